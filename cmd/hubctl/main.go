@@ -0,0 +1,107 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/lint"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"github.com/falcosecurity/cloud-native-security-hub/web"
+	"os"
+)
+
+const usage = `hubctl manages the resources and vendors served by the hub.
+
+Usage:
+  hubctl validate [-resources dir] [-vendors dir] [-falco-rules file] [-format text|json|junit]
+  hubctl export-static -output dir [-resources dir] [-vendors dir]
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "validate":
+		os.Exit(validate(os.Args[2:]))
+	case "export-static":
+		os.Exit(exportStatic(os.Args[2:]))
+	case "help", "-h", "-help", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+}
+
+// validate checks the resources and vendors, exiting with 1 when any of them
+// has errors so CI pipelines fail.
+func validate(args []string) int {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	resourcesPath := flags.String("resources", os.Getenv("RESOURCES_PATH"), "directory with the resources to check")
+	vendorsPath := flags.String("vendors", os.Getenv("VENDOR_PATH"), "directory with the vendors to check")
+	falcoRulesPath := flags.String("falco-rules", os.Getenv("FALCO_RULES_PATH"), "Falco rules file resources may use without defining them; without it, references to unknown macros are only warnings")
+	format := flags.String("format", lint.TEXT, "report format: text, json or junit")
+	flags.Parse(args)
+
+	if *resourcesPath == "" || *vendorsPath == "" {
+		fmt.Fprintln(os.Stderr, "the resources and vendors directories are required")
+		return 2
+	}
+
+	options := lint.Options{ResourcesPath: *resourcesPath, VendorsPath: *vendorsPath}
+	if *falcoRulesPath != "" {
+		defaults, err := resource.FalcoDefaultsFromFile(*falcoRulesPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "unable to read the Falco rules in %s: %s\n", *falcoRulesPath, err)
+			return 2
+		}
+		options.FalcoDefaults = defaults
+	}
+
+	report, err := lint.Run(options)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to validate: %s\n", err)
+		return 2
+	}
+	if err := report.Write(os.Stdout, *format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if report.Count(lint.ERROR) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// exportStatic renders the hub into a directory of static files. Resources
+// and vendors are read the way the server reads them, so the environment
+// variables configuring the server apply.
+func exportStatic(args []string) int {
+	flags := flag.NewFlagSet("export-static", flag.ExitOnError)
+	output := flags.String("output", "", "directory to write the files to")
+	resourcesPath := flags.String("resources", "", "directory with the resources to export, RESOURCES_PATH by default")
+	vendorsPath := flags.String("vendors", "", "directory with the vendors to export, VENDOR_PATH by default")
+	flags.Parse(args)
+
+	if *output == "" {
+		fmt.Fprintln(os.Stderr, "the output directory is required")
+		return 2
+	}
+	if *resourcesPath != "" {
+		os.Setenv("RESOURCES_PATH", *resourcesPath)
+	}
+	if *vendorsPath != "" {
+		os.Setenv("VENDOR_PATH", *vendorsPath)
+	}
+
+	index, err := web.ExportStatic(web.NewRouter(), *output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to export the hub: %s\n", err)
+		return 1
+	}
+	fmt.Printf("exported %d files to %s\n", len(index.Files), *output)
+	return 0
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/database"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/vendor"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+	"log"
+	"os"
+)
+
+func main() {
+	driver := flag.String("driver", envOrDefault("DATABASE_DRIVER", "postgres"), "database/sql driver: postgres or sqlite3")
+	dataSource := flag.String("database", os.Getenv("DATABASE_URL"), "database connection string")
+	resourcesPath := flag.String("resources", os.Getenv("RESOURCES_PATH"), "directory with the resources to import")
+	vendorsPath := flag.String("vendors", os.Getenv("VENDOR_PATH"), "directory with the vendors to import")
+	flag.Parse()
+
+	if *dataSource == "" {
+		log.Fatal("the database connection string is required")
+	}
+
+	db, err := database.Open(*driver, *dataSource)
+	if err != nil {
+		log.Fatalf("unable to migrate the database: %s", err)
+	}
+	defer db.Close()
+
+	version, _ := database.SchemaVersion(db)
+	log.Printf("database schema is at version %d", version)
+
+	if *vendorsPath != "" {
+		importVendors(db, *vendorsPath)
+	}
+	if *resourcesPath != "" {
+		importResources(db, *resourcesPath)
+	}
+}
+
+func importVendors(db *sql.DB, path string) {
+	source, err := vendor.FromPath(path)
+	if err != nil {
+		log.Fatalf("unable to read vendors from %s: %s", path, err)
+	}
+	vendors, err := source.FindAll()
+	if err != nil {
+		log.Fatalf("unable to read vendors from %s: %s", path, err)
+	}
+
+	target := vendor.FromDatabase(db)
+	for _, v := range vendors {
+		if err := target.Save(v); err != nil {
+			log.Fatalf("unable to import vendor %s: %s", v.ID, err)
+		}
+	}
+	log.Printf("imported %d vendors from %s", len(vendors), path)
+}
+
+func importResources(db *sql.DB, path string) {
+	source, err := resource.FromPath(path)
+	if err != nil {
+		log.Fatalf("unable to read resources from %s: %s", path, err)
+	}
+	resources, err := source.FindAll()
+	if err != nil {
+		log.Fatalf("unable to read resources from %s: %s", path, err)
+	}
+
+	target := resource.FromDatabase(db)
+	imported := 0
+	for _, latest := range resources {
+		versions, err := source.FindVersions(latest.ID)
+		if err != nil {
+			log.Fatalf("unable to read resource %s: %s", latest.ID, err)
+		}
+		for _, r := range versions {
+			save := target.Save
+			if _, err := target.FindVersion(r.ID, r.Version); err == nil {
+				save = target.Update
+			}
+			if err := save(r); err != nil {
+				log.Fatalf("unable to import resource %s version %q: %s", r.ID, r.Version, err)
+			}
+			imported++
+		}
+	}
+	log.Printf("imported %d resource versions from %s", imported, path)
+}
+
+func envOrDefault(name, defaultValue string) string {
+	if value, ok := os.LookupEnv(name); ok {
+		return value
+	}
+	return defaultValue
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"github.com/falcosecurity/cloud-native-security-hub/web"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 	"log"
 	"net/http"
 	"os"
@@ -1,9 +1,11 @@
 module github.com/falcosecurity/cloud-native-security-hub
 
-go 1.12
+go 1.13
 
 require (
 	github.com/julienschmidt/httprouter v1.2.0
+	github.com/lib/pq v1.2.0
+	github.com/mattn/go-sqlite3 v1.14.0
 	github.com/rs/cors v1.7.0
 	github.com/stretchr/testify v1.3.0
 	gopkg.in/yaml.v2 v2.2.2
@@ -0,0 +1,236 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Open connects to a database through a database/sql driver, which must be
+// registered by the binary, and brings its schema up to date.
+func Open(driver, dataSource string) (*sql.DB, error) {
+	db, err := sql.Open(driver, dataSource)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if err := Migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+type migration struct {
+	version    int
+	statements []string
+}
+
+// migrations are applied in order and must never be modified once released,
+// add a new one instead. Statements stick to SQL understood by both SQLite
+// and PostgreSQL.
+var migrations = []migration{
+	{
+		version: 1,
+		statements: []string{
+			`CREATE TABLE vendors (
+				id TEXT PRIMARY KEY,
+				kind TEXT NOT NULL,
+				name TEXT NOT NULL,
+				description TEXT NOT NULL,
+				icon TEXT NOT NULL,
+				website TEXT NOT NULL
+			)`,
+			`CREATE TABLE resources (
+				id TEXT PRIMARY KEY,
+				kind TEXT NOT NULL,
+				vendor TEXT NOT NULL,
+				name TEXT NOT NULL,
+				short_description TEXT NOT NULL,
+				description TEXT NOT NULL,
+				icon TEXT NOT NULL,
+				website TEXT NOT NULL
+			)`,
+			`CREATE TABLE resource_maintainers (
+				resource_id TEXT NOT NULL REFERENCES resources (id),
+				position INTEGER NOT NULL,
+				name TEXT NOT NULL,
+				email TEXT NOT NULL,
+				PRIMARY KEY (resource_id, position)
+			)`,
+			`CREATE TABLE resource_keywords (
+				resource_id TEXT NOT NULL REFERENCES resources (id),
+				position INTEGER NOT NULL,
+				keyword TEXT NOT NULL,
+				PRIMARY KEY (resource_id, position)
+			)`,
+			`CREATE TABLE resource_rules (
+				resource_id TEXT NOT NULL REFERENCES resources (id),
+				position INTEGER NOT NULL,
+				raw TEXT NOT NULL,
+				PRIMARY KEY (resource_id, position)
+			)`,
+		},
+	},
+	{
+		// Resources are keyed by ID and version. Tables are rebuilt under a
+		// new name and renamed at the end, as SQLite can't alter primary keys
+		// and PostgreSQL keeps the names of the indexes of renamed tables.
+		version: 2,
+		statements: []string{
+			`CREATE TABLE resources_v2 (
+				id TEXT NOT NULL,
+				version TEXT NOT NULL,
+				kind TEXT NOT NULL,
+				vendor TEXT NOT NULL,
+				name TEXT NOT NULL,
+				short_description TEXT NOT NULL,
+				description TEXT NOT NULL,
+				icon TEXT NOT NULL,
+				website TEXT NOT NULL,
+				PRIMARY KEY (id, version)
+			)`,
+			`CREATE TABLE resource_maintainers_v2 (
+				resource_id TEXT NOT NULL,
+				resource_version TEXT NOT NULL,
+				position INTEGER NOT NULL,
+				name TEXT NOT NULL,
+				email TEXT NOT NULL,
+				PRIMARY KEY (resource_id, resource_version, position),
+				FOREIGN KEY (resource_id, resource_version) REFERENCES resources_v2 (id, version)
+			)`,
+			`CREATE TABLE resource_keywords_v2 (
+				resource_id TEXT NOT NULL,
+				resource_version TEXT NOT NULL,
+				position INTEGER NOT NULL,
+				keyword TEXT NOT NULL,
+				PRIMARY KEY (resource_id, resource_version, position),
+				FOREIGN KEY (resource_id, resource_version) REFERENCES resources_v2 (id, version)
+			)`,
+			`CREATE TABLE resource_rules_v2 (
+				resource_id TEXT NOT NULL,
+				resource_version TEXT NOT NULL,
+				position INTEGER NOT NULL,
+				raw TEXT NOT NULL,
+				PRIMARY KEY (resource_id, resource_version, position),
+				FOREIGN KEY (resource_id, resource_version) REFERENCES resources_v2 (id, version)
+			)`,
+			`INSERT INTO resources_v2 (id, version, kind, vendor, name, short_description, description, icon, website)
+				SELECT id, '', kind, vendor, name, short_description, description, icon, website FROM resources`,
+			`INSERT INTO resource_maintainers_v2 (resource_id, resource_version, position, name, email)
+				SELECT resource_id, '', position, name, email FROM resource_maintainers`,
+			`INSERT INTO resource_keywords_v2 (resource_id, resource_version, position, keyword)
+				SELECT resource_id, '', position, keyword FROM resource_keywords`,
+			`INSERT INTO resource_rules_v2 (resource_id, resource_version, position, raw)
+				SELECT resource_id, '', position, raw FROM resource_rules`,
+			`DROP TABLE resource_maintainers`,
+			`DROP TABLE resource_keywords`,
+			`DROP TABLE resource_rules`,
+			`DROP TABLE resources`,
+			`ALTER TABLE resources_v2 RENAME TO resources`,
+			`ALTER TABLE resource_maintainers_v2 RENAME TO resource_maintainers`,
+			`ALTER TABLE resource_keywords_v2 RENAME TO resource_keywords`,
+			`ALTER TABLE resource_rules_v2 RENAME TO resource_rules`,
+		},
+	},
+	{
+		// Former IDs of resources, which redirect to their current ID.
+		version: 3,
+		statements: []string{
+			`CREATE TABLE resource_aliases (
+				resource_id TEXT NOT NULL,
+				resource_version TEXT NOT NULL,
+				position INTEGER NOT NULL,
+				alias TEXT NOT NULL,
+				PRIMARY KEY (resource_id, resource_version, position),
+				FOREIGN KEY (resource_id, resource_version) REFERENCES resources (id, version)
+			)`,
+			`CREATE INDEX resource_aliases_alias ON resource_aliases (alias)`,
+		},
+	},
+	{
+		// Policies of the resources which are not Falco rules, and the
+		// current name of the Falco rules kind.
+		version: 4,
+		statements: []string{
+			`CREATE TABLE resource_policies (
+				resource_id TEXT NOT NULL,
+				resource_version TEXT NOT NULL,
+				position INTEGER NOT NULL,
+				raw TEXT NOT NULL,
+				PRIMARY KEY (resource_id, resource_version, position),
+				FOREIGN KEY (resource_id, resource_version) REFERENCES resources (id, version)
+			)`,
+			`UPDATE resources SET kind = 'FalcoRules' WHERE kind = 'FalcoRule'`,
+		},
+	},
+	{
+		// Former IDs of vendors, which redirect to their current ID.
+		version: 5,
+		statements: []string{
+			`CREATE TABLE vendor_aliases (
+				vendor_id TEXT NOT NULL REFERENCES vendors (id),
+				position INTEGER NOT NULL,
+				alias TEXT NOT NULL,
+				PRIMARY KEY (vendor_id, position)
+			)`,
+			`CREATE INDEX vendor_aliases_alias ON vendor_aliases (alias)`,
+		},
+	},
+}
+
+// Migrate applies the migrations which have not been applied yet, each one
+// in its own transaction.
+func Migrate(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY)`)
+	if err != nil {
+		return err
+	}
+
+	current, err := SchemaVersion(db)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := apply(db, m); err != nil {
+			return fmt.Errorf("migration %d: %s", m.version, err)
+		}
+	}
+
+	return nil
+}
+
+// SchemaVersion returns the version of the last migration applied.
+func SchemaVersion(db *sql.DB) (version int, err error) {
+	err = db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	return
+}
+
+func apply(db *sql.DB, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, statement := range m.statements {
+		if _, err := tx.Exec(statement); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_migrations (version) VALUES ($1)`, m.version); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
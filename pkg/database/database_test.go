@@ -0,0 +1,62 @@
+package database
+
+import (
+	"database/sql"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestOpenAppliesEveryMigration(t *testing.T) {
+	db, err := Open("sqlite3", inMemory(t))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	version, _ := SchemaVersion(db)
+
+	assert.Equal(t, migrations[len(migrations)-1].version, version)
+}
+
+func TestMigrateIsIdempotent(t *testing.T) {
+	db, _ := Open("sqlite3", inMemory(t))
+	defer db.Close()
+
+	assert.NoError(t, Migrate(db))
+}
+
+func TestMigrationsCreateTheSchema(t *testing.T) {
+	db, _ := Open("sqlite3", inMemory(t))
+	defer db.Close()
+
+	for _, table := range []string{"vendors", "resources", "resource_maintainers", "resource_keywords", "resource_rules", "resource_aliases", "resource_policies", "vendor_aliases"} {
+		_, err := db.Exec("SELECT * FROM " + table)
+		assert.NoError(t, err, table)
+	}
+}
+
+func TestOpenReturnsErrorWithUnknownDriver(t *testing.T) {
+	_, err := Open("unknown", "")
+
+	assert.Error(t, err)
+}
+
+func inMemory(t *testing.T) string {
+	return "file:" + t.Name() + "?mode=memory&cache=shared"
+}
+
+func TestVersionMigrationKeepsExistingResources(t *testing.T) {
+	db, _ := sql.Open("sqlite3", inMemory(t))
+	defer db.Close()
+	db.Exec(`CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY)`)
+	assert.NoError(t, apply(db, migrations[0]))
+	db.Exec(`INSERT INTO resources VALUES ('apache', 'FalcoRules', 'Apache', 'Apache', '', '', '', '')`)
+	db.Exec(`INSERT INTO resource_rules VALUES ('apache', 0, '- macro: apache')`)
+
+	assert.NoError(t, Migrate(db))
+
+	var version, raw string
+	err := db.QueryRow(`SELECT resource_version, raw FROM resource_rules WHERE resource_id = 'apache'`).Scan(&version, &raw)
+	assert.NoError(t, err)
+	assert.Equal(t, "", version)
+	assert.Equal(t, "- macro: apache", raw)
+}
@@ -0,0 +1,31 @@
+package diagnostic
+
+type Code string
+
+const (
+	INVALID_ID           Code = "invalid_id"
+	DUPLICATE_ID         Code = "duplicate_id"
+	ALIAS_CONFLICT       Code = "alias_conflict"
+	UNKNOWN_KIND         Code = "unknown_kind"
+	INVALID_PAYLOAD      Code = "invalid_payload"
+	UNKNOWN_VENDOR       Code = "unknown_vendor"
+	DUPLICATE_DEFINITION Code = "duplicate_definition"
+)
+
+// Diagnostic is a problem found in the resources or vendors served, which
+// doesn't prevent serving the rest of them.
+type Diagnostic struct {
+	Code   Code   `json:"code"`
+	Source string `json:"source,omitempty"`
+	// Resource is the ID of the resource a problem found across files is
+	// about, when it has no source.
+	Resource string `json:"resource,omitempty"`
+	Message  string `json:"message"`
+}
+
+func (d *Diagnostic) String() string {
+	if d.Source == "" {
+		return d.Message
+	}
+	return d.Source + ": " + d.Message
+}
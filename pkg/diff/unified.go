@@ -0,0 +1,169 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+const context = 3
+
+type operation byte
+
+const (
+	equal  operation = ' '
+	insert operation = '+'
+	remove operation = '-'
+)
+
+type edit struct {
+	op   operation
+	line string
+	// Zero based lines of the edit in each side, before it is applied.
+	from, to int
+}
+
+// Unified returns the differences between from and to in the unified diff
+// format, with three lines of context around each change. It returns an
+// empty string when both texts are equal.
+func Unified(fromName, toName, from, to string) string {
+	edits := lineEdits(lines(from), lines(to))
+
+	var result strings.Builder
+	for start := 0; start < len(edits); {
+		if edits[start].op == equal {
+			start++
+			continue
+		}
+
+		end := start
+		for next := start; next < len(edits) && next-end <= 2*context; next++ {
+			if edits[next].op != equal {
+				end = next
+			}
+		}
+		first, last := max(start-context, 0), min(end+context+1, len(edits))
+
+		if result.Len() == 0 {
+			fmt.Fprintf(&result, "--- %s\n+++ %s\n", fromName, toName)
+		}
+		writeHunk(&result, edits[first:last])
+		start = last
+	}
+	return result.String()
+}
+
+func writeHunk(result *strings.Builder, edits []edit) {
+	fromCount, toCount := 0, 0
+	for _, e := range edits {
+		if e.op != insert {
+			fromCount++
+		}
+		if e.op != remove {
+			toCount++
+		}
+	}
+
+	fmt.Fprintf(result, "@@ -%s +%s @@\n", hunkRange(edits[0].from, fromCount), hunkRange(edits[0].to, toCount))
+	for _, e := range edits {
+		fmt.Fprintf(result, "%c%s\n", e.op, e.line)
+	}
+}
+
+// hunkRange formats the lines a hunk covers in one side. Hunks that cover no
+// lines point to the line before them.
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
+
+func lines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// lineEdits finds the shortest edit script turning a into b with the Myers
+// algorithm, keeping every intermediate frontier to walk the path back.
+func lineEdits(a, b []string) []edit {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	frontier := make([]int, 2*offset+1)
+	var trace [][]int
+
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), frontier...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && frontier[offset+k-1] < frontier[offset+k+1]) {
+				x = frontier[offset+k+1]
+			} else {
+				x = frontier[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			frontier[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	var edits []edit
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		previous := trace[d]
+		k := x - y
+		var previousK int
+		if k == -d || (k != d && previous[offset+k-1] < previous[offset+k+1]) {
+			previousK = k + 1
+		} else {
+			previousK = k - 1
+		}
+		previousX := previous[offset+previousK]
+		previousY := previousX - previousK
+
+		for x > previousX && y > previousY {
+			x, y = x-1, y-1
+			edits = append(edits, edit{op: equal, line: a[x], from: x, to: y})
+		}
+		if d > 0 {
+			if x == previousX {
+				y--
+				edits = append(edits, edit{op: insert, line: b[y], from: x, to: y})
+			} else {
+				x--
+				edits = append(edits, edit{op: remove, line: a[x], from: x, to: y})
+			}
+		}
+		x, y = previousX, previousY
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,49 @@
+package diff
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUnifiedReturnsNothingForEqualTexts(t *testing.T) {
+	assert.Equal(t, "", Unified("a", "b", "one\ntwo\n", "one\ntwo\n"))
+}
+
+func TestUnifiedShowsChangesWithContext(t *testing.T) {
+	from := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n"
+	to := "1\n2\n3\n4\nfive\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n"
+
+	assert.Equal(t, `--- old
++++ new
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+@@ -13,3 +13,4 @@
+ 13
+ 14
+ 15
++16
+`, Unified("old", "new", from, to))
+}
+
+func TestUnifiedMergesCloseChanges(t *testing.T) {
+	assert.Equal(t, `--- old
++++ new
+@@ -1,4 +1,3 @@
+-1
++one
+ 2
+ 3
+-4
+`, Unified("old", "new", "1\n2\n3\n4\n", "one\n2\n3\n"))
+}
+
+func TestUnifiedFromAnEmptyText(t *testing.T) {
+	assert.Equal(t, "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+one\n+two\n", Unified("old", "new", "", "one\ntwo"))
+}
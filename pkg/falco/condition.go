@@ -0,0 +1,292 @@
+package falco
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Expr is a node of a parsed condition.
+type Expr interface {
+	Pos() Position
+}
+
+// BooleanExpr joins two expressions with "and" or "or".
+type BooleanExpr struct {
+	Operator string
+	Left     Expr
+	Right    Expr
+	Position Position
+}
+
+type NotExpr struct {
+	Expr     Expr
+	Position Position
+}
+
+// MacroRef is a bare name standing for the condition of a macro.
+type MacroRef struct {
+	Name     string
+	Position Position
+}
+
+// Check compares a field with a value, a list of values, or checks that the
+// field exists.
+type Check struct {
+	Field    string
+	Operator string
+	Value    string
+	Values   []ListValue
+	Position Position
+}
+
+// ListValue is an item inside the parentheses of an in, intersects or
+// pmatch check. Unquoted items may name a Falco list.
+type ListValue struct {
+	Value    string
+	Quoted   bool
+	Position Position
+}
+
+func (e *BooleanExpr) Pos() Position { return e.Position }
+func (e *NotExpr) Pos() Position     { return e.Position }
+func (e *MacroRef) Pos() Position    { return e.Position }
+func (e *Check) Pos() Position       { return e.Position }
+
+var (
+	binaryOperators = map[string]bool{
+		"=": true, "==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true,
+		"contains": true, "icontains": true, "bcontains": true, "startswith": true, "bstartswith": true,
+		"endswith": true, "glob": true, "regex": true,
+	}
+	listOperators = map[string]bool{"in": true, "intersects": true, "pmatch": true}
+	keywords      = map[string]bool{"and": true, "or": true, "not": true, "exists": true}
+
+	macroName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	fieldName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z0-9_]+)+(\[[^\]]+\])?(\.[a-zA-Z0-9_]+)*$`)
+)
+
+// ParseCondition parses the condition of a Falco rule or macro.
+func ParseCondition(condition string) (Expr, error) {
+	return parse(condition, false)
+}
+
+// ParseAppendedCondition parses the condition of a rule or macro with
+// append: true, which may start with "and" or "or" to extend the condition
+// it is appended to.
+func ParseAppendedCondition(condition string) (Expr, error) {
+	return parse(condition, true)
+}
+
+func parse(condition string, appended bool) (Expr, error) {
+	tokens, err := tokenize(condition)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	if appended && p.current().kind == tokenWord && (p.current().text == "and" || p.current().text == "or") {
+		p.offset++
+	}
+	if p.current().kind == tokenEOF {
+		return nil, p.errorf(p.current(), "empty condition")
+	}
+
+	expr, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.current(); t.kind != tokenEOF {
+		return nil, p.errorf(t, "unexpected %s, expected \"and\", \"or\" or end of condition", t)
+	}
+
+	return expr, nil
+}
+
+type parser struct {
+	tokens []token
+	offset int
+}
+
+func (p *parser) current() token {
+	return p.tokens[p.offset]
+}
+
+func (p *parser) isKeyword(text string) bool {
+	t := p.current()
+	return t.kind == tokenWord && t.text == text
+}
+
+func (p *parser) or() (Expr, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword("or") {
+		operator := p.current()
+		p.offset++
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = &BooleanExpr{Operator: "or", Left: left, Right: right, Position: operator.position}
+	}
+
+	return left, nil
+}
+
+func (p *parser) and() (Expr, error) {
+	left, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword("and") {
+		operator := p.current()
+		p.offset++
+		right, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		left = &BooleanExpr{Operator: "and", Left: left, Right: right, Position: operator.position}
+	}
+
+	return left, nil
+}
+
+func (p *parser) not() (Expr, error) {
+	if p.isKeyword("not") {
+		operator := p.current()
+		p.offset++
+		expr, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return &NotExpr{Expr: expr, Position: operator.position}, nil
+	}
+
+	return p.primary()
+}
+
+func (p *parser) primary() (Expr, error) {
+	t := p.current()
+	switch {
+	case t.kind == tokenLeftParen:
+		p.offset++
+		expr, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.current(); closing.kind != tokenRightParen {
+			return nil, p.errorf(closing, "unexpected %s, expected \")\" to close the parenthesis at %s", closing, t.position)
+		}
+		p.offset++
+		return expr, nil
+	case t.kind == tokenWord && !keywords[t.text] && !binaryOperators[t.text] && !listOperators[t.text]:
+		p.offset++
+		return p.check(t)
+	default:
+		return nil, p.errorf(t, "unexpected %s, expected a field, a macro or \"(\"", t)
+	}
+}
+
+func (p *parser) check(name token) (Expr, error) {
+	operator := p.current()
+
+	switch {
+	case operator.kind == tokenOperator || (operator.kind == tokenWord && binaryOperators[operator.text]):
+		if err := p.field(name); err != nil {
+			return nil, err
+		}
+		p.offset++
+		value := p.current()
+		// Values are bare words, so evt.dir=< compares with "<".
+		if value.kind != tokenWord && value.kind != tokenString && value.kind != tokenOperator {
+			return nil, p.errorf(value, "unexpected %s, expected a value after %q", value, operator.text)
+		}
+		p.offset++
+		return &Check{Field: name.text, Operator: operator.text, Value: value.text, Position: name.position}, nil
+
+	case operator.kind == tokenWord && listOperators[operator.text]:
+		if err := p.field(name); err != nil {
+			return nil, err
+		}
+		p.offset++
+		values, err := p.list(operator)
+		if err != nil {
+			return nil, err
+		}
+		return &Check{Field: name.text, Operator: operator.text, Values: values, Position: name.position}, nil
+
+	case operator.kind == tokenWord && operator.text == "exists":
+		if err := p.field(name); err != nil {
+			return nil, err
+		}
+		p.offset++
+		return &Check{Field: name.text, Operator: operator.text, Position: name.position}, nil
+	}
+
+	if !macroName.MatchString(name.text) {
+		if fieldName.MatchString(name.text) {
+			return nil, p.errorf(name, "field %q must be followed by an operator", name.text)
+		}
+		return nil, p.errorf(name, "%q is not a valid macro name", name.text)
+	}
+	return &MacroRef{Name: name.text, Position: name.position}, nil
+}
+
+func (p *parser) field(name token) error {
+	if !fieldName.MatchString(name.text) {
+		return p.errorf(name, "%q is not a valid field name", name.text)
+	}
+	return nil
+}
+
+func (p *parser) list(operator token) ([]ListValue, error) {
+	if open := p.current(); open.kind != tokenLeftParen {
+		return nil, p.errorf(open, "unexpected %s, expected \"(\" after %q", open, operator.text)
+	}
+	p.offset++
+
+	values := []ListValue{}
+	if p.current().kind == tokenRightParen {
+		p.offset++
+		return values, nil
+	}
+
+	for {
+		item := p.current()
+		if item.kind != tokenWord && item.kind != tokenString {
+			return nil, p.errorf(item, "unexpected %s, expected a list item", item)
+		}
+		values = append(values, ListValue{Value: item.text, Quoted: item.kind == tokenString, Position: item.position})
+		p.offset++
+
+		switch separator := p.current(); separator.kind {
+		case tokenComma:
+			p.offset++
+		case tokenRightParen:
+			p.offset++
+			return values, nil
+		default:
+			return nil, p.errorf(separator, "unexpected %s, expected \",\" or \")\" in list", separator)
+		}
+	}
+}
+
+func (p *parser) errorf(t token, format string, args ...interface{}) error {
+	return &SyntaxError{Position: t.position, Message: strings.TrimSpace(fmt.Sprintf(format, args...))}
+}
+
+// Walk calls fn for expr and every expression nested in it, parents first.
+func Walk(expr Expr, fn func(Expr)) {
+	fn(expr)
+	switch e := expr.(type) {
+	case *BooleanExpr:
+		Walk(e.Left, fn)
+		Walk(e.Right, fn)
+	case *NotExpr:
+		Walk(e.Expr, fn)
+	}
+}
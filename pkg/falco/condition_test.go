@@ -0,0 +1,89 @@
+package falco
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseConditionAcceptsFalcoConditions(t *testing.T) {
+	conditions := []string{
+		"spawned_process",
+		"evt.type = execve and evt.dir=<",
+		"evt.type in (open, openat) and not fd.name startswith /etc/",
+		"(container and proc.name in (shell_binaries, \"sh\")) or k8s.ns.name = kube-system",
+		"proc.aname[2] = sshd and fd.name pmatch (/var/log, /tmp)",
+		"proc.cmdline contains '-c' and fd.sip != 127.0.0.1 and evt.arg.flags icontains O_CREAT",
+		"jevt.value[/user/name] exists and ka.verb in ()",
+		"evt.num >= 10 and\n  not (proc.name glob \"nginx*\")",
+	}
+
+	for _, condition := range conditions {
+		_, err := ParseCondition(condition)
+
+		assert.NoError(t, err, condition)
+	}
+}
+
+func TestParseConditionBuildsTheExpressionTree(t *testing.T) {
+	expr, err := ParseCondition("spawned_process and not proc.name in (bash, \"sh\") or evt.dir=<")
+
+	assert.NoError(t, err)
+	assert.Equal(t, &BooleanExpr{
+		Operator: "or",
+		Left: &BooleanExpr{
+			Operator: "and",
+			Left:     &MacroRef{Name: "spawned_process", Position: Position{1, 1}},
+			Right: &NotExpr{
+				Expr: &Check{
+					Field:    "proc.name",
+					Operator: "in",
+					Values: []ListValue{
+						{Value: "bash", Position: Position{1, 39}},
+						{Value: "sh", Quoted: true, Position: Position{1, 45}},
+					},
+					Position: Position{1, 25},
+				},
+				Position: Position{1, 21},
+			},
+			Position: Position{1, 17},
+		},
+		Right:    &Check{Field: "evt.dir", Operator: "=", Value: "<", Position: Position{1, 54}},
+		Position: Position{1, 51},
+	}, expr)
+}
+
+func TestParseConditionReportsTheLineAndColumnOfSyntaxErrors(t *testing.T) {
+	cases := map[string]string{
+		"":                                "1:1: empty condition",
+		"evt.type =":                      "1:11: unexpected end of condition, expected a value after \"=\"",
+		"evt.type = execve and":           "1:22: unexpected end of condition, expected a field, a macro or \"(\"",
+		"(spawned_process":                "1:17: unexpected end of condition, expected \")\" to close the parenthesis at 1:1",
+		"spawned_process)":                "1:16: unexpected \")\", expected \"and\", \"or\" or end of condition",
+		"proc.name":                       "1:1: field \"proc.name\" must be followed by an operator",
+		"proc.name in bash":               "1:14: unexpected \"bash\", expected \"(\" after \"in\"",
+		"proc.name in (bash sh)":          "1:20: unexpected \"sh\", expected \",\" or \")\" in list",
+		"proc.name = \"bash":              "1:13: unterminated string",
+		"spawned_process\n  and or shell": "2:7: unexpected \"or\", expected a field, a macro or \"(\"",
+		"shell-binaries":                  "1:1: \"shell-binaries\" is not a valid macro name",
+		"proc..name = bash":               "1:1: \"proc..name\" is not a valid field name",
+	}
+
+	for condition, expected := range cases {
+		_, err := ParseCondition(condition)
+
+		assert.EqualError(t, err, expected, condition)
+	}
+}
+
+func TestParseConditionRejectsLeadingOperators(t *testing.T) {
+	_, err := ParseCondition("or evt.type = execveat")
+
+	assert.EqualError(t, err, "1:1: unexpected \"or\", expected a field, a macro or \"(\"")
+}
+
+func TestParseAppendedConditionAcceptsLeadingOperators(t *testing.T) {
+	expr, err := ParseAppendedCondition("or evt.type = execveat")
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Check{Field: "evt.type", Operator: "=", Value: "execveat", Position: Position{1, 4}}, expr)
+}
@@ -0,0 +1,175 @@
+package falco
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenLeftParen
+	tokenRightParen
+	tokenComma
+	tokenOperator
+	tokenString
+	tokenWord
+)
+
+// Position is a 1-based line and column inside a condition.
+type Position struct {
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+type token struct {
+	kind     tokenKind
+	text     string
+	position Position
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of condition"
+	case tokenString:
+		return fmt.Sprintf("string %q", t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// SyntaxError points to the first problem found in a condition.
+type SyntaxError struct {
+	Position Position
+	Message  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Position, e.Message)
+}
+
+type lexer struct {
+	input    []rune
+	offset   int
+	position Position
+}
+
+func tokenize(condition string) ([]token, error) {
+	l := &lexer{input: []rune(condition), position: Position{Line: 1, Column: 1}}
+
+	var tokens []token
+	for {
+		t, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+		if t.kind == tokenEOF {
+			return tokens, nil
+		}
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipSpaces()
+	start := l.position
+	if l.offset >= len(l.input) {
+		return token{kind: tokenEOF, position: start}, nil
+	}
+
+	switch r := l.input[l.offset]; {
+	case r == '(':
+		l.advance()
+		return token{kind: tokenLeftParen, text: "(", position: start}, nil
+	case r == ')':
+		l.advance()
+		return token{kind: tokenRightParen, text: ")", position: start}, nil
+	case r == ',':
+		l.advance()
+		return token{kind: tokenComma, text: ",", position: start}, nil
+	case r == '"' || r == '\'':
+		return l.quoted(r)
+	case strings.ContainsRune("=<>", r) || (r == '!' && l.peek(1) == '='):
+		return l.operator(), nil
+	default:
+		return l.word(), nil
+	}
+}
+
+func (l *lexer) quoted(quote rune) (token, error) {
+	start := l.position
+	l.advance()
+
+	var text strings.Builder
+	for l.offset < len(l.input) {
+		r := l.input[l.offset]
+		switch {
+		case r == quote:
+			l.advance()
+			return token{kind: tokenString, text: text.String(), position: start}, nil
+		case r == '\\' && l.offset+1 < len(l.input):
+			l.advance()
+			text.WriteRune(l.input[l.offset])
+		default:
+			text.WriteRune(r)
+		}
+		l.advance()
+	}
+
+	return token{}, &SyntaxError{Position: start, Message: "unterminated string"}
+}
+
+func (l *lexer) operator() token {
+	start := l.position
+	first := l.input[l.offset]
+	l.advance()
+	if l.offset < len(l.input) && l.input[l.offset] == '=' {
+		l.advance()
+		return token{kind: tokenOperator, text: string(first) + "=", position: start}
+	}
+	return token{kind: tokenOperator, text: string(first), position: start}
+}
+
+func (l *lexer) word() token {
+	start := l.position
+	var text strings.Builder
+	for l.offset < len(l.input) {
+		r := l.input[l.offset]
+		if unicode.IsSpace(r) || strings.ContainsRune("(),\"'=<>", r) || (r == '!' && l.peek(1) == '=') {
+			break
+		}
+		text.WriteRune(r)
+		l.advance()
+	}
+	return token{kind: tokenWord, text: text.String(), position: start}
+}
+
+func (l *lexer) skipSpaces() {
+	for l.offset < len(l.input) && unicode.IsSpace(l.input[l.offset]) {
+		l.advance()
+	}
+}
+
+func (l *lexer) peek(distance int) rune {
+	if l.offset+distance >= len(l.input) {
+		return 0
+	}
+	return l.input[l.offset+distance]
+}
+
+func (l *lexer) advance() {
+	if l.input[l.offset] == '\n' {
+		l.position.Line++
+		l.position.Column = 1
+	} else {
+		l.position.Column++
+	}
+	l.offset++
+}
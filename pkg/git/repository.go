@@ -0,0 +1,151 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Repository is a local git repository read through the git command line.
+type Repository struct {
+	dir   string
+	mutex sync.Mutex
+}
+
+type Commit struct {
+	Hash    string    `json:"hash"`
+	Author  string    `json:"author"`
+	Email   string    `json:"email"`
+	Date    time.Time `json:"date"`
+	Message string    `json:"message"`
+}
+
+// Clone mirrors the repository at url into dir. When dir already contains a
+// repository it is opened instead, so restarts don't clone again.
+func Clone(url, dir string) (*Repository, error) {
+	if isRepository(dir) {
+		return Open(dir)
+	}
+
+	if _, err := run("", "clone", "--quiet", "--mirror", url, dir); err != nil {
+		return nil, err
+	}
+	return &Repository{dir: dir}, nil
+}
+
+func Open(dir string) (*Repository, error) {
+	if !isRepository(dir) {
+		return nil, fmt.Errorf("%s is not a git repository", dir)
+	}
+	return &Repository{dir: dir}, nil
+}
+
+// Fetch updates the refs from the remote the repository was cloned from. It
+// does nothing for repositories without remotes.
+func (r *Repository) Fetch() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	remotes, err := run(r.dir, "remote")
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(remotes)) == 0 {
+		return nil
+	}
+
+	_, err = run(r.dir, "fetch", "--quiet", "--prune", "origin")
+	return err
+}
+
+// Resolve returns the hash of the commit a branch, tag or commit ref points to.
+func (r *Repository) Resolve(ref string) (string, error) {
+	output, err := run(r.dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve ref %s", ref)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Files lists the paths of the files under dir at the given commit.
+func (r *Repository) Files(commit, dir string) ([]string, error) {
+	args := []string{"ls-tree", "-r", "--name-only", "-z", commit}
+	if dir = strings.Trim(dir, "/"); dir != "" && dir != "." {
+		args = append(args, "--", dir+"/")
+	}
+
+	output, err := run(r.dir, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, file := range strings.Split(string(output), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+func (r *Repository) ReadFile(commit, path string) ([]byte, error) {
+	return run(r.dir, "cat-file", "blob", commit+":"+path)
+}
+
+// LastCommit returns the most recent commit reachable from commit which
+// modified path.
+func (r *Repository) LastCommit(commit, path string) (*Commit, error) {
+	output, err := run(r.dir, "log", "-1", "--format=%H%x00%an%x00%ae%x00%cI%x00%s", commit, "--", path)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.Split(strings.TrimSpace(string(output)), "\x00")
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("no commits found for %s", path)
+	}
+	date, err := time.Parse(time.RFC3339, fields[3])
+	if err != nil {
+		return nil, err
+	}
+
+	return &Commit{
+		Hash:    fields[0],
+		Author:  fields[1],
+		Email:   fields[2],
+		Date:    date,
+		Message: fields[4],
+	}, nil
+}
+
+func isRepository(dir string) bool {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		return true
+	}
+	_, headErr := os.Stat(filepath.Join(dir, "HEAD"))
+	_, objectsErr := os.Stat(filepath.Join(dir, "objects"))
+	return headErr == nil && objectsErr == nil
+}
+
+func run(dir string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	command := exec.Command("git", args...)
+	command.Dir = dir
+	command.Stderr = &stderr
+	command.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	output, err := command.Output()
+	if err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return nil, fmt.Errorf("git %s: %s", args[0], message)
+	}
+	return output, nil
+}
@@ -0,0 +1,121 @@
+package git
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestCloneAndReadFilesFromARef(t *testing.T) {
+	origin := newOriginRepository(t, map[string]string{
+		"resources/apache.yaml": "name: Apache\n",
+		"vendors/apache.yaml":   "name: Apache\n",
+	})
+	defer os.RemoveAll(origin)
+	repository, dir := cloneToTempDir(t, origin)
+	defer os.RemoveAll(dir)
+
+	commit, err := repository.Resolve("master")
+	assert.NoError(t, err)
+
+	files, _ := repository.Files(commit, "resources")
+	assert.Equal(t, []string{"resources/apache.yaml"}, files)
+
+	content, _ := repository.ReadFile(commit, "resources/apache.yaml")
+	assert.Equal(t, "name: Apache\n", string(content))
+}
+
+func TestLastCommitReturnsCommitMetadata(t *testing.T) {
+	origin := newOriginRepository(t, map[string]string{"apache.yaml": "name: Apache\n"})
+	defer os.RemoveAll(origin)
+	repository, dir := cloneToTempDir(t, origin)
+	defer os.RemoveAll(dir)
+	commit, _ := repository.Resolve("master")
+
+	lastCommit, err := repository.LastCommit(commit, "apache.yaml")
+
+	assert.NoError(t, err)
+	assert.Equal(t, commit, lastCommit.Hash)
+	assert.Equal(t, "Hub Tester", lastCommit.Author)
+	assert.Equal(t, "Add files", lastCommit.Message)
+}
+
+func TestFetchUpdatesRefs(t *testing.T) {
+	origin := newOriginRepository(t, map[string]string{"apache.yaml": "name: Apache\n"})
+	defer os.RemoveAll(origin)
+	repository, dir := cloneToTempDir(t, origin)
+	defer os.RemoveAll(dir)
+	before, _ := repository.Resolve("master")
+
+	commitFiles(t, origin, map[string]string{"mongo.yaml": "name: Mongo\n"})
+	err := repository.Fetch()
+
+	after, _ := repository.Resolve("master")
+	assert.NoError(t, err)
+	assert.NotEqual(t, before, after)
+}
+
+func TestResolveReturnsErrorForUnknownRef(t *testing.T) {
+	origin := newOriginRepository(t, map[string]string{"apache.yaml": "name: Apache\n"})
+	defer os.RemoveAll(origin)
+	repository, dir := cloneToTempDir(t, origin)
+	defer os.RemoveAll(dir)
+
+	_, err := repository.Resolve("non-existent")
+
+	assert.Error(t, err)
+}
+
+func TestOpenReturnsErrorIfDirIsNotARepository(t *testing.T) {
+	_, err := Open("../../test/fixtures")
+
+	assert.Error(t, err)
+}
+
+func cloneToTempDir(t *testing.T, origin string) (*Repository, string) {
+	dir, _ := ioutil.TempDir("", "clone")
+	repository, err := Clone(origin, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repository, dir
+}
+
+// newOriginRepository creates a bare repository with a working copy in its
+// "work" subdirectory used to push new commits.
+func newOriginRepository(t *testing.T, files map[string]string) string {
+	dir, _ := ioutil.TempDir("", "origin")
+	gitCommand(t, dir, "init", "--quiet", "--bare")
+	gitCommand(t, dir, "symbolic-ref", "HEAD", "refs/heads/master")
+
+	work := filepath.Join(dir, "work")
+	gitCommand(t, dir, "init", "--quiet", work)
+	gitCommand(t, work, "checkout", "--quiet", "-b", "master")
+	commitFiles(t, dir, files)
+	return dir
+}
+
+func commitFiles(t *testing.T, origin string, files map[string]string) {
+	work := filepath.Join(origin, "work")
+	for path, content := range files {
+		os.MkdirAll(filepath.Join(work, filepath.Dir(path)), 0755)
+		ioutil.WriteFile(filepath.Join(work, path), []byte(content), 0644)
+	}
+	gitCommand(t, work, "add", "-A")
+	gitCommand(t, work, "commit", "--quiet", "-m", "Add files")
+	gitCommand(t, work, "push", "--quiet", origin, "master")
+}
+
+func gitCommand(t *testing.T, dir string, args ...string) {
+	command := exec.Command("git", args...)
+	command.Dir = dir
+	command.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Hub Tester", "GIT_AUTHOR_EMAIL=tester@example.com",
+		"GIT_COMMITTER_NAME=Hub Tester", "GIT_COMMITTER_EMAIL=tester@example.com")
+	if output, err := command.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %s", args, output)
+	}
+}
@@ -0,0 +1,228 @@
+// Package hubfile finds and decodes the files resources and vendors are read
+// from.
+package hubfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Supported reports whether the file at path holds resources or vendors,
+// judging by its extension.
+func Supported(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// IsJSON reports whether the file at path is decoded as JSON instead of YAML.
+func IsJSON(path string) bool {
+	return strings.ToLower(filepath.Ext(path)) == ".json"
+}
+
+// Decode calls fn once for each document in the content of the file at path,
+// with a function decoding the document into a value. YAML files hold
+// documents separated by "---", and JSON files either a single value, several
+// values one after the other, or an array of them.
+func Decode(path string, content []byte, fn func(decode func(value interface{}) error) error) error {
+	if !IsJSON(path) {
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		return eachDocument(fn, decoder.Decode)
+	}
+
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && trimmed[0] == '[' {
+		var documents []json.RawMessage
+		if err := json.Unmarshal(trimmed, &documents); err != nil {
+			return err
+		}
+		for _, document := range documents {
+			document := document
+			if err := fn(func(value interface{}) error { return json.Unmarshal(document, value) }); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	return eachDocument(fn, decoder.Decode)
+}
+
+func eachDocument(fn func(decode func(value interface{}) error) error, decode func(value interface{}) error) error {
+	for {
+		if err := fn(decode); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// DocumentLines returns the line each non empty YAML document of content
+// starts at, in the order Decode reads them, to point at the documents in
+// error messages. It returns nil for JSON files.
+func DocumentLines(path string, content []byte) []int {
+	if IsJSON(path) {
+		return nil
+	}
+
+	var lines []int
+	start := 0
+	for number, line := range strings.Split(string(content), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if line == "---" || strings.HasPrefix(line, "--- ") {
+			start = 0
+			continue
+		}
+		if start == 0 && trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			start = number + 1
+			lines = append(lines, start)
+		}
+	}
+	return lines
+}
+
+// Walk calls fn for every supported file under root, skipping the files and
+// directories excluded by the ignore file at the root of the tree.
+func Walk(root string, fn func(path string, info os.FileInfo) error) error {
+	ignore, err := ReadIgnore(root)
+	if err != nil {
+		return err
+	}
+
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relative, err := filepath.Rel(root, path)
+		if err != nil || relative == "." {
+			return err
+		}
+		if ignore.Match(filepath.ToSlash(relative), info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() || !Supported(path) {
+			return nil
+		}
+		return fn(path, info)
+	})
+}
+
+// Select returns the supported files among files, which are slash separated
+// paths under root, leaving out those excluded by the ignore file at root.
+// read returns the content of a file, and is used to read the ignore file.
+func Select(root string, files []string, read func(path string) ([]byte, error)) ([]string, error) {
+	prefix := strings.Trim(root, "/")
+	if prefix == "." {
+		prefix = ""
+	}
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	ignore := &Ignore{}
+	for _, file := range files {
+		if file == prefix+IgnoreFile {
+			content, err := read(file)
+			if err != nil {
+				return nil, err
+			}
+			ignore = ParseIgnore(string(content))
+		}
+	}
+
+	var selected []string
+	for _, file := range files {
+		if Supported(file) && !ignore.Excludes(strings.TrimPrefix(file, prefix)) {
+			selected = append(selected, file)
+		}
+	}
+	return selected, nil
+}
+
+// Encode writes values as the documents of a file at path, in the format
+// Decode reads them from.
+func Encode(path string, values []interface{}) ([]byte, error) {
+	var content bytes.Buffer
+	if IsJSON(path) {
+		var documents []interface{}
+		for _, value := range values {
+			document, err := jsonCompatible(value)
+			if err != nil {
+				return nil, err
+			}
+			documents = append(documents, document)
+		}
+		var document interface{} = documents
+		if len(documents) == 1 {
+			document = documents[0]
+		}
+		encoder := json.NewEncoder(&content)
+		encoder.SetIndent("", "  ")
+		err := encoder.Encode(document)
+		return content.Bytes(), err
+	}
+
+	for position, value := range values {
+		document, err := yaml.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		if position > 0 {
+			content.WriteString("---\n")
+		}
+		content.Write(document)
+	}
+	return content.Bytes(), nil
+}
+
+// jsonCompatible converts value to the same structure it has when written as
+// YAML, so it is stored with the field names files use.
+func jsonCompatible(value interface{}) (interface{}, error) {
+	document, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	if err := yaml.Unmarshal(document, &decoded); err != nil {
+		return nil, err
+	}
+	return withStringKeys(decoded)
+}
+
+func withStringKeys(value interface{}) (interface{}, error) {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			convertedItem, err := withStringKeys(item)
+			if err != nil {
+				return nil, err
+			}
+			converted[fmt.Sprint(key)] = convertedItem
+		}
+		return converted, nil
+	case []interface{}:
+		converted := make([]interface{}, len(value))
+		for position, item := range value {
+			convertedItem, err := withStringKeys(item)
+			if err != nil {
+				return nil, err
+			}
+			converted[position] = convertedItem
+		}
+		return converted, nil
+	}
+	return value, nil
+}
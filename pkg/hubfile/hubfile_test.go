@@ -0,0 +1,111 @@
+package hubfile
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type document struct {
+	Name string `json:"name" yaml:"name"`
+}
+
+func decodeAll(t *testing.T, path, content string) []*document {
+	var documents []*document
+	err := Decode(path, []byte(content), func(decode func(interface{}) error) error {
+		var doc *document
+		if err := decode(&doc); err != nil {
+			return err
+		}
+		if doc != nil {
+			documents = append(documents, doc)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	return documents
+}
+
+func TestDecodeReadsEveryYAMLDocument(t *testing.T) {
+	documents := decodeAll(t, "vendors.yml", "name: Apache\n---\n---\nname: Nginx\n")
+
+	assert.Equal(t, []*document{{Name: "Apache"}, {Name: "Nginx"}}, documents)
+}
+
+func TestDecodeReadsJSONValuesAndArrays(t *testing.T) {
+	assert.Equal(t, []*document{{Name: "Apache"}}, decodeAll(t, "apache.json", `{"name": "Apache"}`))
+	assert.Equal(t, []*document{{Name: "Apache"}, {Name: "Nginx"}}, decodeAll(t, "web.json", `{"name": "Apache"} {"name": "Nginx"}`))
+	assert.Equal(t, []*document{{Name: "Apache"}, {Name: "Nginx"}}, decodeAll(t, "web.json", ` [{"name": "Apache"}, {"name": "Nginx"}]`))
+}
+
+func TestDecodeReturnsSyntaxErrors(t *testing.T) {
+	err := Decode("broken.yaml", []byte("name: Apache\n---\nname: ["), func(decode func(interface{}) error) error {
+		var doc document
+		return decode(&doc)
+	})
+
+	assert.Error(t, err)
+}
+
+func TestEncodeWritesTheFormatOfTheFile(t *testing.T) {
+	yamlContent, _ := Encode("web.yaml", []interface{}{document{Name: "Apache"}, document{Name: "Nginx"}})
+	jsonContent, _ := Encode("apache.json", []interface{}{document{Name: "Apache"}})
+
+	assert.Equal(t, "name: Apache\n---\nname: Nginx\n", string(yamlContent))
+	assert.Equal(t, "{\n  \"name\": \"Apache\"\n}\n", string(jsonContent))
+}
+
+func TestIgnoreMatchesNamesPathsAndDirectories(t *testing.T) {
+	ignore := ParseIgnore("# drafts\n*.draft.yaml\n\ntemplates/\n/examples/*.json\n")
+
+	assert.True(t, ignore.Excludes("apache.draft.yaml"))
+	assert.True(t, ignore.Excludes("web/nginx.draft.yaml"))
+	assert.True(t, ignore.Excludes("templates/resource.yaml"))
+	assert.True(t, ignore.Excludes("web/templates/resource.yaml"))
+	assert.True(t, ignore.Excludes("examples/apache.json"))
+	assert.True(t, ignore.Excludes(".hubignore"))
+	assert.False(t, ignore.Excludes("templates"))
+	assert.False(t, ignore.Excludes("web/examples/apache.json"))
+	assert.False(t, ignore.Excludes("apache.yaml"))
+}
+
+func TestWalkSkipsIgnoredAndUnsupportedFiles(t *testing.T) {
+	root, _ := ioutil.TempDir("", "hubfile")
+	defer os.RemoveAll(root)
+	for _, file := range []string{"apache.yaml", "mongo.yml", "nginx.json", "README.md", "drafts/traefik.yaml", ".hubignore"} {
+		os.MkdirAll(filepath.Dir(filepath.Join(root, file)), 0755)
+		ioutil.WriteFile(filepath.Join(root, file), []byte("drafts/\n"), 0644)
+	}
+
+	var found []string
+	err := Walk(root, func(path string, info os.FileInfo) error {
+		relative, _ := filepath.Rel(root, path)
+		found = append(found, relative)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"apache.yaml", "mongo.yml", "nginx.json"}, found)
+}
+
+func TestSelectHonoursTheIgnoreFileOfTheRoot(t *testing.T) {
+	files := []string{"resources/.hubignore", "resources/apache.yaml", "resources/drafts/nginx.yaml", "resources/README.md", "vendors/apache.yaml"}
+	read := func(path string) ([]byte, error) {
+		assert.Equal(t, "resources/.hubignore", path)
+		return []byte("drafts/\n"), nil
+	}
+
+	selected, err := Select("resources", files[:4], read)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"resources/apache.yaml"}, selected)
+}
+
+func TestDocumentLinesSkipsEmptyDocuments(t *testing.T) {
+	content := []byte("# resources\nname: Nginx\n---\n---\n\nname: Apache\n--- # mongo\nname: MongoDB\n")
+
+	assert.Equal(t, []int{2, 6, 8}, DocumentLines("resources.yaml", content))
+	assert.Nil(t, DocumentLines("resources.json", []byte(`{"name": "Nginx"}`)))
+}
@@ -0,0 +1,98 @@
+package hubfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFile is the name of the file listing, at the root of a tree of
+// resources or vendors, the files and directories to skip.
+const IgnoreFile = ".hubignore"
+
+// Ignore matches paths against the patterns of an ignore file. Each line holds
+// a pattern in the syntax of path.Match; blank lines and lines starting with
+// "#" are skipped. Patterns ending with "/" only match directories, and
+// patterns with a "/" anywhere else are matched against the whole path
+// relative to the root instead of the name of each file and directory.
+type Ignore struct {
+	patterns []ignorePattern
+}
+
+type ignorePattern struct {
+	glob     string
+	dirOnly  bool
+	anchored bool
+}
+
+// ParseIgnore reads the patterns of an ignore file.
+func ParseIgnore(content string) *Ignore {
+	ignore := &Ignore{}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pattern := ignorePattern{}
+		if strings.HasSuffix(line, "/") {
+			pattern.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			pattern.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		pattern.glob = line
+		ignore.patterns = append(ignore.patterns, pattern)
+	}
+	return ignore
+}
+
+// ReadIgnore reads the ignore file at the root of a tree. Trees without one
+// ignore nothing.
+func ReadIgnore(root string) (*Ignore, error) {
+	content, err := ioutil.ReadFile(filepath.Join(root, IgnoreFile))
+	if os.IsNotExist(err) {
+		return &Ignore{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseIgnore(string(content)), nil
+}
+
+// Match reports whether the file or directory at relative, a slash separated
+// path relative to the root of the tree, is ignored. It doesn't check the
+// directories containing it, see Excludes.
+func (i *Ignore) Match(relative string, dir bool) bool {
+	if relative == IgnoreFile {
+		return true
+	}
+	for _, pattern := range i.patterns {
+		if pattern.dirOnly && !dir {
+			continue
+		}
+		subject := path.Base(relative)
+		if pattern.anchored {
+			subject = relative
+		}
+		if matched, _ := path.Match(pattern.glob, subject); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Excludes reports whether the file at relative, or any directory containing
+// it, is ignored.
+func (i *Ignore) Excludes(relative string) bool {
+	parts := strings.Split(relative, "/")
+	for end := 1; end < len(parts); end++ {
+		if i.Match(strings.Join(parts[:end], "/"), true) {
+			return true
+		}
+	}
+	return i.Match(relative, false)
+}
@@ -0,0 +1,330 @@
+// Package lint checks a tree of resources and vendors the way the server
+// loads them, reporting every problem found with the file and line causing
+// it.
+package lint
+
+import (
+	"errors"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/diagnostic"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/hubfile"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/usecases"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/vendor"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Severity string
+
+const (
+	ERROR   Severity = "error"
+	WARNING Severity = "warning"
+)
+
+// Codes of the problems found in the files themselves. Problems found across
+// files use the codes of the diagnostics reported by the server.
+const (
+	INVALID_FILE          = "invalid_file"
+	INVALID_RESOURCE      = "invalid_resource"
+	INVALID_VENDOR        = "invalid_vendor"
+	UNRESOLVED_DEPENDENCY = "unresolved_dependency"
+)
+
+// Problem is something wrong in a file. Line is 0 when the problem can't be
+// tied to a line.
+type Problem struct {
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`
+	File     string   `json:"file"`
+	Line     int      `json:"line,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// Report lists the files checked and the problems found in them.
+type Report struct {
+	Files    []string   `json:"files"`
+	Problems []*Problem `json:"problems"`
+}
+
+// Count returns the number of problems of the given severity.
+func (r *Report) Count(severity Severity) int {
+	count := 0
+	for _, problem := range r.Problems {
+		if problem.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// Options locate the trees to check and the Falco rules resources may use
+// without defining them. When nil, the partial list of NewFalcoDefaults is
+// used and the references it doesn't resolve are only warnings.
+type Options struct {
+	ResourcesPath string
+	VendorsPath   string
+	FalcoDefaults *resource.FalcoDefaults
+}
+
+// Run checks every resource and vendor file, then the references between
+// them when every file could be read.
+func Run(options Options) (*Report, error) {
+	for _, path := range []string{options.ResourcesPath, options.VendorsPath} {
+		if _, err := os.Stat(path); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &Report{Files: []string{}, Problems: []*Problem{}}
+	locations := map[string]location{}
+	resourcesRead, err := checkResourceFiles(report, options.ResourcesPath, locations)
+	if err != nil {
+		return nil, err
+	}
+	vendorsRead, err := checkVendorFiles(report, options.VendorsPath)
+	if err != nil {
+		return nil, err
+	}
+	if resourcesRead && vendorsRead {
+		if err := checkReferences(report, options, locations); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(report.Problems, func(i, j int) bool {
+		a, b := report.Problems[i], report.Problems[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return report, nil
+}
+
+type location struct {
+	file string
+	line int
+	// rules are the lines of the raw keys of the Falco rules files, when
+	// they could be found.
+	rules []int
+}
+
+var (
+	lineInError = regexp.MustCompile(`line (\d+)`)
+	lineInRule  = regexp.MustCompile(`^line (\d+): (.*)$`)
+	rawKey      = regexp.MustCompile(`^\s*(-\s+)?raw:\s*[|>]`)
+)
+
+// checkResourceFiles validates every resource, reporting whether every file
+// could be decoded. The location of each resource is recorded to report the
+// problems found across files.
+func checkResourceFiles(report *Report, root string, locations map[string]location) (bool, error) {
+	decoded := true
+	err := hubfile.Walk(root, func(path string, info os.FileInfo) error {
+		report.Files = append(report.Files, path)
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		resources, err := resource.ReadFile(path)
+		if err != nil {
+			decoded = false
+			report.add(ERROR, INVALID_FILE, path, lineOf(err), err.Error())
+			return nil
+		}
+
+		lines := documentLines(path, content, len(resources))
+		fileLines := strings.Split(string(content), "\n")
+		for i, res := range resources {
+			rules := ruleLines(res, fileLines, lines, i)
+			locations[res.ID+"@"+res.Version] = location{file: path, line: lines[i], rules: rules}
+
+			var validationError *resource.ValidationError
+			if err := res.Validate(); errors.As(err, &validationError) {
+				for _, message := range validationError.Errors {
+					line, message := locateRuleError(res, rules, message)
+					if line == 0 {
+						line = lines[i]
+					}
+					report.add(ERROR, INVALID_RESOURCE, path, line, message)
+				}
+			}
+		}
+		return nil
+	})
+	return decoded, err
+}
+
+func checkVendorFiles(report *Report, root string) (bool, error) {
+	decoded := true
+	err := hubfile.Walk(root, func(path string, info os.FileInfo) error {
+		report.Files = append(report.Files, path)
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		vendors, err := vendor.ReadFile(path)
+		if err != nil {
+			decoded = false
+			report.add(ERROR, INVALID_FILE, path, lineOf(err), err.Error())
+			return nil
+		}
+
+		lines := documentLines(path, content, len(vendors))
+		for i, v := range vendors {
+			if err := v.Validate(); err != nil {
+				report.add(ERROR, INVALID_VENDOR, path, lines[i], err.Error())
+			}
+		}
+		return nil
+	})
+	return decoded, err
+}
+
+// checkReferences loads the trees the way the server does, reporting the
+// diagnostics it would log and the macros and lists resources use without
+// defining them. Invalid IDs and kinds are already reported by the resource
+// validation.
+func checkReferences(report *Report, options Options, locations map[string]location) error {
+	resources, err := resource.FromPath(options.ResourcesPath)
+	if err != nil {
+		return err
+	}
+	vendors, err := vendor.FromPath(options.VendorsPath)
+	if err != nil {
+		return err
+	}
+
+	useCase := usecases.RetrieveDiagnostics{ResourceRepository: resources, VendorRepository: vendors}
+	diagnostics, err := useCase.Execute()
+	if err != nil {
+		return err
+	}
+	latest, err := resources.FindAll()
+	if err != nil {
+		return err
+	}
+	locationOf := map[string]location{}
+	for _, res := range latest {
+		locationOf[res.ID] = locations[res.ID+"@"+res.Version]
+	}
+
+	for _, d := range diagnostics {
+		switch d.Code {
+		case diagnostic.INVALID_ID, diagnostic.UNKNOWN_KIND, diagnostic.INVALID_PAYLOAD:
+			continue
+		case diagnostic.DUPLICATE_DEFINITION:
+			// Reported below, once for every resource defining the item.
+			continue
+		}
+		file, line := d.Source, 0
+		if file == "" && d.Resource != "" {
+			at := locationOf[d.Resource]
+			file, line = at.file, at.line
+		}
+		report.add(ERROR, string(d.Code), file, line, d.Message)
+	}
+	for _, duplicate := range resource.Duplicates(latest) {
+		for _, definition := range duplicate.Definitions {
+			at := locationOf[definition.Resource.ID]
+			line := at.line
+			if definition.File < len(at.rules) {
+				line = at.rules[definition.File] + definition.Item.Line
+			}
+			report.add(WARNING, string(diagnostic.DUPLICATE_DEFINITION), at.file, line, duplicate.String())
+		}
+	}
+	defaults := options.FalcoDefaults
+	if defaults == nil {
+		defaults = resource.NewFalcoDefaults()
+	}
+	severity := ERROR
+	if defaults.Partial {
+		// What partial defaults don't resolve may still be defined by Falco,
+		// so it is only worth a warning.
+		severity = WARNING
+		complete := *defaults
+		complete.Partial = false
+		defaults = &complete
+	}
+	analyzer := resource.NewDependencyAnalyzer(latest, defaults)
+	for _, res := range latest {
+		var validationError *resource.ValidationError
+		if err := analyzer.Validate(res); errors.As(err, &validationError) {
+			at := locations[res.ID+"@"+res.Version]
+			for _, message := range validationError.Errors {
+				report.add(severity, UNRESOLVED_DEPENDENCY, at.file, at.line, message)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *Report) add(severity Severity, code, file string, line int, message string) {
+	r.Problems = append(r.Problems, &Problem{Severity: severity, Code: code, File: file, Line: line, Message: message})
+}
+
+// documentLines returns the line each of the documents decoded from a file
+// starts at, or 0 for every document when they can't be told apart.
+func documentLines(path string, content []byte, documents int) []int {
+	lines := hubfile.DocumentLines(path, content)
+	if len(lines) != documents {
+		return make([]int, documents)
+	}
+	return lines
+}
+
+// ruleLines returns the line of the raw key of each Falco rules file of the
+// resource defined in the document at the given position, or nil when they
+// can't be found.
+func ruleLines(res *resource.Resource, fileLines []string, documentLines []int, document int) []int {
+	start := documentLines[document]
+	if start == 0 {
+		return nil
+	}
+	end := len(fileLines)
+	if document+1 < len(documentLines) {
+		end = documentLines[document+1] - 1
+	}
+
+	var lines []int
+	for number := start; number <= end && number <= len(fileLines); number++ {
+		if rawKey.MatchString(fileLines[number-1]) {
+			lines = append(lines, number)
+		}
+	}
+	if len(lines) != len(res.Rules) {
+		return nil
+	}
+	return lines
+}
+
+// locateRuleError turns a problem found in a Falco rules file of the
+// resource, positioned within the rules, into a line of the file.
+func locateRuleError(res *resource.Resource, rules []int, message string) (int, string) {
+	match := lineInRule.FindStringSubmatch(message)
+	if match == nil || rules == nil {
+		return 0, message
+	}
+	offset, _ := strconv.Atoi(match[1])
+	for i, rule := range res.Rules {
+		for _, problem := range rule.Validate() {
+			if problem == message {
+				return rules[i] + offset, match[2]
+			}
+		}
+	}
+	return 0, message
+}
+
+func lineOf(err error) int {
+	if match := lineInError.FindStringSubmatch(err.Error()); match != nil {
+		line, _ := strconv.Atoi(match[1])
+		return line
+	}
+	return 0
+}
@@ -0,0 +1,194 @@
+package lint
+
+import (
+	"bytes"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const validResource = `apiVersion: v1
+kind: FalcoRules
+vendor: Apache
+name: Apache
+icon: https://example.com/apache.png
+maintainers:
+  - name: nestorsalceda
+    email: nestor.salceda@sysdig.com
+rules:
+  - raw: |
+      - macro: apache_consider_syscalls
+        condition: (evt.num < 0)
+`
+
+const validVendor = `apiVersion: v1
+kind: Vendor
+name: Apache
+icon: https://example.com/apache.png
+website: https://apache.org/
+`
+
+func newTree(t *testing.T, resources, vendors map[string]string) Options {
+	root, err := ioutil.TempDir("", "lint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := Options{ResourcesPath: filepath.Join(root, "resources"), VendorsPath: filepath.Join(root, "vendors")}
+	writeFiles(t, options.ResourcesPath, resources)
+	writeFiles(t, options.VendorsPath, vendors)
+	return options
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func removeTree(options Options) {
+	os.RemoveAll(filepath.Dir(options.ResourcesPath))
+}
+
+func TestRunReportsNothingForTheFixtures(t *testing.T) {
+	report, err := Run(Options{ResourcesPath: "../../test/fixtures/resources", VendorsPath: "../../test/fixtures/vendors"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*Problem{}, report.Problems)
+	assert.Len(t, report.Files, 4)
+}
+
+func TestRunReportsInvalidResourcesAtTheirDocument(t *testing.T) {
+	options := newTree(t, map[string]string{
+		"apache.yaml": validResource + "---\napiVersion: v1\nkind: FalcoRules\nname: Nginx\nvendor: Apache\nicon: https://example.com/nginx.png\nmaintainers:\n  - name: nestorsalceda\n    email: nestor\n",
+	}, map[string]string{"apache.yaml": validVendor})
+	defer removeTree(options)
+	file := filepath.Join(options.ResourcesPath, "apache.yaml")
+
+	report, _ := Run(options)
+
+	assert.Equal(t, []*Problem{
+		{Severity: ERROR, Code: INVALID_RESOURCE, File: file, Line: 14, Message: `the maintainer "nestorsalceda" must have a valid email, like name@example.com`},
+	}, report.Problems)
+}
+
+func TestRunReportsInvalidRulesAtTheirLine(t *testing.T) {
+	options := newTree(t, map[string]string{
+		"apache.yaml": validResource + "      - macro: apache_broken\n        condition: (evt.num <\n",
+	}, map[string]string{"apache.yaml": validVendor})
+	defer removeTree(options)
+
+	report, _ := Run(options)
+
+	assert.Len(t, report.Problems, 1)
+	assert.Equal(t, INVALID_RESOURCE, report.Problems[0].Code)
+	assert.Equal(t, 13, report.Problems[0].Line)
+	assert.Contains(t, report.Problems[0].Message, `macro "apache_broken" has an invalid condition`)
+}
+
+func TestRunReportsFilesWhichCantBeDecoded(t *testing.T) {
+	options := newTree(t, map[string]string{
+		"apache.yaml": validResource,
+		"broken.yaml": "name: Broken\nkeywords: [\n",
+	}, map[string]string{"apache.yaml": validVendor})
+	defer removeTree(options)
+
+	report, _ := Run(options)
+
+	assert.Len(t, report.Problems, 1)
+	assert.Equal(t, INVALID_FILE, report.Problems[0].Code)
+	assert.Equal(t, filepath.Join(options.ResourcesPath, "broken.yaml"), report.Problems[0].File)
+	assert.Equal(t, 2, report.Problems[0].Line)
+}
+
+func TestRunReportsInvalidVendors(t *testing.T) {
+	options := newTree(t, map[string]string{"apache.yaml": validResource},
+		map[string]string{"apache.yaml": validVendor + "---\nkind: Vendor\nname: Nginx\n"})
+	defer removeTree(options)
+
+	report, _ := Run(options)
+
+	assert.Len(t, report.Problems, 1)
+	assert.Equal(t, INVALID_VENDOR, report.Problems[0].Code)
+	assert.Equal(t, 7, report.Problems[0].Line)
+}
+
+func TestRunReportsReferencesBetweenFiles(t *testing.T) {
+	options := newTree(t, map[string]string{
+		"apache.yaml": validResource,
+		"nginx.yaml":  "apiVersion: v1\nkind: FalcoRules\nvendor: Nginx\nname: Nginx\nicon: https://example.com/nginx.png\nmaintainers:\n  - name: nestorsalceda\n    email: nestor.salceda@sysdig.com\nrules:\n  - raw: |\n      - rule: nginx_shell\n        desc: A shell in nginx\n        condition: nginx_consider_syscalls\n        output: shell\n        priority: WARNING\n",
+	}, map[string]string{"apache.yaml": validVendor})
+	defer removeTree(options)
+	options.FalcoDefaults = &resource.FalcoDefaults{}
+	file := filepath.Join(options.ResourcesPath, "nginx.yaml")
+
+	report, _ := Run(options)
+
+	assert.Equal(t, 2, report.Count(ERROR))
+	assert.Equal(t, "unknown_vendor", report.Problems[0].Code)
+	assert.Equal(t, file, report.Problems[0].File)
+	assert.Equal(t, 1, report.Problems[0].Line)
+	assert.Equal(t, UNRESOLVED_DEPENDENCY, report.Problems[1].Code)
+	assert.Equal(t, file, report.Problems[1].File)
+	assert.Equal(t, 1, report.Problems[1].Line)
+}
+
+func TestRunReportsEveryDefinitionOfAnItemDefinedTwice(t *testing.T) {
+	options := newTree(t, map[string]string{
+		"apache.yaml": validResource,
+		"copy.yaml":   strings.Replace(validResource, "name: Apache", "name: Apache Copy", 1),
+	}, map[string]string{"apache.yaml": validVendor})
+	defer removeTree(options)
+
+	report, _ := Run(options)
+
+	assert.Equal(t, []*Problem{
+		{
+			Severity: WARNING,
+			Code:     "duplicate_definition",
+			File:     filepath.Join(options.ResourcesPath, "apache.yaml"),
+			Line:     11,
+			Message:  `the macro "apache_consider_syscalls" is defined by "apache", "apache-copy"`,
+		},
+		{
+			Severity: WARNING,
+			Code:     "duplicate_definition",
+			File:     filepath.Join(options.ResourcesPath, "copy.yaml"),
+			Line:     11,
+			Message:  `the macro "apache_consider_syscalls" is defined by "apache", "apache-copy"`,
+		},
+	}, report.Problems)
+}
+
+func TestRunOnlyWarnsAboutMacrosMissingFromTheBuiltInFalcoDefaults(t *testing.T) {
+	options := newTree(t, map[string]string{
+		"apache.yaml": strings.Replace(validResource, "(evt.num < 0)", "container_entrypoint", 1),
+	}, map[string]string{"apache.yaml": validVendor})
+	defer removeTree(options)
+
+	report, _ := Run(options)
+
+	assert.Equal(t, 0, report.Count(ERROR))
+	assert.Equal(t, 1, report.Count(WARNING))
+	assert.Equal(t, UNRESOLVED_DEPENDENCY, report.Problems[0].Code)
+}
+
+func TestRunFailsWhenATreeIsMissing(t *testing.T) {
+	_, err := Run(Options{ResourcesPath: "../../test/fixtures/resources", VendorsPath: "missing"})
+
+	assert.Error(t, err)
+}
+
+func TestWriteRejectsUnknownFormats(t *testing.T) {
+	err := (&Report{}).Write(&bytes.Buffer{}, "xml")
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,134 @@
+package lint
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Formats the report can be written in.
+const (
+	TEXT  = "text"
+	JSON  = "json"
+	JUNIT = "junit"
+)
+
+// ErrUnknownFormat is returned when writing a report in a format other than
+// TEXT, JSON or JUNIT.
+var ErrUnknownFormat = errors.New("unknown report format")
+
+// Write writes the report in the format, for people, tools or CI servers.
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case TEXT:
+		return r.writeText(w)
+	case JSON:
+		return r.writeJSON(w)
+	case JUNIT:
+		return r.writeJUnit(w)
+	}
+	return fmt.Errorf("%w: %q, use one of %s, %s or %s", ErrUnknownFormat, format, TEXT, JSON, JUNIT)
+}
+
+// String points at the problem the way compilers do, "file:line: severity:
+// message [code]".
+func (p *Problem) String() string {
+	position := p.File
+	if position == "" {
+		position = "hub"
+	}
+	if p.Line > 0 {
+		position = fmt.Sprintf("%s:%d", position, p.Line)
+	}
+	return fmt.Sprintf("%s: %s: %s [%s]", position, p.Severity, p.Message, p.Code)
+}
+
+func (r *Report) writeText(w io.Writer) error {
+	for _, problem := range r.Problems {
+		if _, err := fmt.Fprintln(w, problem); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d files checked, %d errors, %d warnings\n", len(r.Files), r.Count(ERROR), r.Count(WARNING))
+	return err
+}
+
+func (r *Report) writeJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+type junitSuite struct {
+	XMLName  xml.Name    `xml:"testsuite"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string          `xml:"name,attr"`
+	Classname string          `xml:"classname,attr"`
+	Failures  []*junitFailure `xml:"failure"`
+	SystemOut string          `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Type    string `xml:"type,attr"`
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit reports each file as a test case, failing with its errors.
+// Warnings don't fail the file, they are written to its output.
+func (r *Report) writeJUnit(w io.Writer) error {
+	suite := junitSuite{Name: "hubctl validate"}
+	cases := map[string]*junitCase{}
+	var files []string
+	caseFor := func(file string) *junitCase {
+		if file == "" {
+			file = "hub"
+		}
+		if _, ok := cases[file]; !ok {
+			cases[file] = &junitCase{Name: file, Classname: "hubctl.validate"}
+			files = append(files, file)
+		}
+		return cases[file]
+	}
+
+	for _, file := range r.Files {
+		caseFor(file)
+	}
+	for _, problem := range r.Problems {
+		testCase := caseFor(problem.File)
+		if problem.Severity == ERROR {
+			testCase.Failures = append(testCase.Failures, &junitFailure{Type: problem.Code, Message: problem.Message, Text: problem.String()})
+			continue
+		}
+		testCase.SystemOut = strings.TrimPrefix(testCase.SystemOut+"\n"+problem.String(), "\n")
+	}
+
+	for _, file := range files {
+		testCase := cases[file]
+		suite.Cases = append(suite.Cases, *testCase)
+		if len(testCase.Failures) > 0 {
+			suite.Failures++
+		}
+	}
+	suite.Tests = len(suite.Cases)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suite); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,53 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newReport() *Report {
+	return &Report{
+		Files: []string{"resources/apache.yaml", "resources/mongo.yaml"},
+		Problems: []*Problem{
+			{Severity: ERROR, Code: INVALID_RESOURCE, File: "resources/apache.yaml", Line: 14, Message: "the resource must have a valid icon"},
+			{Severity: WARNING, Code: "duplicate_definition", File: "resources/mongo.yaml", Message: "the macro \"shell\" is defined by several resources"},
+		},
+	}
+}
+
+func TestReportWritesText(t *testing.T) {
+	var output bytes.Buffer
+
+	assert.NoError(t, newReport().Write(&output, TEXT))
+	assert.Equal(t, `resources/apache.yaml:14: error: the resource must have a valid icon [invalid_resource]
+resources/mongo.yaml: warning: the macro "shell" is defined by several resources [duplicate_definition]
+2 files checked, 1 errors, 1 warnings
+`, output.String())
+}
+
+func TestReportWritesJSON(t *testing.T) {
+	var output bytes.Buffer
+	var decoded Report
+
+	assert.NoError(t, newReport().Write(&output, JSON))
+	assert.NoError(t, json.Unmarshal(output.Bytes(), &decoded))
+	assert.Equal(t, newReport(), &decoded)
+}
+
+func TestReportWritesJUnit(t *testing.T) {
+	var output bytes.Buffer
+
+	assert.NoError(t, newReport().Write(&output, JUNIT))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="hubctl validate" tests="2" failures="1">
+  <testcase name="resources/apache.yaml" classname="hubctl.validate">
+    <failure type="invalid_resource" message="the resource must have a valid icon">resources/apache.yaml:14: error: the resource must have a valid icon [invalid_resource]</failure>
+  </testcase>
+  <testcase name="resources/mongo.yaml" classname="hubctl.validate">
+    <system-out>resources/mongo.yaml: warning: the macro &#34;shell&#34; is defined by several resources [duplicate_definition]</system-out>
+  </testcase>
+</testsuite>
+`, output.String())
+}
@@ -0,0 +1,245 @@
+// Package metrics keeps counters and histograms and writes them in the
+// Prometheus text exposition format, so the hub can be scraped without
+// depending on a client library.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the media type of the metrics written by a Registry.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets suit latencies in seconds, from 5ms to 10s.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Emit reports the value of a series of a metric collected by a function.
+type Emit func(value float64, labelValues ...string)
+
+// Registry holds the metrics of a process, written in the order they were
+// registered.
+type Registry struct {
+	mutex    sync.Mutex
+	families []family
+}
+
+type family interface {
+	write(w *bufio.Writer)
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(f family) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.families = append(r.families, f)
+}
+
+// Write writes every metric in the Prometheus text format.
+func (r *Registry) Write(w io.Writer) error {
+	r.mutex.Lock()
+	families := append([]family(nil), r.families...)
+	r.mutex.Unlock()
+
+	buffered := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(buffered)
+	}
+	return buffered.Flush()
+}
+
+// series holds the values of a metric for each combination of label values.
+type series struct {
+	mutex  sync.Mutex
+	labels []string
+	values map[string][]string
+}
+
+func newSeries(labels []string) series {
+	return series{labels: labels, values: map[string][]string{}}
+}
+
+// key identifies the label values of a series, failing when their number
+// doesn't match the labels of the metric.
+func (s *series) key(labelValues []string) string {
+	if len(labelValues) != len(s.labels) {
+		panic(fmt.Sprintf("metrics: got %d label values for labels %v", len(labelValues), s.labels))
+	}
+	key := strings.Join(labelValues, "\xff")
+	if _, ok := s.values[key]; !ok {
+		s.values[key] = append([]string(nil), labelValues...)
+	}
+	return key
+}
+
+// sortedKeys returns the keys of the series in the order they are written.
+func (s *series) sortedKeys() []string {
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Counter is a value which only goes up, like the number of requests served.
+type Counter struct {
+	name, help string
+	series
+	counts map[string]float64
+}
+
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	counter := &Counter{name: name, help: help, series: newSeries(labels), counts: map[string]float64{}}
+	r.register(counter)
+	return counter
+}
+
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *Counter) Add(value float64, labelValues ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.counts[c.key(labelValues)] += value
+}
+
+func (c *Counter) write(w *bufio.Writer) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	writeHeader(w, c.name, c.help, "counter")
+	for _, key := range c.sortedKeys() {
+		writeSample(w, c.name, c.labels, c.values[key], nil, c.counts[key])
+	}
+}
+
+// Histogram counts observations, like request latencies, in buckets.
+type Histogram struct {
+	name, help string
+	series
+	buckets []float64
+	counts  map[string][]uint64
+	sums    map[string]float64
+	totals  map[string]uint64
+}
+
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	histogram := &Histogram{
+		name: name, help: help, series: newSeries(labels), buckets: buckets,
+		counts: map[string][]uint64{}, sums: map[string]float64{}, totals: map[string]uint64{},
+	}
+	r.register(histogram)
+	return histogram
+}
+
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	key := h.key(labelValues)
+	if h.counts[key] == nil {
+		h.counts[key] = make([]uint64, len(h.buckets))
+	}
+	for i, bound := range h.buckets {
+		if value <= bound {
+			h.counts[key][i]++
+		}
+	}
+	h.sums[key] += value
+	h.totals[key]++
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	writeHeader(w, h.name, h.help, "histogram")
+	for _, key := range h.sortedKeys() {
+		for i, bound := range h.buckets {
+			writeSample(w, h.name+"_bucket", h.labels, h.values[key], []string{"le", formatValue(bound)}, float64(h.counts[key][i]))
+		}
+		writeSample(w, h.name+"_bucket", h.labels, h.values[key], []string{"le", "+Inf"}, float64(h.totals[key]))
+		writeSample(w, h.name+"_sum", h.labels, h.values[key], nil, h.sums[key])
+		writeSample(w, h.name+"_count", h.labels, h.values[key], nil, float64(h.totals[key]))
+	}
+}
+
+// collected is a metric whose values are kept elsewhere, read by a function
+// each time the metrics are written.
+type collected struct {
+	name, help, kind string
+	labels           []string
+	collect          func(emit Emit)
+}
+
+// NewCounterFunc registers a counter kept elsewhere, like by the
+// repositories, which collect emits each time the metrics are written.
+func (r *Registry) NewCounterFunc(name, help string, labels []string, collect func(emit Emit)) {
+	r.register(&collected{name: name, help: help, kind: "counter", labels: labels, collect: collect})
+}
+
+// NewGaugeFunc registers a value which can go up and down, like the number
+// of resources loaded, which collect emits each time the metrics are written.
+func (r *Registry) NewGaugeFunc(name, help string, labels []string, collect func(emit Emit)) {
+	r.register(&collected{name: name, help: help, kind: "gauge", labels: labels, collect: collect})
+}
+
+func (c *collected) write(w *bufio.Writer) {
+	s := newSeries(c.labels)
+	values := map[string]float64{}
+	c.collect(func(value float64, labelValues ...string) {
+		values[s.key(labelValues)] = value
+	})
+
+	writeHeader(w, c.name, c.help, c.kind)
+	for _, key := range s.sortedKeys() {
+		writeSample(w, c.name, c.labels, s.values[key], nil, values[key])
+	}
+}
+
+func writeHeader(w *bufio.Writer, name, help, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, kind)
+}
+
+// writeSample writes a line of a series. extra is an additional label name
+// and value, like the bound of a histogram bucket.
+func writeSample(w *bufio.Writer, name string, labels, labelValues, extra []string, value float64) {
+	var pairs []string
+	for i, label := range labels {
+		pairs = append(pairs, label+`="`+escapeLabel(labelValues[i])+`"`)
+	}
+	if extra != nil {
+		pairs = append(pairs, extra[0]+`="`+escapeLabel(extra[1])+`"`)
+	}
+
+	w.WriteString(name)
+	if len(pairs) > 0 {
+		w.WriteString("{" + strings.Join(pairs, ",") + "}")
+	}
+	w.WriteString(" " + formatValue(value) + "\n")
+}
+
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
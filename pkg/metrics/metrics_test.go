@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func written(registry *Registry) string {
+	var output bytes.Buffer
+	registry.Write(&output)
+	return output.String()
+}
+
+func TestCountersAreWrittenPerLabelValues(t *testing.T) {
+	registry := NewRegistry()
+	requests := registry.NewCounter("requests_total", "Requests served.", "route", "status")
+
+	requests.Inc("/resources", "200")
+	requests.Inc("/resources", "200")
+	requests.Add(3, "/vendors", "404")
+
+	assert.Equal(t, `# HELP requests_total Requests served.
+# TYPE requests_total counter
+requests_total{route="/resources",status="200"} 2
+requests_total{route="/vendors",status="404"} 3
+`, written(registry))
+}
+
+func TestHistogramsCountObservationsInCumulativeBuckets(t *testing.T) {
+	registry := NewRegistry()
+	latency := registry.NewHistogram("latency_seconds", "Latency.", []float64{0.1, 1})
+
+	latency.Observe(0.05)
+	latency.Observe(0.5)
+	latency.Observe(2)
+
+	assert.Equal(t, `# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.1"} 1
+latency_seconds_bucket{le="1"} 2
+latency_seconds_bucket{le="+Inf"} 3
+latency_seconds_sum 2.55
+latency_seconds_count 3
+`, written(registry))
+}
+
+func TestFunctionsAreCollectedWhenWriting(t *testing.T) {
+	registry := NewRegistry()
+	loaded := 1
+	registry.NewGaugeFunc("loaded", "Loaded items.", []string{"kind"}, func(emit Emit) {
+		emit(float64(loaded), "FalcoRules")
+	})
+	registry.NewCounterFunc("loads_total", "Loads.", nil, func(emit Emit) {
+		emit(4)
+	})
+
+	loaded = 2
+
+	assert.Equal(t, `# HELP loaded Loaded items.
+# TYPE loaded gauge
+loaded{kind="FalcoRules"} 2
+# HELP loads_total Loads.
+# TYPE loads_total counter
+loads_total 4
+`, written(registry))
+}
+
+func TestLabelValuesAreEscaped(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounter("downloads_total", "Downloads.", "resource").Inc("a\"b\\c\nd")
+
+	assert.Contains(t, written(registry), `downloads_total{resource="a\"b\\c\nd"} 1`)
+}
+
+func TestWrongNumberOfLabelValuesPanics(t *testing.T) {
+	counter := NewRegistry().NewCounter("requests_total", "Requests served.", "route")
+
+	assert.Panics(t, func() { counter.Inc("/resources", "200") })
+}
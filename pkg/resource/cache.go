@@ -0,0 +1,137 @@
+package resource
+
+import (
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/diagnostic"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cache holds the resources loaded by a repository which reads them in bulk,
+// so they can be swapped atomically when the source changes.
+type cache struct {
+	mutex       sync.RWMutex
+	resources   []*Resource
+	versions    map[string][]*Resource
+	aliases     map[string]string
+	index       *Index
+	diagnostics []*diagnostic.Diagnostic
+	err         error
+	loadStats   LoadStats
+	// taxonomy normalizes the keywords of the resources swapped in.
+	taxonomy *Taxonomy
+}
+
+func (c *cache) findAll() ([]*Resource, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.resources, c.err
+}
+
+func (c *cache) findById(id string) (*Resource, error) {
+	versions, err := c.findVersions(id)
+	if err != nil {
+		return nil, err
+	}
+	return versions[0], nil
+}
+
+func (c *cache) findVersions(id string) ([]*Resource, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	if len(c.resources) == 0 {
+		return nil, ErrEmptyRepository
+	}
+
+	versions, ok := c.versions[strings.ToLower(id)]
+	if !ok {
+		if to, ok := c.aliases[strings.ToLower(id)]; ok {
+			return nil, &MovedError{ID: id, To: to}
+		}
+		return nil, notFound(id)
+	}
+	return versions, nil
+}
+
+func (c *cache) findVersion(id, version string) (*Resource, error) {
+	versions, err := c.findVersions(id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, resource := range versions {
+		if resource.Version == version {
+			return resource, nil
+		}
+	}
+	return nil, versionNotFound(id, version)
+}
+
+func (c *cache) getDiagnostics() []*diagnostic.Diagnostic {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.diagnostics
+}
+
+func (c *cache) getIndex() (*Index, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.index, c.err
+}
+
+func (c *cache) setTaxonomy(taxonomy *Taxonomy) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.taxonomy = taxonomy
+}
+
+func (c *cache) swap(resources []*Resource, diagnostics []*diagnostic.Diagnostic, err error) {
+	c.mutex.RLock()
+	c.taxonomy.Normalize(resources)
+	c.mutex.RUnlock()
+
+	for _, resource := range resources {
+		for _, rule := range resource.Rules {
+			rule.parse()
+		}
+	}
+	latest, versions := latestVersions(resources)
+	index := NewIndex(latest)
+	aliases := aliasesOf(resources)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.resources = latest
+	c.versions = versions
+	c.aliases = aliases
+	c.index = index
+	c.diagnostics = diagnostics
+	c.err = backendError(err)
+}
+
+// recordLoad accounts for a load of the resources which started at started.
+func (c *cache) recordLoad(started time.Time, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.loadStats.Loads++
+	if err != nil {
+		c.loadStats.Failures++
+	}
+	c.loadStats.LastDuration = time.Since(started)
+}
+
+func (c *cache) getLoadStats() LoadStats {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.loadStats
+}
@@ -0,0 +1,137 @@
+package resource
+
+import (
+	"fmt"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/diagnostic"
+	"strings"
+)
+
+// ConflictError lists the rules, macros and lists defined by more than one of
+// the resources deployed together. Falco keeps the last definition it
+// loads, so one of them would be silently ignored.
+type ConflictError struct {
+	Conflicts []string
+}
+
+func (e *ConflictError) Error() string {
+	return strings.Join(e.Conflicts, ",")
+}
+
+// CheckConflicts fails when several resources define a rule, macro or list
+// with the same name, so they can't be deployed together.
+func CheckConflicts(resources []*Resource) error {
+	definedBy := map[definitionKey]string{}
+	var conflicts []string
+
+	for _, resource := range resources {
+		items, err := itemsOf(resource)
+		if err != nil {
+			return &ValidationError{Errors: []string{fmt.Sprintf("resource %q: %s", resource.ID, err)}}
+		}
+		for _, item := range items {
+			if item.Overrides() {
+				continue
+			}
+			key := definitionKey{Type: item.Type, Name: item.Name}
+			if previous, ok := definedBy[key]; ok && previous != resource.ID {
+				conflicts = append(conflicts, fmt.Sprintf("the %s %q is defined by both %q and %q", item.Type, item.Name, previous, resource.ID))
+				continue
+			}
+			definedBy[key] = resource.ID
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return &ConflictError{Conflicts: conflicts}
+	}
+	return nil
+}
+
+// Duplicate is a rule, macro or list defined by more than one resource.
+type Duplicate struct {
+	Type        FalcoItemType
+	Name        string
+	Definitions []*Definition
+}
+
+// Definition is where a resource defines an item: File is the position of
+// the Falco rules file among the rules of the resource.
+type Definition struct {
+	Resource *Resource
+	File     int
+	Item     *FalcoItem
+}
+
+func (d *Duplicate) String() string {
+	ids := make([]string, len(d.Definitions))
+	for i, definition := range d.Definitions {
+		ids[i] = idOf(definition.Resource)
+	}
+	return fmt.Sprintf("the %s %q is defined by %s", d.Type, d.Name, quoted(ids))
+}
+
+// Duplicates returns the rules, macros and lists defined by more than one of
+// the resources, with the first definition of each resource. Resources whose
+// rules can't be parsed are skipped.
+func Duplicates(resources []*Resource) []*Duplicate {
+	definitions := map[definitionKey][]*Definition{}
+	var keys []definitionKey
+	for _, resource := range resources {
+		if _, err := itemsOf(resource); err != nil {
+			continue
+		}
+		for file, rule := range resource.Rules {
+			items, _ := rule.items()
+			for _, item := range items {
+				key := definitionKey{Type: item.Type, Name: item.Name}
+				if item.Overrides() || definedBy(definitions[key], resource) {
+					continue
+				}
+				if len(definitions[key]) == 0 {
+					keys = append(keys, key)
+				}
+				definitions[key] = append(definitions[key], &Definition{Resource: resource, File: file, Item: item})
+			}
+		}
+	}
+
+	var duplicates []*Duplicate
+	for _, key := range keys {
+		if len(definitions[key]) > 1 {
+			duplicates = append(duplicates, &Duplicate{Type: key.Type, Name: key.Name, Definitions: definitions[key]})
+		}
+	}
+	return duplicates
+}
+
+func definedBy(definitions []*Definition, resource *Resource) bool {
+	for _, definition := range definitions {
+		if idOf(definition.Resource) == idOf(resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// DiagnoseConflicts reports the rules, macros and lists defined by more than
+// one of the resources served, as Falco would only keep one of them when
+// they are deployed together.
+func DiagnoseConflicts(resources []*Resource) []*diagnostic.Diagnostic {
+	var diagnostics []*diagnostic.Diagnostic
+	for _, duplicate := range Duplicates(resources) {
+		diagnostics = append(diagnostics, &diagnostic.Diagnostic{
+			Code:    diagnostic.DUPLICATE_DEFINITION,
+			Message: duplicate.String(),
+		})
+	}
+	return diagnostics
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
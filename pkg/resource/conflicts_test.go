@@ -0,0 +1,48 @@
+package resource
+
+import (
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/diagnostic"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCheckConflictsReportsItemsDefinedByDifferentResources(t *testing.T) {
+	apache := resourceWithRules("apache", "- macro: web_server\n  condition: proc.name = httpd\n- rule: Shell in web server\n  condition: web_server\n")
+	nginx := resourceWithRules("nginx", "- macro: web_server\n  condition: proc.name = nginx\n- rule: Shell in web server\n  condition: web_server\n")
+	extension := resourceWithRules("extension", "- macro: web_server\n  append: true\n  condition: or proc.name = caddy\n")
+
+	err := CheckConflicts([]*Resource{apache, nginx, extension})
+
+	assert.Equal(t, &ConflictError{Conflicts: []string{
+		`the macro "web_server" is defined by both "apache" and "nginx"`,
+		`the rule "Shell in web server" is defined by both "apache" and "nginx"`,
+	}}, err)
+}
+
+func TestCheckConflictsAcceptsResourcesWithDifferentItems(t *testing.T) {
+	apache := resourceWithRules("apache", "- macro: apache_consider_syscalls\n  condition: (evt.num < 0)\n")
+	mongodb := resourceWithRules("mongodb", "- macro: mongo_consider_syscalls\n  condition: (evt.num < 0)\n")
+
+	assert.NoError(t, CheckConflicts([]*Resource{apache, mongodb}))
+}
+
+func TestCheckConflictsAcceptsRulesOnlyDisablingARule(t *testing.T) {
+	apache := resourceWithRules("apache", "- rule: Shell in web server\n  condition: proc.name = httpd\n")
+	quiet := resourceWithRules("quiet", "- rule: Shell in web server\n  enabled: false\n")
+
+	assert.NoError(t, CheckConflicts([]*Resource{apache, quiet}))
+}
+
+func TestDiagnoseConflictsReportsEveryResourceDefiningAnItem(t *testing.T) {
+	apache := resourceWithRules("apache", "- macro: web_server\n  condition: proc.name = httpd\n")
+	nginx := resourceWithRules("nginx", "- macro: web_server\n  condition: proc.name = nginx\n- macro: web_server\n  condition: proc.name = nginx\n")
+	caddy := resourceWithRules("caddy", "- macro: web_server\n  condition: proc.name = caddy\n")
+	extension := resourceWithRules("extension", "- macro: web_server\n  append: true\n  condition: or proc.name = lighttpd\n")
+
+	diagnostics := DiagnoseConflicts([]*Resource{apache, nginx, caddy, extension})
+
+	assert.Equal(t, []*diagnostic.Diagnostic{{
+		Code:    diagnostic.DUPLICATE_DEFINITION,
+		Message: `the macro "web_server" is defined by "apache", "nginx", "caddy"`,
+	}}, diagnostics)
+}
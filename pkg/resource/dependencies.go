@@ -0,0 +1,240 @@
+package resource
+
+import (
+	"fmt"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/falco"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type DependencySource string
+
+const (
+	LOCAL_DEPENDENCY      DependencySource = "local"
+	HUB_DEPENDENCY        DependencySource = "hub"
+	FALCO_DEPENDENCY      DependencySource = "falco"
+	UNRESOLVED_DEPENDENCY DependencySource = "unresolved"
+)
+
+// Dependency is a macro or list used by the rules of a resource, and where
+// its definition was found.
+type Dependency struct {
+	Type      FalcoItemType    `json:"type"`
+	Name      string           `json:"name"`
+	Source    DependencySource `json:"source"`
+	Resources []string         `json:"resources,omitempty"`
+	UsedBy    []string         `json:"usedBy"`
+}
+
+type Dependencies struct {
+	Resource     string        `json:"resource"`
+	Dependencies []*Dependency `json:"dependencies"`
+}
+
+func (d *Dependencies) Unresolved() []*Dependency {
+	var unresolved []*Dependency
+	for _, dependency := range d.Dependencies {
+		if dependency.Source == UNRESOLVED_DEPENDENCY {
+			unresolved = append(unresolved, dependency)
+		}
+	}
+	return unresolved
+}
+
+// DependencyAnalyzer resolves the macros and lists used by a resource against
+// its own definitions, the definitions of the other resources in the hub and
+// the Falco defaults, in that order.
+type DependencyAnalyzer struct {
+	definitions map[FalcoItemType]map[string][]string
+	defaults    map[FalcoItemType]map[string]bool
+	// partial is set when the defaults don't list every macro of Falco.
+	partial bool
+}
+
+func NewDependencyAnalyzer(resources []*Resource, defaults *FalcoDefaults) *DependencyAnalyzer {
+	analyzer := &DependencyAnalyzer{
+		definitions: map[FalcoItemType]map[string][]string{FALCO_MACRO_ITEM: {}, FALCO_LIST_ITEM: {}},
+		defaults:    map[FalcoItemType]map[string]bool{FALCO_MACRO_ITEM: {}, FALCO_LIST_ITEM: {}},
+	}
+
+	for _, resource := range resources {
+		for key := range definedItems(resource) {
+			analyzer.definitions[key.Type][key.Name] = append(analyzer.definitions[key.Type][key.Name], resource.ID)
+		}
+	}
+	if defaults != nil {
+		analyzer.partial = defaults.Partial
+		for _, macro := range defaults.Macros {
+			analyzer.defaults[FALCO_MACRO_ITEM][macro] = true
+		}
+		for _, list := range defaults.Lists {
+			analyzer.defaults[FALCO_LIST_ITEM][list] = true
+		}
+	}
+
+	return analyzer
+}
+
+// Analyze returns every macro used by the rules of the resource, and every
+// list they use. Unquoted values inside lists that don't name any known list
+// are literal values for Falco, so only macros may be unresolved.
+func (a *DependencyAnalyzer) Analyze(resource *Resource) (*Dependencies, error) {
+	items, err := itemsOf(resource)
+	if err != nil {
+		return nil, err
+	}
+	local := definedItems(resource)
+
+	dependencies := map[definitionKey]*Dependency{}
+	use := func(itemType FalcoItemType, name string, user *FalcoItem) {
+		key := definitionKey{Type: itemType, Name: name}
+		dependency, ok := dependencies[key]
+		if !ok {
+			dependency = a.resolve(key, resource.ID, local)
+			if dependency == nil {
+				return
+			}
+			dependencies[key] = dependency
+		}
+		for _, usedBy := range dependency.UsedBy {
+			if usedBy == user.Name {
+				return
+			}
+		}
+		dependency.UsedBy = append(dependency.UsedBy, user.Name)
+	}
+
+	for _, item := range items {
+		if item.Append && item.Type != FALCO_RULE_ITEM {
+			use(item.Type, item.Name, item)
+		}
+		for _, listItem := range item.Items {
+			use(FALCO_LIST_ITEM, listItem, item)
+		}
+		if item.Condition == "" {
+			continue
+		}
+
+		parse := falco.ParseCondition
+		if item.Append {
+			parse = falco.ParseAppendedCondition
+		}
+		expr, err := parse(item.Condition)
+		if err != nil {
+			return nil, fmt.Errorf("%s %q has an invalid condition at %s", item.Type, item.Name, err)
+		}
+		falco.Walk(expr, func(expr falco.Expr) {
+			switch e := expr.(type) {
+			case *falco.MacroRef:
+				use(FALCO_MACRO_ITEM, e.Name, item)
+			case *falco.Check:
+				for _, value := range e.Values {
+					if !value.Quoted {
+						use(FALCO_LIST_ITEM, value.Value, item)
+					}
+				}
+			}
+		})
+	}
+
+	result := &Dependencies{Resource: resource.ID, Dependencies: []*Dependency{}}
+	for _, dependency := range dependencies {
+		result.Dependencies = append(result.Dependencies, dependency)
+	}
+	sort.Slice(result.Dependencies, func(i, j int) bool {
+		if result.Dependencies[i].Type != result.Dependencies[j].Type {
+			return result.Dependencies[i].Type < result.Dependencies[j].Type
+		}
+		return result.Dependencies[i].Name < result.Dependencies[j].Name
+	})
+	return result, nil
+}
+
+// Validate fails when the resource uses macros which are not defined anywhere.
+// Macros missing from partial defaults may be defined by Falco, so they are
+// only reported when the defaults are complete.
+func (a *DependencyAnalyzer) Validate(resource *Resource) error {
+	dependencies, err := a.Analyze(resource)
+	if err != nil {
+		return &ValidationError{Errors: []string{err.Error()}}
+	}
+	if a.partial {
+		return nil
+	}
+
+	var errors []string
+	for _, dependency := range dependencies.Unresolved() {
+		errors = append(errors, fmt.Sprintf("the %s %q used by %s is not defined in the resource, the hub or Falco",
+			dependency.Type, dependency.Name, quoted(dependency.UsedBy)))
+	}
+	if len(errors) > 0 {
+		return &ValidationError{Errors: errors}
+	}
+	return nil
+}
+
+func (a *DependencyAnalyzer) resolve(key definitionKey, resourceID string, local map[definitionKey]bool) *Dependency {
+	dependency := &Dependency{Type: key.Type, Name: key.Name}
+
+	if local[key] {
+		dependency.Source = LOCAL_DEPENDENCY
+		return dependency
+	}
+	for _, id := range a.definitions[key.Type][key.Name] {
+		if id != resourceID {
+			dependency.Resources = append(dependency.Resources, id)
+		}
+	}
+	switch {
+	case len(dependency.Resources) > 0:
+		dependency.Source = HUB_DEPENDENCY
+	case a.defaults[key.Type][key.Name]:
+		dependency.Source = FALCO_DEPENDENCY
+	case key.Type == FALCO_MACRO_ITEM:
+		dependency.Source = UNRESOLVED_DEPENDENCY
+	default:
+		return nil
+	}
+	return dependency
+}
+
+func quoted(names []string) string {
+	result := make([]string, len(names))
+	for i, name := range names {
+		result[i] = strconv.Quote(name)
+	}
+	return strings.Join(result, ", ")
+}
+
+type definitionKey struct {
+	Type FalcoItemType
+	Name string
+}
+
+// definedItems returns the macros and lists a resource defines by name.
+// Appending to a macro or list extends a definition made somewhere else, so
+// those entries are not definitions.
+func definedItems(resource *Resource) map[definitionKey]bool {
+	items, _ := itemsOf(resource)
+
+	defined := map[definitionKey]bool{}
+	for _, item := range items {
+		if item.Type != FALCO_RULE_ITEM && !item.Append {
+			defined[definitionKey{Type: item.Type, Name: item.Name}] = true
+		}
+	}
+	return defined
+}
+
+func itemsOf(resource *Resource) ([]*FalcoItem, error) {
+	var items []*FalcoItem
+	for _, rule := range resource.Rules {
+		parsed, err := rule.items()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, parsed...)
+	}
+	return items, nil
+}
@@ -0,0 +1,130 @@
+package resource
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func resourceWithRules(id, rules string) *Resource {
+	return &Resource{ID: id, Kind: FALCO_RULES, Rules: []*FalcoRuleData{{Raw: rules}}}
+}
+
+func TestDependencyAnalyzerResolvesMacrosAndListsFromEverySource(t *testing.T) {
+	shared := resourceWithRules("shared", `
+- macro: web_server
+  condition: proc.name in (web_binaries)
+- list: web_binaries
+  items: [nginx, httpd]
+`)
+	nginx := resourceWithRules("nginx", `
+- list: nginx_binaries
+  items: [nginx, web_binaries]
+- macro: nginx_consider_syscalls
+  condition: evt.num < 0
+- rule: Shell in nginx
+  condition: spawned_process and web_server and proc.pname in (nginx_binaries, shell_binaries, sshd, "web_binaries")
+- rule: Write below nginx config
+  condition: open_write and nginx_consider_syscalls and not wrong_macro
+`)
+	analyzer := NewDependencyAnalyzer([]*Resource{shared, nginx}, NewFalcoDefaults())
+
+	dependencies, err := analyzer.Analyze(nginx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Dependencies{
+		Resource: "nginx",
+		Dependencies: []*Dependency{
+			{Type: FALCO_LIST_ITEM, Name: "nginx_binaries", Source: LOCAL_DEPENDENCY, UsedBy: []string{"Shell in nginx"}},
+			{Type: FALCO_LIST_ITEM, Name: "shell_binaries", Source: FALCO_DEPENDENCY, UsedBy: []string{"Shell in nginx"}},
+			{Type: FALCO_LIST_ITEM, Name: "web_binaries", Source: HUB_DEPENDENCY, Resources: []string{"shared"}, UsedBy: []string{"nginx_binaries"}},
+			{Type: FALCO_MACRO_ITEM, Name: "nginx_consider_syscalls", Source: LOCAL_DEPENDENCY, UsedBy: []string{"Write below nginx config"}},
+			{Type: FALCO_MACRO_ITEM, Name: "open_write", Source: FALCO_DEPENDENCY, UsedBy: []string{"Write below nginx config"}},
+			{Type: FALCO_MACRO_ITEM, Name: "spawned_process", Source: FALCO_DEPENDENCY, UsedBy: []string{"Shell in nginx"}},
+			{Type: FALCO_MACRO_ITEM, Name: "web_server", Source: HUB_DEPENDENCY, Resources: []string{"shared"}, UsedBy: []string{"Shell in nginx"}},
+			{Type: FALCO_MACRO_ITEM, Name: "wrong_macro", Source: UNRESOLVED_DEPENDENCY, UsedBy: []string{"Write below nginx config"}},
+		},
+	}, dependencies)
+}
+
+func TestDependencyAnalyzerTreatsAppendedMacrosAsDependencies(t *testing.T) {
+	extension := resourceWithRules("extension", `
+- macro: spawned_process
+  append: true
+  condition: or evt.type = execveat
+- macro: missing_macro
+  append: true
+  condition: and proc.name = bash
+`)
+	analyzer := NewDependencyAnalyzer([]*Resource{extension}, &FalcoDefaults{Macros: []string{"spawned_process"}})
+
+	err := analyzer.Validate(extension)
+
+	assert.Equal(t, &ValidationError{Errors: []string{
+		`the macro "missing_macro" used by "missing_macro" is not defined in the resource, the hub or Falco`,
+	}}, err)
+}
+
+func TestDependencyAnalyzerOnlyRejectsUnresolvedMacrosWithCompleteDefaults(t *testing.T) {
+	nginx := resourceWithRules("nginx", "- rule: Shell in nginx\n  condition: container_entrypoint\n")
+	analyzer := NewDependencyAnalyzer([]*Resource{nginx}, NewFalcoDefaults())
+
+	dependencies, _ := analyzer.Analyze(nginx)
+
+	assert.NoError(t, analyzer.Validate(nginx))
+	assert.Len(t, dependencies.Unresolved(), 1)
+}
+
+func TestDependencyAnalyzerIgnoresDefinitionsOfThePreviousVersionOfTheResource(t *testing.T) {
+	stored := resourceWithRules("nginx", "- macro: web_server\n  condition: proc.name = nginx\n")
+	updated := resourceWithRules("nginx", "- rule: Shell in nginx\n  condition: web_server\n")
+	analyzer := NewDependencyAnalyzer([]*Resource{stored}, nil)
+
+	err := analyzer.Validate(updated)
+
+	assert.Equal(t, &ValidationError{Errors: []string{
+		`the macro "web_server" used by "Shell in nginx" is not defined in the resource, the hub or Falco`,
+	}}, err)
+}
+
+func TestFalcoDefaultsAreReadFromARulesFile(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "falco")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "falco_rules.yaml")
+	writeFile(t, path, "- list: shell_binaries\n  items: [bash]\n- macro: spawned_process\n  condition: evt.type = execve\n")
+
+	defaults, err := FalcoDefaultsFromFile(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &FalcoDefaults{Macros: []string{"spawned_process"}, Lists: []string{"shell_binaries"}}, defaults)
+}
+
+func TestFalcoDefaultsNameEachItemOnce(t *testing.T) {
+	defaults := NewFalcoDefaults()
+	macros := map[string]bool{}
+	for _, macro := range defaults.Macros {
+		assert.False(t, macros[macro], "the macro %q is repeated", macro)
+		macros[macro] = true
+	}
+	lists := map[string]bool{}
+	for _, list := range defaults.Lists {
+		assert.False(t, lists[list], "the list %q is repeated", list)
+		assert.False(t, macros[list], "%q is both a macro and a list", list)
+		lists[list] = true
+	}
+}
+
+func TestDependencyAnalyzerResolvesTheListsShippedWithFalco(t *testing.T) {
+	sshd := resourceWithRules("sshd", "- rule: Shell run by a system user\n  condition: proc.name in (shell_binaries) and user.name in (system_users)\n")
+	analyzer := NewDependencyAnalyzer([]*Resource{sshd}, NewFalcoDefaults())
+
+	dependencies, err := analyzer.Analyze(sshd)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*Dependency{
+		{Type: FALCO_LIST_ITEM, Name: "shell_binaries", Source: FALCO_DEPENDENCY, UsedBy: []string{"Shell run by a system user"}},
+		{Type: FALCO_LIST_ITEM, Name: "system_users", Source: FALCO_DEPENDENCY, UsedBy: []string{"Shell run by a system user"}},
+	}, dependencies.Dependencies)
+}
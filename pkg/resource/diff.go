@@ -0,0 +1,117 @@
+package resource
+
+import (
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/diff"
+	"reflect"
+	"strings"
+)
+
+// ItemChange is a rule, macro or list whose definition changed between two
+// versions of a resource.
+type ItemChange struct {
+	Type FalcoItemType `json:"type"`
+	Name string        `json:"name"`
+	From *FalcoItem    `json:"from"`
+	To   *FalcoItem    `json:"to"`
+}
+
+// Diff lists the rules, macros and lists added, removed or modified between
+// two versions of a resource, matched by type and name, next to a unified
+// diff of their raw Falco rules.
+type Diff struct {
+	Resource string        `json:"resource"`
+	From     string        `json:"from"`
+	To       string        `json:"to"`
+	Added    []*FalcoItem  `json:"added"`
+	Removed  []*FalcoItem  `json:"removed"`
+	Modified []*ItemChange `json:"modified"`
+	Unified  string        `json:"unified"`
+}
+
+// Compare returns the differences between two versions of a resource.
+// Entries appending to the same rule, macro or list are matched in the order
+// they appear.
+func Compare(from, to *Resource) (*Diff, error) {
+	fromItems, err := itemsOf(from)
+	if err != nil {
+		return nil, err
+	}
+	toItems, err := itemsOf(to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Diff{
+		Resource: from.ID,
+		From:     from.Version,
+		To:       to.Version,
+		Added:    []*FalcoItem{},
+		Removed:  []*FalcoItem{},
+		Modified: []*ItemChange{},
+		Unified:  diff.Unified(from.ID+" "+from.Version, to.ID+" "+to.Version, rawPayload(from), rawPayload(to)),
+	}
+
+	remaining := map[itemKey][]*FalcoItem{}
+	for _, item := range fromItems {
+		key := keyOf(item)
+		remaining[key] = append(remaining[key], item)
+	}
+	for _, item := range toItems {
+		key := keyOf(item)
+		if len(remaining[key]) == 0 {
+			result.Added = append(result.Added, item)
+			continue
+		}
+		previous := remaining[key][0]
+		remaining[key] = remaining[key][1:]
+		if !sameDefinition(previous, item) {
+			result.Modified = append(result.Modified, &ItemChange{Type: item.Type, Name: item.Name, From: previous, To: item})
+		}
+	}
+	for _, item := range fromItems {
+		key := keyOf(item)
+		for _, unmatched := range remaining[key] {
+			if unmatched == item {
+				result.Removed = append(result.Removed, item)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+type itemKey struct {
+	Type   FalcoItemType
+	Name   string
+	Append bool
+}
+
+func keyOf(item *FalcoItem) itemKey {
+	return itemKey{Type: item.Type, Name: item.Name, Append: item.Append}
+}
+
+// sameDefinition compares two items ignoring the line they are declared at,
+// which moves whenever something above them changes.
+func sameDefinition(a, b *FalcoItem) bool {
+	withoutLineA, withoutLineB := *a, *b
+	withoutLineA.Line, withoutLineB.Line = 0, 0
+	return reflect.DeepEqual(withoutLineA, withoutLineB)
+}
+
+// rawPayload concatenates the rules or policies of the resource.
+func rawPayload(resource *Resource) string {
+	var raw strings.Builder
+	write := func(content string) {
+		raw.WriteString(content)
+		if !strings.HasSuffix(content, "\n") {
+			raw.WriteString("\n")
+		}
+	}
+	for _, rule := range resource.Rules {
+		write(rule.Raw)
+	}
+	for _, policy := range resource.Policies {
+		write(policy.Raw)
+	}
+	return raw.String()
+}
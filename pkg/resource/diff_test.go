@@ -0,0 +1,62 @@
+package resource
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCompareFindsAddedRemovedAndModifiedItems(t *testing.T) {
+	from := &Resource{ID: "nginx", Version: "1.0.0", Rules: []*FalcoRuleData{{Raw: `- macro: nginx_process
+  condition: proc.name = nginx
+- list: nginx_ports
+  items: [80, 443]
+- rule: Nginx shell
+  desc: A shell in nginx
+  condition: nginx_process and proc.name = bash
+  output: Shell in nginx
+  priority: WARNING
+`}}}
+	to := &Resource{ID: "nginx", Version: "1.1.0", Rules: []*FalcoRuleData{{Raw: `- macro: nginx_process
+  condition: proc.name in (nginx, nginx-debug)
+- rule: Nginx shell
+  desc: A shell in nginx
+  condition: nginx_process and proc.name = bash
+  output: Shell in nginx
+  priority: WARNING
+- rule: Nginx writes below etc
+  desc: Nginx writing configuration
+  condition: nginx_process and fd.directory = /etc
+  output: Nginx writing below etc
+  priority: ERROR
+`}}}
+
+	result, err := Compare(from, to)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1.0.0", result.From)
+	assert.Equal(t, "1.1.0", result.To)
+	assert.Len(t, result.Added, 1)
+	assert.Equal(t, "Nginx writes below etc", result.Added[0].Name)
+	assert.Len(t, result.Removed, 1)
+	assert.Equal(t, "nginx_ports", result.Removed[0].Name)
+	assert.Len(t, result.Modified, 1)
+	assert.Equal(t, "nginx_process", result.Modified[0].Name)
+	assert.Equal(t, "proc.name = nginx", result.Modified[0].From.Condition)
+	assert.Equal(t, "proc.name in (nginx, nginx-debug)", result.Modified[0].To.Condition)
+	assert.Contains(t, result.Unified, "--- nginx 1.0.0\n+++ nginx 1.1.0\n")
+	assert.Contains(t, result.Unified, "-  condition: proc.name = nginx\n")
+	assert.Contains(t, result.Unified, "+  condition: proc.name in (nginx, nginx-debug)\n")
+}
+
+func TestCompareIgnoresItemsThatOnlyMoved(t *testing.T) {
+	from := &Resource{ID: "nginx", Version: "1.0.0", Rules: []*FalcoRuleData{{Raw: "- list: a\n  items: [x]\n- list: b\n  items: [y]\n"}}}
+	to := &Resource{ID: "nginx", Version: "1.1.0", Rules: []*FalcoRuleData{{Raw: "- list: b\n  items: [y]\n- list: a\n  items: [x]\n"}}}
+
+	result, err := Compare(from, to)
+
+	assert.NoError(t, err)
+	assert.Empty(t, result.Added)
+	assert.Empty(t, result.Removed)
+	assert.Empty(t, result.Modified)
+	assert.NotEmpty(t, result.Unified)
+}
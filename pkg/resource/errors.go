@@ -0,0 +1,77 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrNotFound            = errors.New("not found")
+	ErrEmptyRepository     = errors.New("no resources")
+	ErrInvalidID           = errors.New("invalid resource ID")
+	ErrInvalidVersion      = errors.New("invalid resource version")
+	ErrAlreadyExists       = errors.New("already exists")
+	ErrReadOnly            = errors.New("read-only repository")
+	ErrUnknownFormat       = errors.New("unknown export format")
+	ErrInvalidExportOption = errors.New("invalid export option")
+	// ErrBackend wraps failures of the storage behind a repository, like
+	// unreadable files or database errors.
+	ErrBackend = errors.New("backend failure")
+)
+
+// ValidateID checks that id can be used to look up a resource.
+func ValidateID(id string) error {
+	if strings.TrimSpace(id) == "" || len(id) > 256 || strings.ContainsAny(id, "/\\\x00\n\r\t") || reserved(id) {
+		return fmt.Errorf("%w: %q", ErrInvalidID, id)
+	}
+	return nil
+}
+
+// MovedError is returned when a resource is looked up by one of its former
+// IDs, listed in the aliases of the resource.
+type MovedError struct {
+	ID string
+	To string
+}
+
+func (e *MovedError) Error() string {
+	return fmt.Sprintf("resource %q moved to %q", e.ID, e.To)
+}
+
+func notFound(id string) error {
+	return fmt.Errorf("resource %q %w", id, ErrNotFound)
+}
+
+func versionNotFound(id, version string) error {
+	return fmt.Errorf("resource %q version %q %w", id, version, ErrNotFound)
+}
+
+func alreadyExists(id, version string) error {
+	if version == "" {
+		return fmt.Errorf("resource %q %w", id, ErrAlreadyExists)
+	}
+	return fmt.Errorf("resource %q version %q %w", id, version, ErrAlreadyExists)
+}
+
+// backendError wraps errors coming from the storage with ErrBackend, leaving
+// the errors of this package untouched.
+func backendError(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, known := range []error{ErrNotFound, ErrEmptyRepository, ErrInvalidID, ErrInvalidVersion, ErrAlreadyExists, ErrReadOnly, ErrBackend} {
+		if errors.Is(err, known) {
+			return err
+		}
+	}
+	var validationError *ValidationError
+	if errors.As(err, &validationError) {
+		return err
+	}
+	var movedError *MovedError
+	if errors.As(err, &movedError) {
+		return err
+	}
+	return fmt.Errorf("%w: %v", ErrBackend, err)
+}
@@ -0,0 +1,79 @@
+package resource
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Exporter renders the rules or policies of one or several resources in a
+// format they can be deployed with. The kinds of resources list the formats
+// they support.
+type Exporter interface {
+	// ContentType is the media type of the exported file.
+	ContentType() string
+	Export(resources []*Resource, options ExportOptions) ([]byte, error)
+}
+
+// ExportOptions tune an export, like the name of a generated ConfigMap.
+// Exporters ignore the options they don't know.
+type ExportOptions map[string]string
+
+// Export is a file generated by an exporter.
+type Export struct {
+	ContentType string
+	Content     []byte
+}
+
+var exporters = map[string]Exporter{}
+
+// RegisterExporter makes an exporter available under the name of the file it
+// generates, like rules.yaml.
+func RegisterExporter(format string, exporter Exporter) {
+	exporters[format] = exporter
+}
+
+// ExportFormats returns the formats of every registered exporter, sorted.
+func ExportFormats() []string {
+	formats := make([]string, 0, len(exporters))
+	for format := range exporters {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// ExportContentType returns the media type of the files exported in the
+// format, or an empty string when no exporter is registered for it.
+func ExportContentType(format string) string {
+	if exporter, ok := exporters[format]; ok {
+		return exporter.ContentType()
+	}
+	return ""
+}
+
+func ExportResources(format string, resources []*Resource, options ExportOptions) (*Export, error) {
+	exporter, ok := exporters[format]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+	for _, resource := range resources {
+		if err := exportable(resource, format); err != nil {
+			return nil, err
+		}
+	}
+	content, err := exporter.Export(resources, options)
+	if err != nil {
+		return nil, err
+	}
+	return &Export{ContentType: exporter.ContentType(), Content: content}, nil
+}
+
+func init() {
+	RegisterExporter("custom-rules.yaml", helmExporter{})
+	RegisterExporter("rules.yaml", falcoExporter{})
+	RegisterExporter("configmap.yaml", configMapExporter{})
+	RegisterExporter("kustomization.yaml", kustomizationExporter{})
+	RegisterExporter("rulesfile.yaml", rulesfileExporter{})
+	RegisterExporter("opa-configmap.yaml", opaConfigMapExporter{})
+	RegisterExporter("policies.yaml", policiesExporter{})
+}
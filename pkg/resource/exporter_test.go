@@ -0,0 +1,190 @@
+package resource
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func exportedResources() []*Resource {
+	return []*Resource{
+		resourceWithRules("apache", "- macro: apache_consider_syscalls\n  condition: (evt.num < 0)\n"),
+		resourceWithRules("mongodb", "- macro: mongo_consider_syscalls\n  condition: (evt.num < 0)"),
+	}
+}
+
+func TestExportFormatsListsTheRegisteredExporters(t *testing.T) {
+	assert.Equal(t, []string{"configmap.yaml", "custom-rules.yaml", "kustomization.yaml", "opa-configmap.yaml", "policies.yaml", "rules.yaml", "rulesfile.yaml"}, ExportFormats())
+}
+
+func TestExportContentTypeIsTheOneOfTheExporter(t *testing.T) {
+	assert.Equal(t, helmExporter{}.ContentType(), ExportContentType("custom-rules.yaml"))
+	assert.Equal(t, "", ExportContentType("rules.json"))
+}
+
+func TestExportResourcesFailsWithUnknownFormats(t *testing.T) {
+	_, err := ExportResources("rules.json", exportedResources(), nil)
+
+	assert.True(t, errors.Is(err, ErrUnknownFormat))
+}
+
+func TestExportResourcesForHelmChart(t *testing.T) {
+	export, err := ExportResources("custom-rules.yaml", exportedResources(), nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "application/x-yaml", export.ContentType)
+	assert.Equal(t, `customRules:
+  rules-apache.yaml: |
+    - macro: apache_consider_syscalls
+      condition: (evt.num < 0)
+  rules-mongodb.yaml: |-
+    - macro: mongo_consider_syscalls
+      condition: (evt.num < 0)
+`, string(export.Content))
+}
+
+func TestExportResourcesForHelmChartSeparatesTheRulesOfAResource(t *testing.T) {
+	apache := resourceWithRules("apache", "- macro: apache_consider_syscalls\n  condition: (evt.num < 0)")
+	apache.Rules = append(apache.Rules, &FalcoRuleData{Raw: "- list: apache_binaries\n  items: [httpd]\n"})
+
+	export, err := ExportResources("custom-rules.yaml", []*Resource{apache}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `customRules:
+  rules-apache.yaml: |
+    - macro: apache_consider_syscalls
+      condition: (evt.num < 0)
+    - list: apache_binaries
+      items: [httpd]
+`, string(export.Content))
+}
+
+func TestExportResourcesAsFalcoRulesFile(t *testing.T) {
+	export, err := ExportResources("rules.yaml", exportedResources(), nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `- macro: apache_consider_syscalls
+  condition: (evt.num < 0)
+- macro: mongo_consider_syscalls
+  condition: (evt.num < 0)
+`, string(export.Content))
+}
+
+func TestExportResourcesAsConfigMap(t *testing.T) {
+	export, err := ExportResources("configmap.yaml", exportedResources()[:1], ExportOptions{"namespace": "falco"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: falco-rules-apache
+  namespace: falco
+data:
+  rules-apache.yaml: |
+    - macro: apache_consider_syscalls
+      condition: (evt.num < 0)
+`, string(export.Content))
+}
+
+func TestExportResourcesAsConfigMapRejectsInvalidNames(t *testing.T) {
+	_, err := ExportResources("configmap.yaml", exportedResources(), ExportOptions{"name": "Falco Rules"})
+
+	assert.True(t, errors.Is(err, ErrInvalidExportOption))
+}
+
+func TestExportResourcesAsKustomization(t *testing.T) {
+	export, err := ExportResources("kustomization.yaml", exportedResources(), ExportOptions{"name": "hub-rules"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+generatorOptions:
+  disableNameSuffixHash: true
+configMapGenerator:
+- name: hub-rules
+  literals:
+  - |
+    rules-apache.yaml=- macro: apache_consider_syscalls
+      condition: (evt.num < 0)
+  - |-
+    rules-mongodb.yaml=- macro: mongo_consider_syscalls
+      condition: (evt.num < 0)
+`, string(export.Content))
+}
+
+func TestExportResourcesAsFalcoOperatorRulesfile(t *testing.T) {
+	export, err := ExportResources("rulesfile.yaml", exportedResources(), ExportOptions{"name": "hub-rules", "namespace": "falco"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, `apiVersion: artifact.falcosecurity.dev/v1alpha1
+kind: Rulesfile
+metadata:
+  name: hub-rules
+  namespace: falco
+spec:
+  inlineRules: |
+    - macro: apache_consider_syscalls
+      condition: (evt.num < 0)
+    - macro: mongo_consider_syscalls
+      condition: (evt.num < 0)
+`, string(export.Content))
+}
+
+func networkPolicies() *Resource {
+	return &Resource{
+		ID:   "deny-all",
+		Kind: NETWORK_POLICIES,
+		Policies: []*PolicyData{
+			{Raw: "apiVersion: networking.k8s.io/v1\nkind: NetworkPolicy\nmetadata:\n  name: deny-ingress\n"},
+			{Raw: "apiVersion: networking.k8s.io/v1\nkind: NetworkPolicy\nmetadata:\n  name: deny-egress"},
+		},
+	}
+}
+
+func TestExportResourcesAsPolicies(t *testing.T) {
+	export, err := ExportResources("policies.yaml", []*Resource{networkPolicies()}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: deny-ingress
+---
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: deny-egress
+`, string(export.Content))
+}
+
+func TestExportResourcesAsOPAConfigMap(t *testing.T) {
+	rego := &Resource{
+		ID:       "registries",
+		Kind:     OPA_POLICIES,
+		Policies: []*PolicyData{{Raw: "package kubernetes.admission\n"}},
+	}
+
+	export, err := ExportResources("opa-configmap.yaml", []*Resource{rego}, ExportOptions{"namespace": "opa"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: opa-policies-registries
+  namespace: opa
+  labels:
+    openpolicyagent.org/policy: rego
+data:
+  registries.rego: |
+    package kubernetes.admission
+`, string(export.Content))
+}
+
+func TestExportResourcesRejectsFormatsOfAnotherKind(t *testing.T) {
+	_, rulesErr := ExportResources("rules.yaml", []*Resource{networkPolicies()}, nil)
+	_, policiesErr := ExportResources("policies.yaml", exportedResources(), nil)
+
+	assert.True(t, errors.Is(rulesErr, ErrUnknownFormat))
+	assert.EqualError(t, rulesErr, `unknown export format: "rules.yaml" is not available for the NetworkPolicies resource "deny-all"`)
+	assert.True(t, errors.Is(policiesErr, ErrUnknownFormat))
+}
@@ -0,0 +1,274 @@
+package resource
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"regexp"
+	"strings"
+)
+
+// helmExporter generates a values file for the Falco Helm chart, with one
+// customRules entry per resource.
+type helmExporter struct{}
+
+func (helmExporter) ContentType() string {
+	return "application/x-yaml"
+}
+
+func (helmExporter) Export(resources []*Resource, _ ExportOptions) ([]byte, error) {
+	return yaml.Marshal(map[string]map[string]string{
+		"customRules": rulesFiles(resources),
+	})
+}
+
+// falcoExporter generates a plain Falco rules file, ready to be dropped in
+// /etc/falco/rules.d.
+type falcoExporter struct{}
+
+func (falcoExporter) ContentType() string {
+	return "application/x-yaml"
+}
+
+func (falcoExporter) Export(resources []*Resource, _ ExportOptions) ([]byte, error) {
+	var rules []*FalcoRuleData
+	for _, resource := range resources {
+		rules = append(rules, resource.Rules...)
+	}
+	file := falcoRulesFile(rules)
+	if !strings.HasSuffix(file, "\n") {
+		file += "\n"
+	}
+	return []byte(file), nil
+}
+
+// falcoRulesFile joins rules into a single Falco rules file, starting each
+// one on its own line.
+func falcoRulesFile(rules []*FalcoRuleData) string {
+	var file strings.Builder
+	for _, rule := range rules {
+		if file.Len() > 0 && !strings.HasSuffix(file.String(), "\n") {
+			file.WriteString("\n")
+		}
+		file.WriteString(rule.Raw)
+	}
+	return file.String()
+}
+
+type objectMeta struct {
+	Name      string            `yaml:"name"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+}
+
+type configMap struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   objectMeta        `yaml:"metadata"`
+	Data       map[string]string `yaml:"data"`
+}
+
+// configMapExporter generates a Kubernetes ConfigMap holding the rules, to be
+// mounted in the Falco pods. The name and namespace options set its
+// metadata.
+type configMapExporter struct{}
+
+func (configMapExporter) ContentType() string {
+	return "application/x-yaml"
+}
+
+func (configMapExporter) Export(resources []*Resource, options ExportOptions) ([]byte, error) {
+	metadata, err := objectMetaFrom(resources, options, "falco-rules")
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(configMap{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Metadata:   metadata,
+		Data:       rulesFiles(resources),
+	})
+}
+
+type configMapGenerator struct {
+	Name     string   `yaml:"name"`
+	Literals []string `yaml:"literals"`
+}
+
+type generatorOptions struct {
+	DisableNameSuffixHash bool `yaml:"disableNameSuffixHash"`
+}
+
+type kustomization struct {
+	APIVersion         string               `yaml:"apiVersion"`
+	Kind               string               `yaml:"kind"`
+	Namespace          string               `yaml:"namespace,omitempty"`
+	GeneratorOptions   generatorOptions     `yaml:"generatorOptions"`
+	ConfigMapGenerator []configMapGenerator `yaml:"configMapGenerator"`
+}
+
+// kustomizationExporter generates a kustomization.yaml with a ConfigMap
+// generator holding the rules, so they can be added to an overlay as a single
+// file. It takes the same options as the ConfigMap exporter.
+type kustomizationExporter struct{}
+
+func (kustomizationExporter) ContentType() string {
+	return "application/x-yaml"
+}
+
+func (kustomizationExporter) Export(resources []*Resource, options ExportOptions) ([]byte, error) {
+	metadata, err := objectMetaFrom(resources, options, "falco-rules")
+	if err != nil {
+		return nil, err
+	}
+
+	files := rulesFiles(resources)
+	generator := configMapGenerator{Name: metadata.Name, Literals: []string{}}
+	for _, resource := range resources {
+		name := rulesFileName(resource)
+		if content, ok := files[name]; ok {
+			generator.Literals = append(generator.Literals, name+"="+content)
+			delete(files, name)
+		}
+	}
+
+	return yaml.Marshal(kustomization{
+		APIVersion:         "kustomize.config.k8s.io/v1beta1",
+		Kind:               "Kustomization",
+		Namespace:          metadata.Namespace,
+		GeneratorOptions:   generatorOptions{DisableNameSuffixHash: true},
+		ConfigMapGenerator: []configMapGenerator{generator},
+	})
+}
+
+// rulesFiles returns the rules of every resource keyed by the name of the
+// file Falco reads them from.
+func rulesFiles(resources []*Resource) map[string]string {
+	files := map[string]string{}
+	for _, resource := range resources {
+		if len(resource.Rules) > 0 {
+			files[rulesFileName(resource)] = falcoRulesFile(resource.Rules)
+		}
+	}
+	return files
+}
+
+func rulesFileName(resource *Resource) string {
+	return "rules-" + resource.ID + ".yaml"
+}
+
+type rulesfileSpec struct {
+	InlineRules string `yaml:"inlineRules"`
+}
+
+type rulesfile struct {
+	APIVersion string        `yaml:"apiVersion"`
+	Kind       string        `yaml:"kind"`
+	Metadata   objectMeta    `yaml:"metadata"`
+	Spec       rulesfileSpec `yaml:"spec"`
+}
+
+// rulesfileExporter generates a Rulesfile custom resource for the Falco
+// operator, which loads its inline rules in the Falco instances it manages.
+// It takes the same options as the ConfigMap exporter.
+type rulesfileExporter struct{}
+
+func (rulesfileExporter) ContentType() string {
+	return "application/x-yaml"
+}
+
+func (rulesfileExporter) Export(resources []*Resource, options ExportOptions) ([]byte, error) {
+	metadata, err := objectMetaFrom(resources, options, "falco-rules")
+	if err != nil {
+		return nil, err
+	}
+	content, _ := falcoExporter{}.Export(resources, options)
+	return yaml.Marshal(rulesfile{
+		APIVersion: "artifact.falcosecurity.dev/v1alpha1",
+		Kind:       "Rulesfile",
+		Metadata:   metadata,
+		Spec:       rulesfileSpec{InlineRules: string(content)},
+	})
+}
+
+// opaConfigMapExporter generates a ConfigMap holding the Rego modules of the
+// resources, labelled so the OPA kube-mgmt sidecar loads them. It takes the
+// same options as the ConfigMap exporter.
+type opaConfigMapExporter struct{}
+
+func (opaConfigMapExporter) ContentType() string {
+	return "application/x-yaml"
+}
+
+func (opaConfigMapExporter) Export(resources []*Resource, options ExportOptions) ([]byte, error) {
+	metadata, err := objectMetaFrom(resources, options, "opa-policies")
+	if err != nil {
+		return nil, err
+	}
+	metadata.Labels = map[string]string{"openpolicyagent.org/policy": "rego"}
+
+	modules := map[string]string{}
+	for _, resource := range resources {
+		for position, policy := range resource.Policies {
+			name := resource.ID + ".rego"
+			if len(resource.Policies) > 1 {
+				name = fmt.Sprintf("%s-%d.rego", resource.ID, position+1)
+			}
+			modules[name] = policy.Raw
+		}
+	}
+
+	return yaml.Marshal(configMap{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Metadata:   metadata,
+		Data:       modules,
+	})
+}
+
+// policiesExporter generates a YAML stream with every policy of the
+// resources, ready to be applied with kubectl or loaded by a scanner.
+type policiesExporter struct{}
+
+func (policiesExporter) ContentType() string {
+	return "application/x-yaml"
+}
+
+func (policiesExporter) Export(resources []*Resource, _ ExportOptions) ([]byte, error) {
+	var documents []string
+	for _, resource := range resources {
+		for _, policy := range resource.Policies {
+			document := strings.TrimPrefix(policy.Raw, "---\n")
+			if !strings.HasSuffix(document, "\n") {
+				document += "\n"
+			}
+			documents = append(documents, document)
+		}
+	}
+	return []byte(strings.Join(documents, "---\n")), nil
+}
+
+var (
+	dnsSubdomain = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+	dnsLabel     = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+)
+
+// objectMetaFrom reads the name and namespace options, naming the object
+// after the resource when a single one is exported.
+func objectMetaFrom(resources []*Resource, options ExportOptions, defaultName string) (objectMeta, error) {
+	metadata := objectMeta{Name: options["name"], Namespace: options["namespace"]}
+
+	if metadata.Name == "" {
+		metadata.Name = defaultName
+		if len(resources) == 1 {
+			metadata.Name += "-" + resources[0].ID
+		}
+	}
+	if len(metadata.Name) > 253 || !dnsSubdomain.MatchString(metadata.Name) {
+		return metadata, fmt.Errorf("%w: %q is not a valid Kubernetes name", ErrInvalidExportOption, metadata.Name)
+	}
+	if metadata.Namespace != "" && (len(metadata.Namespace) > 63 || !dnsLabel.MatchString(metadata.Namespace)) {
+		return metadata, fmt.Errorf("%w: %q is not a valid Kubernetes namespace", ErrInvalidExportOption, metadata.Namespace)
+	}
+
+	return metadata, nil
+}
@@ -0,0 +1,76 @@
+package resource
+
+import (
+	"io/ioutil"
+)
+
+// FalcoDefaults are the macros and lists shipped with Falco, which rules in
+// the hub can use without defining them.
+type FalcoDefaults struct {
+	Macros []string
+	Lists  []string
+	// Partial tells the defaults miss some of the macros and lists shipped
+	// with Falco, so a reference they don't resolve may still be valid.
+	Partial bool
+}
+
+// NewFalcoDefaults returns the most used of the macros and lists shipped
+// with Falco, a partial list: rules may use others which are missing from
+// it. FalcoDefaultsFromFile reads every one of them from the rules of the
+// Falco version deployed instead.
+func NewFalcoDefaults() *FalcoDefaults {
+	return &FalcoDefaults{
+		Partial: true,
+		Macros: []string{
+			"always_true", "never_true", "proc_name_exists",
+			"open_write", "open_read", "open_directory", "rename", "mkdir", "remove", "modify",
+			"spawned_process", "create_symlink", "chmod", "inbound", "outbound", "inbound_outbound",
+			"ssh_port", "allowed_ssh_hosts", "container", "container_started", "interactive",
+			"bin_dir", "bin_dir_mkdir", "bin_dir_rename", "etc_dir", "root_dir",
+			"sensitive_files", "server_procs", "user_ssh_directory", "user_known_write_etc_conditions",
+			"consider_all_outbound_conns", "running_shell_command", "parent_linux_image_upgrade_script",
+			"shell_procs", "login_doing_dns_lookup", "system_procs",
+			"package_mgmt_procs", "package_mgmt_ancestor_procs",
+			"kevt", "kevt_started", "kcreate", "kmodify", "kdelete", "pod", "pod_subresource",
+			"deployment", "service", "configmap", "namespace", "serviceaccount",
+			"clusterrole", "clusterrolebinding", "role", "health_endpoint", "live_endpoint",
+			"ready_endpoint", "response_successful", "kactivity",
+		},
+		Lists: []string{
+			"shell_binaries", "shell_mgmt_binaries", "coreutils_binaries", "login_binaries",
+			"passwd_binaries", "shadowutils_binaries", "sysdigcloud_binaries", "docker_binaries",
+			"k8s_binaries", "lxd_binaries", "http_server_binaries", "db_server_binaries",
+			"postgres_mgmt_binaries", "nosql_server_binaries", "gitlab_binaries", "rpm_binaries",
+			"deb_binaries", "package_mgmt_binaries", "ssl_mgmt_binaries", "dhcp_binaries",
+			"dev_creation_binaries", "hids_binaries", "vpn_binaries", "mail_binaries",
+			"mail_config_binaries", "sensitive_file_names", "cron_binaries", "interpreted_binaries",
+			"network_tool_binaries", "bin_dirs", "monitored_directories", "known_shell_spawn_binaries",
+			"user_known_change_thread_namespace_binaries", "trusted_images", "falco_privileged_images",
+			"falco_sensitive_mount_images", "system_users", "k8s_audit_stages", "user_known_k8s_users",
+		},
+	}
+}
+
+// FalcoDefaultsFromFile reads the macros and lists defined in a Falco rules
+// file, like the falco_rules.yaml of the Falco version deployed.
+func FalcoDefaultsFromFile(path string) (*FalcoDefaults, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	items, err := (&FalcoRuleData{Raw: string(content)}).Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	defaults := &FalcoDefaults{}
+	for _, item := range items {
+		switch item.Type {
+		case FALCO_MACRO_ITEM:
+			defaults.Macros = append(defaults.Macros, item.Name)
+		case FALCO_LIST_ITEM:
+			defaults.Lists = append(defaults.Lists, item.Name)
+		}
+	}
+	return defaults, nil
+}
@@ -0,0 +1,188 @@
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/falco"
+	"gopkg.in/yaml.v2"
+	"regexp"
+	"strings"
+)
+
+type FalcoItemType string
+
+const (
+	FALCO_RULE_ITEM  FalcoItemType = "rule"
+	FALCO_MACRO_ITEM FalcoItemType = "macro"
+	FALCO_LIST_ITEM  FalcoItemType = "list"
+)
+
+// FalcoItem is a rule, macro or list defined in a Falco rules file.
+type FalcoItem struct {
+	Type      FalcoItemType `json:"type"`
+	Name      string        `json:"name"`
+	Condition string        `json:"condition,omitempty"`
+	Output    string        `json:"output,omitempty"`
+	Priority  string        `json:"priority,omitempty"`
+	Tags      []string      `json:"tags,omitempty"`
+	Desc      string        `json:"desc,omitempty"`
+	Enabled   *bool         `json:"enabled,omitempty"`
+	Append    bool          `json:"append,omitempty"`
+	Items     []string      `json:"items,omitempty"`
+	Line      int           `json:"line,omitempty"`
+}
+
+// Overrides tells whether the item changes a rule, macro or list defined
+// somewhere else rather than defining it: appending to it, or only enabling
+// or disabling a rule.
+func (i *FalcoItem) Overrides() bool {
+	return i.Append || i.Type == FALCO_RULE_ITEM && i.Condition == "" && i.Enabled != nil
+}
+
+type falcoEntry struct {
+	Rule      string        `yaml:"rule"`
+	Macro     string        `yaml:"macro"`
+	List      string        `yaml:"list"`
+	Condition string        `yaml:"condition"`
+	Output    string        `yaml:"output"`
+	Priority  string        `yaml:"priority"`
+	Tags      []string      `yaml:"tags"`
+	Desc      string        `yaml:"desc"`
+	Enabled   *bool         `yaml:"enabled"`
+	Append    bool          `yaml:"append"`
+	Items     []interface{} `yaml:"items"`
+}
+
+// Parse decodes the raw Falco rules file. Entries which are not rules, macros
+// or lists, like required_engine_version, are skipped.
+func (d *FalcoRuleData) Parse() ([]*FalcoItem, error) {
+	var entries []*falcoEntry
+	if err := yaml.Unmarshal([]byte(d.Raw), &entries); err != nil {
+		return nil, fmt.Errorf("invalid Falco rules: %s", err)
+	}
+
+	var items []*FalcoItem
+	for position, entry := range entries {
+		if entry == nil {
+			continue
+		}
+		item := &FalcoItem{
+			Condition: entry.Condition,
+			Output:    entry.Output,
+			Priority:  entry.Priority,
+			Tags:      entry.Tags,
+			Desc:      entry.Desc,
+			Enabled:   entry.Enabled,
+			Append:    entry.Append,
+		}
+		switch {
+		case entry.Rule != "":
+			item.Type, item.Name = FALCO_RULE_ITEM, entry.Rule
+		case entry.Macro != "":
+			item.Type, item.Name = FALCO_MACRO_ITEM, entry.Macro
+		case entry.List != "":
+			item.Type, item.Name = FALCO_LIST_ITEM, entry.List
+			item.Items = []string{}
+			for _, listItem := range entry.Items {
+				item.Items = append(item.Items, fmt.Sprint(listItem))
+			}
+		default:
+			if entry.Condition != "" || entry.Output != "" || len(entry.Items) > 0 {
+				return nil, fmt.Errorf("invalid Falco rules: entry %d is not a rule, macro or list", position+1)
+			}
+			continue
+		}
+		items = append(items, item)
+	}
+
+	setLines(d.Raw, items)
+	return items, nil
+}
+
+// parsedFalcoRules is the result of parsing raw.
+type parsedFalcoRules struct {
+	raw   string
+	items []*FalcoItem
+	err   error
+}
+
+// parse parses the rules and keeps the result, for the repositories to do it
+// once when they load the rules.
+func (d *FalcoRuleData) parse() {
+	items, err := d.Parse()
+	d.parsed = &parsedFalcoRules{raw: d.Raw, items: items, err: err}
+}
+
+// items returns the items kept by parse, or parses them when they weren't
+// kept or Raw changed since. They must not be modified.
+func (d *FalcoRuleData) items() ([]*FalcoItem, error) {
+	if d.parsed != nil && d.parsed.raw == d.Raw {
+		return d.parsed.items, d.parsed.err
+	}
+	return d.Parse()
+}
+
+var itemStart = regexp.MustCompile(`^\s*(-\s+)?(rule|macro|list)\s*:`)
+
+// setLines finds the line of the raw file where each item is declared. YAML
+// decoding loses positions, but items appear in the file in the same order
+// they are decoded.
+func setLines(raw string, items []*FalcoItem) {
+	lines := strings.Split(raw, "\n")
+	next := 0
+	for _, item := range items {
+		for ; next < len(lines); next++ {
+			match := itemStart.FindStringSubmatch(lines[next])
+			if match != nil && FalcoItemType(match[2]) == item.Type {
+				item.Line = next + 1
+				next++
+				break
+			}
+		}
+	}
+}
+
+// Validate checks the syntax of the condition of every rule and macro,
+// returning a message for each one that Falco would refuse to load.
+func (d *FalcoRuleData) Validate() []string {
+	items, err := d.items()
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var errors []string
+	for _, item := range items {
+		if item.Type == FALCO_LIST_ITEM {
+			continue
+		}
+		if item.Condition == "" {
+			if item.Type == FALCO_MACRO_ITEM || !item.Overrides() {
+				errors = append(errors, fmt.Sprintf("line %d: %s %q has no condition", item.Line, item.Type, item.Name))
+			}
+			continue
+		}
+
+		parse := falco.ParseCondition
+		if item.Append {
+			parse = falco.ParseAppendedCondition
+		}
+		if _, err := parse(item.Condition); err != nil {
+			errors = append(errors, fmt.Sprintf("line %d: %s %q has an invalid condition at %s", item.Line, item.Type, item.Name, err))
+		}
+	}
+
+	return errors
+}
+
+// MarshalJSON adds the parsed rules, macros and lists next to the raw file.
+// They are left out when the file cannot be parsed.
+func (d *FalcoRuleData) MarshalJSON() ([]byte, error) {
+	parsed, _ := d.items()
+	return json.Marshal(struct {
+		Raw    string       `json:"raw"`
+		Parsed []*FalcoItem `json:"parsed"`
+	}{
+		Raw:    d.Raw,
+		Parsed: parsed,
+	})
+}
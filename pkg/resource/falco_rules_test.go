@@ -0,0 +1,131 @@
+package resource
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const falcoRules = `- required_engine_version: 2
+
+- list: shell_binaries
+  items: [bash, sh, 1]
+
+- macro: spawned_process
+  condition: evt.type = execve and evt.dir=<
+
+- rule: Run shell untrusted
+  desc: an attempt to spawn a shell
+  condition: spawned_process and proc.name in (shell_binaries)
+  output: "Shell spawned (user=%user.name)"
+  priority: WARNING
+  tags: [shell, mitre_execution]
+  enabled: false
+
+- macro: spawned_process
+  append: true
+  condition: or evt.type = execveat
+`
+
+func TestParseFalcoRulesReturnsRulesMacrosAndLists(t *testing.T) {
+	data := &FalcoRuleData{Raw: falcoRules}
+
+	items, err := data.Parse()
+
+	disabled := false
+	assert.NoError(t, err)
+	assert.Equal(t, []*FalcoItem{
+		{Type: FALCO_LIST_ITEM, Name: "shell_binaries", Items: []string{"bash", "sh", "1"}, Line: 3},
+		{Type: FALCO_MACRO_ITEM, Name: "spawned_process", Condition: "evt.type = execve and evt.dir=<", Line: 6},
+		{
+			Type:      FALCO_RULE_ITEM,
+			Name:      "Run shell untrusted",
+			Desc:      "an attempt to spawn a shell",
+			Condition: "spawned_process and proc.name in (shell_binaries)",
+			Output:    "Shell spawned (user=%user.name)",
+			Priority:  "WARNING",
+			Tags:      []string{"shell", "mitre_execution"},
+			Enabled:   &disabled,
+			Line:      9,
+		},
+		{Type: FALCO_MACRO_ITEM, Name: "spawned_process", Append: true, Condition: "or evt.type = execveat", Line: 17},
+	}, items)
+}
+
+func TestParseFalcoRulesReturnsErrorWithInvalidYAML(t *testing.T) {
+	data := &FalcoRuleData{Raw: "- rule: [unclosed"}
+
+	_, err := data.Parse()
+
+	assert.Error(t, err)
+}
+
+func TestParseFalcoRulesReturnsErrorWithUnknownEntries(t *testing.T) {
+	data := &FalcoRuleData{Raw: "- condition: evt.num > 0"}
+
+	_, err := data.Parse()
+
+	assert.Error(t, err)
+}
+
+func TestFalcoRuleDataIsSerializedWithParsedItems(t *testing.T) {
+	data := &FalcoRuleData{Raw: "- macro: apache_consider_syscalls\n  condition: (evt.num < 0)"}
+
+	serialized, _ := json.Marshal(data)
+
+	assert.JSONEq(t, `{
+		"raw": "- macro: apache_consider_syscalls\n  condition: (evt.num < 0)",
+		"parsed": [{"type": "macro", "name": "apache_consider_syscalls", "condition": "(evt.num < 0)", "line": 1}]
+	}`, string(serialized))
+}
+
+func TestFalcoRuleDataIsSerializedFromTheItemsParsedWhenLoaded(t *testing.T) {
+	data := &FalcoRuleData{Raw: "- macro: apache_consider_syscalls\n  condition: (evt.num < 0)"}
+	loaded(&Resource{Rules: []*FalcoRuleData{data}})
+	data.parsed.items[0].Desc = "parsed when loaded"
+
+	serialized, _ := json.Marshal(data)
+	assert.Contains(t, string(serialized), `"desc":"parsed when loaded"`)
+
+	data.Raw = "- macro: mongo_consider_syscalls\n  condition: (evt.num < 0)"
+	serialized, _ = json.Marshal(data)
+	assert.Contains(t, string(serialized), `"name":"mongo_consider_syscalls"`)
+}
+
+func TestValidateFalcoRulesAcceptsValidConditions(t *testing.T) {
+	data := &FalcoRuleData{Raw: falcoRules}
+
+	assert.Empty(t, data.Validate())
+}
+
+func TestValidateFalcoRulesReportsTheLineAndColumnOfSyntaxErrors(t *testing.T) {
+	data := &FalcoRuleData{Raw: `- macro: spawned_process
+  condition: evt.type = execve and evt.dir=<
+
+- rule: Run shell untrusted
+  condition: spawned_process and (proc.name in (bash, sh)
+  output: "Shell spawned"
+`}
+
+	assert.Equal(t, []string{
+		`line 4: rule "Run shell untrusted" has an invalid condition at 1:45: unexpected end of condition, expected ")" to close the parenthesis at 1:21`,
+	}, data.Validate())
+}
+
+func TestValidateFalcoRulesAcceptsRulesOnlyDisablingARule(t *testing.T) {
+	data := &FalcoRuleData{Raw: "- rule: Run shell untrusted\n  enabled: false\n"}
+
+	assert.Empty(t, data.Validate())
+}
+
+func TestValidateFalcoRulesRequiresAConditionForNewRules(t *testing.T) {
+	data := &FalcoRuleData{Raw: "- rule: Run shell untrusted\n  output: shell\n"}
+
+	assert.Equal(t, []string{`line 1: rule "Run shell untrusted" has no condition`}, data.Validate())
+}
+
+func TestValidateFalcoRulesRequiresAConditionForMacros(t *testing.T) {
+	data := &FalcoRuleData{Raw: "- macro: spawned_process\n"}
+
+	assert.Equal(t, []string{`line 1: macro "spawned_process" has no condition`}, data.Validate())
+}
@@ -2,18 +2,28 @@ package resource
 
 import (
 	"fmt"
-	"gopkg.in/yaml.v2"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/diagnostic"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/hubfile"
+	"io/ioutil"
+	"log"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 )
 
 type fileRepository struct {
 	path                     string
-	resourcesCache           []*Resource
+	resourcesCache           cache
 	resourcesCacheFilledOnce sync.Once
-	resourcesCacheError      error
+	fingerprintMutex         sync.Mutex
+	fingerprint              string
+	// attempted is the fingerprint of the tree last read, even when it
+	// failed to parse, so a broken tree is only read again once it changes.
+	attempted  string
+	modified   time.Time
+	writeMutex sync.Mutex
 }
 
 func FromPath(path string) (*fileRepository, error) {
@@ -24,60 +34,360 @@ func FromPath(path string) (*fileRepository, error) {
 	return &fileRepository{path: path}, nil
 }
 
+// UseTaxonomy replaces the keywords of the resources with their canonical
+// keyword when they are loaded. It must be called before reading resources.
+func (f *fileRepository) UseTaxonomy(taxonomy *Taxonomy) {
+	f.resourcesCache.setTaxonomy(taxonomy)
+}
+
 func (f *fileRepository) FindAll() (resources []*Resource, err error) {
 	f.resourcesCacheFilledOnce.Do(f.fillResourcesCache)
-	return f.resourcesCache, f.resourcesCacheError
+	return f.resourcesCache.findAll()
 }
 
 func (f *fileRepository) FindById(id string) (res *Resource, err error) {
 	f.resourcesCacheFilledOnce.Do(f.fillResourcesCache)
-	idToFind := strings.ToLower(id)
+	return f.resourcesCache.findById(id)
+}
+
+func (f *fileRepository) FindVersions(id string) ([]*Resource, error) {
+	f.resourcesCacheFilledOnce.Do(f.fillResourcesCache)
+	return f.resourcesCache.findVersions(id)
+}
+
+func (f *fileRepository) FindVersion(id, version string) (*Resource, error) {
+	f.resourcesCacheFilledOnce.Do(f.fillResourcesCache)
+	return f.resourcesCache.findVersion(id, version)
+}
+
+func (f *fileRepository) Diagnostics() []*diagnostic.Diagnostic {
+	f.resourcesCacheFilledOnce.Do(f.fillResourcesCache)
+	return f.resourcesCache.getDiagnostics()
+}
+
+func (f *fileRepository) LoadStats() LoadStats {
+	f.resourcesCacheFilledOnce.Do(f.fillResourcesCache)
+	return f.resourcesCache.getLoadStats()
+}
+
+func (f *fileRepository) Index() (*Index, error) {
+	f.resourcesCacheFilledOnce.Do(f.fillResourcesCache)
+	return f.resourcesCache.getIndex()
+}
+
+// Save writes the resource to a new file in the root of the tree, named after
+// its ID and version.
+func (f *fileRepository) Save(resource *Resource) error {
+	f.writeMutex.Lock()
+	defer f.writeMutex.Unlock()
 
-	if f.resourcesCacheError != nil {
-		return nil, f.resourcesCacheError
+	if _, err := f.FindVersion(resource.ID, resource.Version); err == nil {
+		return alreadyExists(resource.ID, resource.Version)
+	}
+	if err := ValidateID(resource.ID); err != nil {
+		return err
 	}
 
-	if len(f.resourcesCache) == 0 {
-		return nil, fmt.Errorf("no resources")
+	name := resource.ID
+	if resource.Version != "" {
+		name += "-" + resource.Version
+	}
+	path := filepath.Join(f.path, name+".yaml")
+	if _, err := os.Stat(path); err == nil {
+		return alreadyExists(resource.ID, resource.Version)
 	}
 
-	for _, resource := range f.resourcesCache {
-		if resource.ID == idToFind {
-			res = resource
-			return
+	return f.writeAndReload(path, []*Resource{resource})
+}
+
+// Update rewrites the file the resource version was read from, keeping the
+// other resources defined in the same file.
+func (f *fileRepository) Update(resource *Resource) error {
+	f.writeMutex.Lock()
+	defer f.writeMutex.Unlock()
+
+	sameVersion := func(stored *Resource) bool {
+		return stored.ID == strings.ToLower(resource.ID) && stored.Version == resource.Version
+	}
+	paths, err := f.filesDefining(sameVersion)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return versionNotFound(resource.ID, resource.Version)
+	}
+
+	stored, err := ReadFile(paths[0])
+	if err != nil {
+		return backendError(err)
+	}
+	for position, candidate := range stored {
+		if sameVersion(candidate) {
+			stored[position] = resource
 		}
 	}
+	return f.writeAndReload(paths[0], stored)
+}
 
-	err = fmt.Errorf("not found")
+// Delete removes every version of the resource from the files defining them,
+// and the files left without resources.
+func (f *fileRepository) Delete(id string) error {
+	f.writeMutex.Lock()
+	defer f.writeMutex.Unlock()
+
+	sameID := func(stored *Resource) bool { return stored.ID == strings.ToLower(id) }
+	paths, err := f.filesDefining(sameID)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return notFound(id)
+	}
+	for _, path := range paths {
+		stored, err := ReadFile(path)
+		if err != nil {
+			return backendError(err)
+		}
+		var kept []*Resource
+		for _, resource := range stored {
+			if !sameID(resource) {
+				kept = append(kept, resource)
+			}
+		}
+		if err := writeOrRemove(path, kept); err != nil {
+			return backendError(err)
+		}
+	}
+
+	return backendError(f.Reload())
+}
+
+func (f *fileRepository) writeAndReload(path string, resources []*Resource) error {
+	if err := writeOrRemove(path, resources); err != nil {
+		return backendError(err)
+	}
+	return backendError(f.Reload())
+}
+
+// writeOrRemove writes the resources to the file at path, in the format of
+// the file, removing it when no resources are left.
+func writeOrRemove(path string, resources []*Resource) error {
+	if len(resources) == 0 {
+		return os.Remove(path)
+	}
+
+	documents := make([]interface{}, len(resources))
+	for position, resource := range resources {
+		documents[position] = resource
+	}
+	content, err := hubfile.Encode(path, documents)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content, 0644)
+}
+
+// filesDefining returns the files defining resources matching the filter.
+func (f *fileRepository) filesDefining(filter func(*Resource) bool) (found []string, err error) {
+	err = hubfile.Walk(f.path, func(path string, info os.FileInfo) error {
+		resources, err := ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		for _, resource := range resources {
+			if filter(resource) {
+				found = append(found, path)
+				return nil
+			}
+		}
+		return nil
+	})
+	err = backendError(err)
 	return
 }
 
-func resourceFromFile(path string) (resource Resource, err error) {
-	file, err := os.OpenFile(path, os.O_RDONLY, 0644)
-	defer file.Close()
+// Reload walks the tree again and swaps in the freshly parsed resources. When
+// the tree fails to parse, the resources loaded previously are kept.
+func (f *fileRepository) Reload() (err error) {
+	f.resourcesCacheFilledOnce.Do(f.fillResourcesCache)
+
+	started := time.Now()
+	defer func() { f.resourcesCache.recordLoad(started, err) }()
+	fingerprint, modified, err := fingerprintTree(f.path)
 	if err != nil {
-		return
+		return err
+	}
+	f.fingerprintMutex.Lock()
+	f.attempted = fingerprint
+	f.fingerprintMutex.Unlock()
+	resources, diagnostics, err := resourcesFromTree(f.path)
+	if err != nil {
+		return err
 	}
 
-	err = yaml.NewDecoder(file).Decode(&resource)
+	f.resourcesCache.swap(resources, diagnostics, nil)
+	f.setFingerprint(fingerprint, modified)
+	return nil
+}
+
+// Watch checks the tree for modified, added or removed files every interval
+// and reloads the repository when something changed. Calling the returned
+// function stops watching.
+func (f *fileRepository) Watch(interval time.Duration) (stop func()) {
+	return every(interval, f.reloadIfChanged)
+}
+
+func (f *fileRepository) reloadIfChanged() {
+	f.resourcesCacheFilledOnce.Do(f.fillResourcesCache)
+
+	fingerprint, _, err := fingerprintTree(f.path)
 	if err != nil {
+		log.Printf("unable to check resources in %s for changes: %s", f.path, err)
 		return
 	}
 
+	f.fingerprintMutex.Lock()
+	changed := fingerprint != f.attempted
+	f.fingerprintMutex.Unlock()
+	if !changed {
+		return
+	}
+
+	if err := f.Reload(); err != nil {
+		log.Printf("keeping previous resources, unable to reload %s: %s", f.path, err)
+	}
+}
+
+// setFingerprint records the state of the tree once loaded. Removing files
+// doesn't make the tree any newer, so the time the change is noticed is
+// recorded instead.
+func (f *fileRepository) setFingerprint(fingerprint string, modified time.Time) {
+	f.fingerprintMutex.Lock()
+	defer f.fingerprintMutex.Unlock()
+	if f.fingerprint != "" && fingerprint != f.fingerprint && !modified.After(f.modified) {
+		modified = time.Now()
+	}
+	f.fingerprint = fingerprint
+	f.attempted = fingerprint
+	f.modified = modified
+}
+
+// LastModified returns when the files the resources are read from last changed.
+func (f *fileRepository) LastModified() time.Time {
+	f.resourcesCacheFilledOnce.Do(f.fillResourcesCache)
+	f.fingerprintMutex.Lock()
+	defer f.fingerprintMutex.Unlock()
+	return f.modified
+}
+
+// ReadFile decodes every resource of a file the way the repositories returned by
+// FromPath do, without validating them.
+func ReadFile(path string) ([]*Resource, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return resourcesFromContent(path, content)
+}
+
+// resourcesFromContent decodes every resource in the content of the file at
+// path, skipping empty documents.
+func resourcesFromContent(path string, content []byte) (resources []*Resource, err error) {
+	err = hubfile.Decode(path, content, func(decode func(interface{}) error) error {
+		var resource *Resource
+		if err := decode(&resource); err != nil {
+			return err
+		}
+		if resource != nil {
+			resources = append(resources, resource)
+		}
+		return nil
+	})
 	return
 }
 
-func (f *fileRepository) fillResourcesCache() {
-	var resources []*Resource
-	f.resourcesCacheError = filepath.Walk(f.path, func(path string, info os.FileInfo, err error) error {
-		if filepath.Ext(path) == ".yaml" {
-			resource, err := resourceFromFile(path)
-			if err != nil {
-				return err
+func resourcesFromTree(root string) (resources []*Resource, diagnostics []*diagnostic.Diagnostic, err error) {
+	var sources []string
+	err = hubfile.Walk(root, func(path string, info os.FileInfo) error {
+		found, err := ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %s", path, err)
+		}
+		for _, resource := range found {
+			if problem := resource.kindError(); problem != "" {
+				diagnostics = append(diagnostics, &diagnostic.Diagnostic{Code: diagnostic.UNKNOWN_KIND, Source: path, Message: problem})
+				continue
 			}
-			resources = append(resources, &resource)
+			if problems := resource.payloadErrors(); len(problems) > 0 {
+				diagnostics = append(diagnostics, payloadDiagnostic(resource, path, problems))
+				continue
+			}
+			resources = append(resources, resource)
+			sources = append(sources, path)
 		}
 		return nil
 	})
-	f.resourcesCache = resources
+	if err != nil {
+		return
+	}
+
+	resources, idDiagnostics := checkIDs(resources, sources)
+	diagnostics = append(diagnostics, idDiagnostics...)
+	return
+}
+
+// fingerprintTree summarizes the files resources are read from, and the
+// ignore file, so changes to any of them can be detected. It also returns the
+// time the most recent of them was modified.
+func fingerprintTree(root string) (string, time.Time, error) {
+	var fingerprint strings.Builder
+	var modified time.Time
+	record := func(name string, info os.FileInfo) {
+		fmt.Fprintf(&fingerprint, "%s:%d:%d\n", name, info.Size(), info.ModTime().UnixNano())
+		if info.ModTime().After(modified) {
+			modified = info.ModTime()
+		}
+	}
+	if info, err := os.Stat(filepath.Join(root, hubfile.IgnoreFile)); err == nil {
+		record(hubfile.IgnoreFile, info)
+	}
+	err := hubfile.Walk(root, func(path string, info os.FileInfo) error {
+		record(path, info)
+		return nil
+	})
+	return fingerprint.String(), modified, err
+}
+
+func (f *fileRepository) fillResourcesCache() {
+	started := time.Now()
+	fingerprint, modified, _ := fingerprintTree(f.path)
+	resources, diagnostics, err := resourcesFromTree(f.path)
+	f.resourcesCache.swap(resources, diagnostics, err)
+	f.resourcesCache.recordLoad(started, err)
+	f.setFingerprint(fingerprint, modified)
+}
+
+// every calls fn each interval in the background until the returned function
+// is called.
+func every(interval time.Duration, fn func()) (stop func()) {
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				fn()
+			}
+		}
+	}()
+
+	var stopOnce sync.Once
+	return func() {
+		stopOnce.Do(func() { close(done) })
+	}
 }
@@ -1,8 +1,14 @@
 package resource
 
 import (
+	"errors"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/diagnostic"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestFileRepositoryWalksADirectoryAndExtractResources(t *testing.T) {
@@ -11,7 +17,7 @@ func TestFileRepositoryWalksADirectoryAndExtractResources(t *testing.T) {
 
 	resources, _ := fileRepository.FindAll()
 
-	assert.Equal(t, buildResourcesFromFixtures(), resources)
+	assert.Equal(t, loaded(buildResourcesFromFixtures()...), resources)
 }
 
 func buildResourcesFromFixtures() []*Resource {
@@ -70,6 +76,17 @@ func buildResourcesFromFixtures() []*Resource {
 	return resources
 }
 
+// loaded parses the rules of the resources, like the repositories reading
+// resources in bulk do when loading them.
+func loaded(resources ...*Resource) []*Resource {
+	for _, resource := range resources {
+		for _, rule := range resource.Rules {
+			rule.parse()
+		}
+	}
+	return resources
+}
+
 func TestFileRepositoryReturnsAnErrorIfPathDoesNotExist(t *testing.T) {
 	nonExistentPath := "../foo"
 
@@ -77,3 +94,396 @@ func TestFileRepositoryReturnsAnErrorIfPathDoesNotExist(t *testing.T) {
 
 	assert.Error(t, err)
 }
+
+func TestFileRepositoryReloadsChangedResources(t *testing.T) {
+	path := copyFixturesToTempDir(t)
+	defer os.RemoveAll(path)
+	fileRepository, _ := FromPath(path)
+	fileRepository.FindAll()
+
+	writeFile(t, filepath.Join(path, "nginx.yaml"), "kind: FalcoRules\nname: Nginx\nvendor: Nginx\n")
+	err := fileRepository.Reload()
+
+	resources, _ := fileRepository.FindAll()
+	assert.NoError(t, err)
+	assert.Len(t, resources, 3)
+}
+
+func TestFileRepositoryKeepsPreviousResourcesWhenReloadFails(t *testing.T) {
+	path := copyFixturesToTempDir(t)
+	defer os.RemoveAll(path)
+	fileRepository, _ := FromPath(path)
+	fileRepository.FindAll()
+
+	writeFile(t, filepath.Join(path, "broken.yaml"), "name: [")
+	err := fileRepository.Reload()
+
+	resources, findErr := fileRepository.FindAll()
+	assert.Error(t, err)
+	assert.NoError(t, findErr)
+	assert.Equal(t, loaded(buildResourcesFromFixtures()...), resources)
+}
+
+func TestFileRepositorySkipsResourcesWithInvalidConditions(t *testing.T) {
+	path := copyFixturesToTempDir(t)
+	defer os.RemoveAll(path)
+	writeFile(t, filepath.Join(path, "broken.yaml"), `kind: FalcoRules
+name: Broken
+rules:
+  - raw: |
+      - rule: Broken
+        condition: proc.name in (bash
+`)
+	fileRepository, _ := FromPath(path)
+
+	resources, err := fileRepository.FindAll()
+
+	assert.NoError(t, err)
+	assert.Equal(t, loaded(buildResourcesFromFixtures()...), resources)
+	assert.Equal(t, []*diagnostic.Diagnostic{
+		{
+			Code:    diagnostic.INVALID_PAYLOAD,
+			Source:  filepath.Join(path, "broken.yaml"),
+			Message: `the resource "broken" was skipped: line 1: rule "Broken" has an invalid condition at 1:19: unexpected end of condition, expected "," or ")" in list`,
+		},
+	}, fileRepository.Diagnostics())
+}
+
+func TestFileRepositoryKeepsTheFirstDefinitionOfDuplicatedResources(t *testing.T) {
+	path := copyFixturesToTempDir(t)
+	defer os.RemoveAll(path)
+	writeFile(t, filepath.Join(path, "copy.yaml"), "kind: FalcoRules\nid: apache\nname: Apache copy\nvendor: Apache\n")
+	writeFile(t, filepath.Join(path, "invalid.yaml"), "kind: FalcoRules\nid: Invalid ID\nname: Invalid\nvendor: Apache\n")
+	fileRepository, _ := FromPath(path)
+
+	resources, err := fileRepository.FindAll()
+
+	assert.NoError(t, err)
+	assert.Equal(t, loaded(buildResourcesFromFixtures()...), resources)
+	assert.Equal(t, []*diagnostic.Diagnostic{
+		{
+			Code:    diagnostic.DUPLICATE_ID,
+			Source:  filepath.Join(path, "copy.yaml"),
+			Message: `resource "apache" is already defined in ` + filepath.Join(path, "apache.yaml") + ", ignoring this definition",
+		},
+		{
+			Code:    diagnostic.INVALID_ID,
+			Source:  filepath.Join(path, "invalid.yaml"),
+			Message: `the resource ID "Invalid ID" must only have lowercase letters, digits, ".", "-" and "_"`,
+		},
+	}, fileRepository.Diagnostics())
+}
+
+func TestFileRepositoryLoadsResourcesWithEmptyRules(t *testing.T) {
+	path, _ := ioutil.TempDir("", "resources")
+	defer os.RemoveAll(path)
+	writeFile(t, filepath.Join(path, "nginx.yaml"), "kind: FalcoRules\nname: Nginx\nvendor: Nginx\nrules:\n  -\n")
+	fileRepository, _ := FromPath(path)
+
+	resources, err := fileRepository.FindAll()
+
+	assert.NoError(t, err)
+	assert.Len(t, resources, 1)
+	assert.Empty(t, resources[0].Rules)
+}
+
+func TestFileRepositoryIgnoresResourcesOfUnknownKinds(t *testing.T) {
+	path := copyFixturesToTempDir(t)
+	defer os.RemoveAll(path)
+	writeFile(t, filepath.Join(path, "dashboard.yaml"), "kind: GrafanaDashboard\nname: Nginx\nvendor: Nginx\n")
+	fileRepository, _ := FromPath(path)
+
+	resources, err := fileRepository.FindAll()
+
+	assert.NoError(t, err)
+	assert.Equal(t, loaded(buildResourcesFromFixtures()...), resources)
+	assert.Equal(t, []*diagnostic.Diagnostic{
+		{
+			Code:    diagnostic.UNKNOWN_KIND,
+			Source:  filepath.Join(path, "dashboard.yaml"),
+			Message: `the resource kind "GrafanaDashboard" is not supported, use one of FalcoRules, NetworkPolicies, OPAPolicies, PodSecurityPolicies, ScanningPolicies`,
+		},
+	}, fileRepository.Diagnostics())
+}
+
+func TestFileRepositoryRedirectsAliasesToTheCurrentID(t *testing.T) {
+	path := copyFixturesToTempDir(t)
+	defer os.RemoveAll(path)
+	writeFile(t, filepath.Join(path, "nginx.yaml"), "kind: FalcoRules\nid: nginx\nname: Nginx Ingress\nvendor: Nginx\naliases: [nginx-ingress]\n")
+	fileRepository, _ := FromPath(path)
+
+	resource, err := fileRepository.FindById("nginx")
+	_, aliasErr := fileRepository.FindById("nginx-ingress")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Nginx Ingress", resource.Name)
+	assert.Equal(t, &MovedError{ID: "nginx-ingress", To: "nginx"}, aliasErr)
+}
+
+func TestFileRepositoryWatchReloadsWhenTreeChanges(t *testing.T) {
+	path := copyFixturesToTempDir(t)
+	defer os.RemoveAll(path)
+	fileRepository, _ := FromPath(path)
+	fileRepository.FindAll()
+
+	stop := fileRepository.Watch(10 * time.Millisecond)
+	defer stop()
+	os.Remove(filepath.Join(path, "mongo.yaml"))
+
+	for i := 0; i < 100; i++ {
+		if resources, _ := fileRepository.FindAll(); len(resources) == 1 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("the repository was not reloaded after removing a resource")
+}
+
+func copyFixturesToTempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "resources")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob("../../test/fixtures/resources/*.yaml")
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, filepath.Join(dir, filepath.Base(file)), string(content))
+	}
+	return dir
+}
+
+func writeFile(t *testing.T, path, content string) {
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileRepositorySavesResourceToANewFile(t *testing.T) {
+	path := copyFixturesToTempDir(t)
+	defer os.RemoveAll(path)
+	fileRepository, _ := FromPath(path)
+	resource := buildResourcesFromFixtures()[0]
+	resource.Name = "Nginx"
+	resource.ID = "nginx"
+
+	err := fileRepository.Save(resource)
+
+	saved, _ := fileRepository.FindById("nginx")
+	assert.NoError(t, err)
+	assert.Equal(t, loaded(resource)[0], saved)
+	assert.FileExists(t, filepath.Join(path, "nginx.yaml"))
+}
+
+func TestFileRepositorySaveFailsIfResourceExists(t *testing.T) {
+	path := copyFixturesToTempDir(t)
+	defer os.RemoveAll(path)
+	fileRepository, _ := FromPath(path)
+
+	err := fileRepository.Save(buildResourcesFromFixtures()[0])
+
+	assert.Error(t, err)
+}
+
+func TestFileRepositoryUpdatesTheFileAResourceWasReadFrom(t *testing.T) {
+	path := copyFixturesToTempDir(t)
+	defer os.RemoveAll(path)
+	fileRepository, _ := FromPath(path)
+	resource := buildResourcesFromFixtures()[1]
+	resource.Keywords = []string{"database", "nosql"}
+
+	err := fileRepository.Update(resource)
+
+	reloaded, _ := ReadFile(filepath.Join(path, "mongo.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, []*Resource{resource}, reloaded)
+}
+
+func TestFileRepositoryDeletesTheFileAResourceWasReadFrom(t *testing.T) {
+	path := copyFixturesToTempDir(t)
+	defer os.RemoveAll(path)
+	fileRepository, _ := FromPath(path)
+
+	err := fileRepository.Delete("mongodb")
+
+	resources, _ := fileRepository.FindAll()
+	assert.NoError(t, err)
+	assert.Equal(t, loaded(buildResourcesFromFixtures()[:1]...), resources)
+	_, statErr := os.Stat(filepath.Join(path, "mongo.yaml"))
+	assert.True(t, os.IsNotExist(statErr))
+}
+
+func TestFileRepositoryDeleteFailsIfResourceDoesNotExist(t *testing.T) {
+	path := copyFixturesToTempDir(t)
+	defer os.RemoveAll(path)
+	fileRepository, _ := FromPath(path)
+
+	assert.Error(t, fileRepository.Delete("nginx"))
+}
+
+func TestFileRepositorySavesEachVersionToItsOwnFile(t *testing.T) {
+	path := copyFixturesToTempDir(t)
+	defer os.RemoveAll(path)
+	fileRepository, _ := FromPath(path)
+	resource := buildResourcesFromFixtures()[0]
+	resource.Version = "1.1.0"
+
+	err := fileRepository.Save(resource)
+
+	latest, _ := fileRepository.FindById("apache")
+	versions, _ := fileRepository.FindVersions("apache")
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(path, "apache-1.1.0.yaml"))
+	assert.Equal(t, loaded(resource)[0], latest)
+	assert.Equal(t, loaded(resource, buildResourcesFromFixtures()[0]), versions)
+}
+
+func TestFileRepositoryUpdatesTheFileOfTheGivenVersion(t *testing.T) {
+	path := copyFixturesToTempDir(t)
+	defer os.RemoveAll(path)
+	fileRepository, _ := FromPath(path)
+	newVersion := buildResourcesFromFixtures()[0]
+	newVersion.Version = "1.1.0"
+	fileRepository.Save(newVersion)
+	unversioned := buildResourcesFromFixtures()[0]
+	unversioned.Keywords = []string{"web", "httpd"}
+
+	err := fileRepository.Update(unversioned)
+
+	reloaded, _ := ReadFile(filepath.Join(path, "apache.yaml"))
+	untouched, _ := fileRepository.FindVersion("apache", "1.1.0")
+	assert.NoError(t, err)
+	assert.Equal(t, []*Resource{unversioned}, reloaded)
+	assert.Equal(t, loaded(newVersion)[0], untouched)
+}
+
+func TestFileRepositoryDeletesEveryVersion(t *testing.T) {
+	path := copyFixturesToTempDir(t)
+	defer os.RemoveAll(path)
+	fileRepository, _ := FromPath(path)
+	newVersion := buildResourcesFromFixtures()[0]
+	newVersion.Version = "1.1.0"
+	fileRepository.Save(newVersion)
+
+	err := fileRepository.Delete("apache")
+
+	_, findErr := fileRepository.FindVersions("apache")
+	assert.NoError(t, err)
+	assert.True(t, errors.Is(findErr, ErrNotFound))
+	_, statErr := os.Stat(filepath.Join(path, "apache-1.1.0.yaml"))
+	assert.True(t, os.IsNotExist(statErr))
+}
+
+func TestFileRepositoryReadsEveryDocumentOfYMLAndJSONFiles(t *testing.T) {
+	path := copyFixturesToTempDir(t)
+	defer os.RemoveAll(path)
+	writeFile(t, filepath.Join(path, "web.yml"), "kind: FalcoRules\nname: Nginx\nvendor: Nginx\n---\nkind: FalcoRules\nname: Traefik\nvendor: Traefik\n")
+	writeFile(t, filepath.Join(path, "caddy.json"), `[{"kind": "FalcoRules", "name": "Caddy", "vendor": "Caddy"}]`)
+	fileRepository, _ := FromPath(path)
+
+	resources, err := fileRepository.FindAll()
+
+	assert.NoError(t, err)
+	assert.Len(t, resources, 5)
+	for _, id := range []string{"nginx", "traefik", "caddy"} {
+		_, err := fileRepository.FindById(id)
+		assert.NoError(t, err, id)
+	}
+}
+
+func TestFileRepositorySkipsFilesListedInTheIgnoreFile(t *testing.T) {
+	path := copyFixturesToTempDir(t)
+	defer os.RemoveAll(path)
+	os.Mkdir(filepath.Join(path, "drafts"), 0755)
+	writeFile(t, filepath.Join(path, "drafts", "nginx.yaml"), "kind: FalcoRules\nname: Nginx\nvendor: Nginx\n")
+	writeFile(t, filepath.Join(path, "mongo.yaml"), "name: [")
+	writeFile(t, filepath.Join(path, ".hubignore"), "drafts/\nmongo.yaml\n")
+	fileRepository, _ := FromPath(path)
+
+	resources, err := fileRepository.FindAll()
+
+	assert.NoError(t, err)
+	assert.Equal(t, loaded(buildResourcesFromFixtures()[:1]...), resources)
+}
+
+func TestFileRepositoryKeepsTheOtherDocumentsOfAFileOnWrites(t *testing.T) {
+	path := copyFixturesToTempDir(t)
+	defer os.RemoveAll(path)
+	writeFile(t, filepath.Join(path, "web.json"), `{"kind": "FalcoRules", "name": "Nginx", "vendor": "Nginx"}
+{"kind": "FalcoRules", "name": "Traefik", "vendor": "Traefik"}`)
+	fileRepository, _ := FromPath(path)
+	nginx, _ := fileRepository.FindById("nginx")
+
+	updated := *nginx
+	updated.Vendor = "F5"
+	assert.NoError(t, fileRepository.Update(&updated))
+	assert.NoError(t, fileRepository.Delete("traefik"))
+
+	stored, err := ReadFile(filepath.Join(path, "web.json"))
+	assert.NoError(t, err)
+	assert.Len(t, stored, 1)
+	assert.Equal(t, "F5", stored[0].Vendor)
+
+	assert.NoError(t, fileRepository.Delete("nginx"))
+	_, err = os.Stat(filepath.Join(path, "web.json"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestFileRepositoryLastModifiedIsTheNewestFile(t *testing.T) {
+	path := copyFixturesToTempDir(t)
+	defer os.RemoveAll(path)
+	modified := time.Date(2019, 11, 5, 10, 0, 0, 0, time.UTC)
+	os.Chtimes(filepath.Join(path, "apache.yaml"), modified, modified)
+	os.Chtimes(filepath.Join(path, "mongo.yaml"), modified.Add(-time.Hour), modified.Add(-time.Hour))
+	fileRepository, _ := FromPath(path)
+
+	assert.True(t, modified.Equal(fileRepository.LastModified()))
+}
+
+func TestFileRepositoryLastModifiedMovesWhenFilesAreRemoved(t *testing.T) {
+	path := copyFixturesToTempDir(t)
+	defer os.RemoveAll(path)
+	modified := time.Date(2019, 11, 5, 10, 0, 0, 0, time.UTC)
+	os.Chtimes(filepath.Join(path, "apache.yaml"), modified, modified)
+	os.Chtimes(filepath.Join(path, "mongo.yaml"), modified, modified)
+	fileRepository, _ := FromPath(path)
+	fileRepository.FindAll()
+
+	os.Remove(filepath.Join(path, "mongo.yaml"))
+	fileRepository.Reload()
+
+	assert.True(t, fileRepository.LastModified().After(modified))
+}
+
+func TestFileRepositoryCountsLoadsAndFailures(t *testing.T) {
+	path := copyFixturesToTempDir(t)
+	defer os.RemoveAll(path)
+	fileRepository, _ := FromPath(path)
+	fileRepository.FindAll()
+
+	writeFile(t, filepath.Join(path, "broken.yaml"), "name: [")
+	fileRepository.Reload()
+
+	stats := fileRepository.LoadStats()
+	assert.Equal(t, 2, stats.Loads)
+	assert.Equal(t, 1, stats.Failures)
+	assert.True(t, stats.LastDuration > 0)
+}
+
+func TestFileRepositoryOnlyReadsABrokenTreeAgainOnceItChanges(t *testing.T) {
+	path := copyFixturesToTempDir(t)
+	defer os.RemoveAll(path)
+	fileRepository, _ := FromPath(path)
+	fileRepository.FindAll()
+
+	writeFile(t, filepath.Join(path, "broken.yaml"), "name: [")
+	fileRepository.reloadIfChanged()
+	fileRepository.reloadIfChanged()
+	assert.Equal(t, 2, fileRepository.LoadStats().Loads)
+
+	writeFile(t, filepath.Join(path, "broken.yaml"), "name: [[")
+	fileRepository.reloadIfChanged()
+	assert.Equal(t, 3, fileRepository.LoadStats().Loads)
+}
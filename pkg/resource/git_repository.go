@@ -0,0 +1,235 @@
+package resource
+
+import (
+	"fmt"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/diagnostic"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/git"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/hubfile"
+	"log"
+	"sync"
+	"time"
+)
+
+type gitRepository struct {
+	source                   *git.Repository
+	ref                      string
+	path                     string
+	resourcesCache           cache
+	resourcesCacheFilledOnce sync.Once
+	commitMutex              sync.Mutex
+	// attempted is the commit last read, even when it failed to parse.
+	attempted string
+	modified  time.Time
+}
+
+// FromGit reads the resources found under path in the given branch, tag or
+// commit of a git repository.
+func FromGit(source *git.Repository, ref, path string) (*gitRepository, error) {
+	if _, err := source.Resolve(ref); err != nil {
+		return nil, err
+	}
+
+	return &gitRepository{source: source, ref: ref, path: path}, nil
+}
+
+// UseTaxonomy replaces the keywords of the resources with their canonical
+// keyword when they are loaded. It must be called before reading resources.
+func (g *gitRepository) UseTaxonomy(taxonomy *Taxonomy) {
+	g.resourcesCache.setTaxonomy(taxonomy)
+}
+
+func (g *gitRepository) FindAll() ([]*Resource, error) {
+	g.resourcesCacheFilledOnce.Do(g.fillResourcesCache)
+	return g.resourcesCache.findAll()
+}
+
+func (g *gitRepository) FindById(id string) (*Resource, error) {
+	g.resourcesCacheFilledOnce.Do(g.fillResourcesCache)
+	return g.resourcesCache.findById(id)
+}
+
+func (g *gitRepository) FindVersions(id string) ([]*Resource, error) {
+	g.resourcesCacheFilledOnce.Do(g.fillResourcesCache)
+	return g.resourcesCache.findVersions(id)
+}
+
+func (g *gitRepository) FindVersion(id, version string) (*Resource, error) {
+	g.resourcesCacheFilledOnce.Do(g.fillResourcesCache)
+	return g.resourcesCache.findVersion(id, version)
+}
+
+func (g *gitRepository) Diagnostics() []*diagnostic.Diagnostic {
+	g.resourcesCacheFilledOnce.Do(g.fillResourcesCache)
+	return g.resourcesCache.getDiagnostics()
+}
+
+func (g *gitRepository) LoadStats() LoadStats {
+	g.resourcesCacheFilledOnce.Do(g.fillResourcesCache)
+	return g.resourcesCache.getLoadStats()
+}
+
+func (g *gitRepository) Index() (*Index, error) {
+	g.resourcesCacheFilledOnce.Do(g.fillResourcesCache)
+	return g.resourcesCache.getIndex()
+}
+
+func (g *gitRepository) Save(resource *Resource) error {
+	return errReadOnly
+}
+
+func (g *gitRepository) Update(resource *Resource) error {
+	return errReadOnly
+}
+
+func (g *gitRepository) Delete(id string) error {
+	return errReadOnly
+}
+
+var errReadOnly = fmt.Errorf("%w: resources are read from git", ErrReadOnly)
+
+// Reload reads the resources again when the ref points to a different commit.
+// When the new commit fails to parse, the resources loaded previously are kept.
+func (g *gitRepository) Reload() (err error) {
+	g.resourcesCacheFilledOnce.Do(g.fillResourcesCache)
+
+	commit, err := g.source.Resolve(g.ref)
+	if err != nil {
+		return err
+	}
+	if !g.attempt(commit) {
+		return nil
+	}
+
+	started := time.Now()
+	defer func() { g.resourcesCache.recordLoad(started, err) }()
+
+	resources, diagnostics, err := g.resourcesAt(commit)
+	if err != nil {
+		return err
+	}
+
+	g.resourcesCache.swap(resources, diagnostics, nil)
+	g.setCommit(commit)
+	return nil
+}
+
+// Watch fetches the remote every interval and reloads the repository when the
+// ref moved. Calling the returned function stops watching.
+func (g *gitRepository) Watch(interval time.Duration) (stop func()) {
+	return every(interval, func() {
+		if err := g.source.Fetch(); err != nil {
+			log.Printf("unable to fetch resources from git: %s", err)
+			return
+		}
+		if err := g.Reload(); err != nil {
+			log.Printf("keeping previous resources, unable to reload %s from git: %s", g.ref, err)
+		}
+	})
+}
+
+func (g *gitRepository) fillResourcesCache() {
+	started := time.Now()
+	commit, err := g.source.Resolve(g.ref)
+	if err != nil {
+		g.resourcesCache.swap(nil, nil, err)
+		g.resourcesCache.recordLoad(started, err)
+		return
+	}
+
+	resources, diagnostics, err := g.resourcesAt(commit)
+	g.resourcesCache.swap(resources, diagnostics, err)
+	g.resourcesCache.recordLoad(started, err)
+	g.setCommit(commit)
+}
+
+func (g *gitRepository) resourcesAt(commit string) (resources []*Resource, diagnostics []*diagnostic.Diagnostic, err error) {
+	files, err := g.source.Files(commit, g.path)
+	if err != nil {
+		return
+	}
+
+	files, err = hubfile.Select(g.path, files, func(path string) ([]byte, error) {
+		return g.source.ReadFile(commit, path)
+	})
+	if err != nil {
+		return
+	}
+
+	var sources []string
+	for _, file := range files {
+		content, err := g.source.ReadFile(commit, file)
+		if err != nil {
+			return nil, nil, err
+		}
+		found, err := resourcesFromContent(file, content)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %s", file, err)
+		}
+		lastCommit, err := g.source.LastCommit(commit, file)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, resource := range found {
+			if problem := resource.kindError(); problem != "" {
+				diagnostics = append(diagnostics, &diagnostic.Diagnostic{Code: diagnostic.UNKNOWN_KIND, Source: file, Message: problem})
+				continue
+			}
+			if problems := resource.payloadErrors(); len(problems) > 0 {
+				diagnostics = append(diagnostics, payloadDiagnostic(resource, file, problems))
+				continue
+			}
+			resource.Commit = &Commit{
+				Hash:    lastCommit.Hash,
+				Author:  lastCommit.Author,
+				Date:    lastCommit.Date,
+				Message: lastCommit.Message,
+			}
+			resources = append(resources, resource)
+			sources = append(sources, file)
+		}
+	}
+
+	resources, idDiagnostics := checkIDs(resources, sources)
+	diagnostics = append(diagnostics, idDiagnostics...)
+	return
+}
+
+// attempt records commit as the last one read, telling whether it wasn't
+// already, so a commit which fails to parse is only read again once the ref
+// moves.
+func (g *gitRepository) attempt(commit string) bool {
+	g.commitMutex.Lock()
+	defer g.commitMutex.Unlock()
+	if commit == g.attempted {
+		return false
+	}
+	g.attempted = commit
+	return true
+}
+
+// setCommit records the commit the resources were loaded from, and the date of
+// the last commit which modified them.
+func (g *gitRepository) setCommit(commit string) {
+	path := g.path
+	if path == "" {
+		path = "."
+	}
+	var modified time.Time
+	if last, err := g.source.LastCommit(commit, path); err == nil {
+		modified = last.Date
+	}
+
+	g.commitMutex.Lock()
+	defer g.commitMutex.Unlock()
+	g.attempted = commit
+	g.modified = modified
+}
+
+// LastModified returns the date of the last commit which modified the
+// resources.
+func (g *gitRepository) LastModified() time.Time {
+	g.resourcesCacheFilledOnce.Do(g.fillResourcesCache)
+	g.commitMutex.Lock()
+	defer g.commitMutex.Unlock()
+	return g.modified
+}
@@ -0,0 +1,188 @@
+package resource
+
+import (
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/diagnostic"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/git"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestGitRepositoryReadsResourcesFromARef(t *testing.T) {
+	origin := newGitOrigin(t)
+	defer os.RemoveAll(origin)
+	source, _ := git.Open(origin)
+	gitRepository, _ := FromGit(source, "master", "resources")
+
+	resources, err := gitRepository.FindAll()
+
+	assert.NoError(t, err)
+	assert.Len(t, resources, 2)
+	for i, expected := range loaded(buildResourcesFromFixtures()...) {
+		assert.NotNil(t, resources[i].Commit)
+		resources[i].Commit = nil
+		assert.Equal(t, expected, resources[i])
+	}
+}
+
+func TestGitRepositoryExposesCommitMetadata(t *testing.T) {
+	origin := newGitOrigin(t)
+	defer os.RemoveAll(origin)
+	source, _ := git.Open(origin)
+	gitRepository, _ := FromGit(source, "master", "resources")
+
+	resource, _ := gitRepository.FindById("apache")
+
+	commit, _ := source.Resolve("master")
+	assert.Equal(t, commit, resource.Commit.Hash)
+	assert.Equal(t, "Hub Tester", resource.Commit.Author)
+	assert.Equal(t, "Add resources", resource.Commit.Message)
+}
+
+func TestGitRepositoryReloadsWhenRefMoves(t *testing.T) {
+	origin := newGitOrigin(t)
+	defer os.RemoveAll(origin)
+	source, _ := git.Open(origin)
+	gitRepository, _ := FromGit(source, "master", "resources")
+	gitRepository.FindAll()
+
+	os.Remove(filepath.Join(origin, "work", "resources", "mongo.yaml"))
+	commitWork(t, origin, "Remove mongo")
+	err := gitRepository.Reload()
+
+	resources, _ := gitRepository.FindAll()
+	assert.NoError(t, err)
+	assert.Len(t, resources, 1)
+}
+
+func TestGitRepositorySkipsResourcesWithInvalidConditions(t *testing.T) {
+	origin := newGitOrigin(t)
+	defer os.RemoveAll(origin)
+	ioutil.WriteFile(filepath.Join(origin, "work", "resources", "broken.yaml"),
+		[]byte("kind: FalcoRules\nname: Broken\nrules:\n  - raw: \"- macro: broken\\n  condition: (evt.num <\"\n"), 0644)
+	commitWork(t, origin, "Add broken")
+	source, _ := git.Open(origin)
+	gitRepository, _ := FromGit(source, "master", "resources")
+
+	resources, err := gitRepository.FindAll()
+
+	assert.NoError(t, err)
+	assert.Len(t, resources, 2)
+	assert.Len(t, gitRepository.Diagnostics(), 1)
+	assert.Equal(t, diagnostic.INVALID_PAYLOAD, gitRepository.Diagnostics()[0].Code)
+}
+
+func TestGitRepositoryLastModifiedIsTheDateOfTheLastCommit(t *testing.T) {
+	origin := newGitOrigin(t)
+	defer os.RemoveAll(origin)
+	source, _ := git.Open(origin)
+	gitRepository, _ := FromGit(source, "master", "resources")
+
+	commit, _ := source.LastCommit("master", "resources")
+	assert.True(t, commit.Date.Equal(gitRepository.LastModified()))
+}
+
+func TestGitRepositoryReadsEveryDocumentAndHonoursTheIgnoreFile(t *testing.T) {
+	origin := newGitOrigin(t)
+	defer os.RemoveAll(origin)
+	source, _ := git.Open(origin)
+	gitRepository, _ := FromGit(source, "master", "resources")
+	work := filepath.Join(origin, "work", "resources")
+	ioutil.WriteFile(filepath.Join(work, "web.yml"), []byte("kind: FalcoRules\nname: Nginx\n---\nkind: FalcoRules\nname: Traefik\n"), 0644)
+	ioutil.WriteFile(filepath.Join(work, ".hubignore"), []byte("mongo.yaml\n"), 0644)
+	commitWork(t, origin, "Add web servers")
+
+	resources, err := gitRepository.FindAll()
+
+	assert.NoError(t, err)
+	assert.Len(t, resources, 3)
+	_, err = gitRepository.FindById("mongodb")
+	assert.Error(t, err)
+}
+
+func TestGitRepositoryReadsAPinnedRef(t *testing.T) {
+	origin := newGitOrigin(t)
+	defer os.RemoveAll(origin)
+	source, _ := git.Open(origin)
+	pinned, _ := source.Resolve("master")
+
+	os.Remove(filepath.Join(origin, "work", "resources", "mongo.yaml"))
+	commitWork(t, origin, "Remove mongo")
+	gitRepository, _ := FromGit(source, pinned, "resources")
+
+	resources, _ := gitRepository.FindAll()
+	assert.Len(t, resources, 2)
+}
+
+func TestGitRepositoryOnlyReadsABrokenCommitAgainOnceTheRefMoves(t *testing.T) {
+	origin := newGitOrigin(t)
+	defer os.RemoveAll(origin)
+	source, _ := git.Open(origin)
+	gitRepository, _ := FromGit(source, "master", "resources")
+	gitRepository.FindAll()
+
+	ioutil.WriteFile(filepath.Join(origin, "work", "resources", "broken.yaml"), []byte("name: ["), 0644)
+	commitWork(t, origin, "Break")
+	firstErr := gitRepository.Reload()
+	secondErr := gitRepository.Reload()
+
+	assert.Error(t, firstErr)
+	assert.NoError(t, secondErr)
+	assert.Equal(t, 2, gitRepository.LoadStats().Loads)
+
+	os.Remove(filepath.Join(origin, "work", "resources", "broken.yaml"))
+	commitWork(t, origin, "Fix")
+	assert.NoError(t, gitRepository.Reload())
+	assert.Equal(t, 3, gitRepository.LoadStats().Loads)
+}
+
+func TestGitRepositoryReturnsAnErrorIfRefDoesNotExist(t *testing.T) {
+	origin := newGitOrigin(t)
+	defer os.RemoveAll(origin)
+	source, _ := git.Open(origin)
+
+	_, err := FromGit(source, "non-existent", "resources")
+
+	assert.Error(t, err)
+}
+
+// newGitOrigin creates a bare repository holding the resource fixtures, with
+// a working copy in its "work" subdirectory used to push new commits.
+func newGitOrigin(t *testing.T) string {
+	origin, _ := ioutil.TempDir("", "origin")
+	gitCommand(t, origin, "init", "--quiet", "--bare")
+	gitCommand(t, origin, "symbolic-ref", "HEAD", "refs/heads/master")
+
+	work := filepath.Join(origin, "work")
+	gitCommand(t, origin, "init", "--quiet", work)
+	gitCommand(t, work, "checkout", "--quiet", "-b", "master")
+	os.MkdirAll(filepath.Join(work, "resources"), 0755)
+	files, _ := filepath.Glob("../../test/fixtures/resources/*.yaml")
+	for _, file := range files {
+		content, _ := ioutil.ReadFile(file)
+		writeFile(t, filepath.Join(work, "resources", filepath.Base(file)), string(content))
+	}
+	commitWork(t, origin, "Add resources")
+	return origin
+}
+
+func commitWork(t *testing.T, origin, message string) {
+	work := filepath.Join(origin, "work")
+	gitCommand(t, work, "add", "-A")
+	gitCommand(t, work, "commit", "--quiet", "-m", message)
+	gitCommand(t, work, "push", "--quiet", origin, "master")
+}
+
+func gitCommand(t *testing.T, dir string, args ...string) {
+	command := exec.Command("git", args...)
+	command.Dir = dir
+	command.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Hub Tester", "GIT_AUTHOR_EMAIL=tester@example.com",
+		"GIT_COMMITTER_NAME=Hub Tester", "GIT_COMMITTER_EMAIL=tester@example.com")
+	if output, err := command.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %s", args, output)
+	}
+}
@@ -0,0 +1,91 @@
+package resource
+
+import (
+	"fmt"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/diagnostic"
+	"strings"
+)
+
+// reservedIDs are segments of the API paths under /resources/ which take
+// precedence over a resource with the same ID, like /resources/search.
+var reservedIDs = map[string]bool{"search": true}
+
+// reserved tells whether the resources can't use id, as it is taken by the
+// API.
+func reserved(id string) bool {
+	return reservedIDs[strings.ToLower(id)]
+}
+
+// aliasesOf maps the former IDs of the resources to their current ID.
+func aliasesOf(resources []*Resource) map[string]string {
+	aliases := map[string]string{}
+	for _, resource := range resources {
+		for _, alias := range resource.Aliases {
+			aliases[strings.ToLower(alias)] = idOf(resource)
+		}
+	}
+	return aliases
+}
+
+// checkIDs drops the resources read from the given sources which can't be
+// told apart: those with an invalid ID, and every definition of a version of
+// a resource after the first one. It reports them, and aliases shadowing
+// another resource, as diagnostics.
+func checkIDs(resources []*Resource, sources []string) (kept []*Resource, diagnostics []*diagnostic.Diagnostic) {
+	definedIn := map[string]string{}
+	ids := map[string]bool{}
+	for position, resource := range resources {
+		source := sources[position]
+		if problems := resource.idErrors(); len(problems) > 0 {
+			for _, problem := range problems {
+				diagnostics = append(diagnostics, &diagnostic.Diagnostic{Code: diagnostic.INVALID_ID, Source: source, Message: problem})
+			}
+			continue
+		}
+
+		id := idOf(resource)
+		key := id + "@" + resource.Version
+		if previous, ok := definedIn[key]; ok {
+			diagnostics = append(diagnostics, &diagnostic.Diagnostic{
+				Code:    diagnostic.DUPLICATE_ID,
+				Source:  source,
+				Message: fmt.Sprintf("%s is already defined in %s, ignoring this definition", describe(id, resource.Version), previous),
+			})
+			continue
+		}
+		definedIn[key] = source
+		ids[id] = true
+		kept = append(kept, resource)
+	}
+
+	aliasedBy := map[string]string{}
+	for _, resource := range kept {
+		id := idOf(resource)
+		for _, alias := range resource.Aliases {
+			if ids[alias] {
+				diagnostics = append(diagnostics, &diagnostic.Diagnostic{
+					Code:    diagnostic.ALIAS_CONFLICT,
+					Source:  definedIn[id+"@"+resource.Version],
+					Message: fmt.Sprintf("the alias %q of %q is the ID of another resource", alias, id),
+				})
+			}
+			if previous, ok := aliasedBy[alias]; ok && previous != id {
+				diagnostics = append(diagnostics, &diagnostic.Diagnostic{
+					Code:    diagnostic.ALIAS_CONFLICT,
+					Source:  definedIn[id+"@"+resource.Version],
+					Message: fmt.Sprintf("the alias %q is used by both %q and %q", alias, previous, id),
+				})
+			}
+			aliasedBy[alias] = id
+		}
+	}
+
+	return
+}
+
+func describe(id, version string) string {
+	if version == "" {
+		return fmt.Sprintf("resource %q", id)
+	}
+	return fmt.Sprintf("resource %q version %q", id, version)
+}
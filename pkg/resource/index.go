@@ -0,0 +1,138 @@
+package resource
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Weights applied to a term depending on the field where it was found, so a
+// match in the name ranks above a match buried in the rules.
+const (
+	nameWeight             = 10
+	keywordWeight          = 6
+	vendorWeight           = 5
+	shortDescriptionWeight = 4
+	descriptionWeight      = 2
+	rulesWeight            = 1
+)
+
+type posting struct {
+	resource int
+	score    int
+}
+
+// Index is an inverted index over the text fields of a set of resources.
+type Index struct {
+	resources []*Resource
+	postings  map[string][]posting
+	terms     []string
+}
+
+func NewIndex(resources []*Resource) *Index {
+	index := &Index{
+		resources: resources,
+		postings:  map[string][]posting{},
+	}
+
+	for i, resource := range resources {
+		scores := map[string]int{}
+		addTerms(scores, resource.Name, nameWeight)
+		for _, keyword := range resource.Keywords {
+			addTerms(scores, keyword, keywordWeight)
+		}
+		addTerms(scores, resource.Vendor, vendorWeight)
+		addTerms(scores, resource.ShortDescription, shortDescriptionWeight)
+		addTerms(scores, resource.Description, descriptionWeight)
+		for _, rule := range resource.Rules {
+			addTerms(scores, rule.Raw, rulesWeight)
+		}
+		for _, policy := range resource.Policies {
+			addTerms(scores, policy.Raw, rulesWeight)
+		}
+
+		for term, score := range scores {
+			index.postings[term] = append(index.postings[term], posting{resource: i, score: score})
+		}
+	}
+
+	for term := range index.postings {
+		index.terms = append(index.terms, term)
+	}
+	sort.Strings(index.terms)
+
+	return index
+}
+
+// Search returns the resources matching every term in the query, most
+// relevant first. A term matches an indexed word exactly or as a prefix,
+// exact matches weighing twice as much.
+func (i *Index) Search(query string) []*Resource {
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var scores map[int]int
+	for _, term := range terms {
+		termScores := i.scoresFor(term)
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for resource := range scores {
+			if score, ok := termScores[resource]; ok {
+				scores[resource] += score
+			} else {
+				delete(scores, resource)
+			}
+		}
+	}
+
+	matches := make([]int, 0, len(scores))
+	for resource := range scores {
+		matches = append(matches, resource)
+	}
+	sort.Slice(matches, func(a, b int) bool {
+		if scores[matches[a]] != scores[matches[b]] {
+			return scores[matches[a]] > scores[matches[b]]
+		}
+		return matches[a] < matches[b]
+	})
+
+	result := make([]*Resource, 0, len(matches))
+	for _, resource := range matches {
+		result = append(result, i.resources[resource])
+	}
+	return result
+}
+
+func (i *Index) scoresFor(term string) map[int]int {
+	scores := map[int]int{}
+	for position := sort.SearchStrings(i.terms, term); position < len(i.terms); position++ {
+		indexed := i.terms[position]
+		if !strings.HasPrefix(indexed, term) {
+			break
+		}
+		factor := 1
+		if indexed == term {
+			factor = 2
+		}
+		for _, p := range i.postings[indexed] {
+			scores[p.resource] += p.score * factor
+		}
+	}
+	return scores
+}
+
+func addTerms(scores map[string]int, text string, weight int) {
+	for _, term := range tokenize(text) {
+		scores[term] += weight
+	}
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
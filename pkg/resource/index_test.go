@@ -0,0 +1,29 @@
+package resource
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestIndexSearchesAcrossFields(t *testing.T) {
+	index := NewIndex(buildResourcesFromFixtures())
+
+	assert.Equal(t, "apache", index.Search("Apache")[0].ID)
+	assert.Equal(t, "mongodb", index.Search("database")[0].ID)
+	assert.Equal(t, "mongodb", index.Search("mongo_consider_syscalls")[0].ID)
+}
+
+func TestIndexReturnsNothingWhenNoResourceMatches(t *testing.T) {
+	index := NewIndex(buildResourcesFromFixtures())
+
+	assert.Empty(t, index.Search("kubernetes"))
+}
+
+func TestIndexIsBuiltWhenFileRepositoryIsFilled(t *testing.T) {
+	fileRepository, _ := FromPath("../../test/fixtures/resources")
+
+	index, err := fileRepository.Index()
+
+	assert.NoError(t, err)
+	assert.Len(t, index.Search("falco rules"), 2)
+}
@@ -0,0 +1,46 @@
+package resource
+
+import (
+	"sort"
+	"strings"
+)
+
+// Keyword is a keyword in use, with the number of resources tagged with it.
+type Keyword struct {
+	Keyword   string `json:"keyword"`
+	Resources int    `json:"resources"`
+}
+
+// CountKeywords returns every keyword of the resources, the most used first.
+// Keywords differing only in case are counted together, under the spelling
+// found first.
+func CountKeywords(resources []*Resource) []*Keyword {
+	keywords := []*Keyword{}
+	byName := map[string]*Keyword{}
+	for _, resource := range resources {
+		counted := map[string]bool{}
+		for _, name := range resource.Keywords {
+			key := strings.ToLower(strings.TrimSpace(name))
+			if key == "" || counted[key] {
+				continue
+			}
+			counted[key] = true
+
+			keyword, ok := byName[key]
+			if !ok {
+				keyword = &Keyword{Keyword: strings.TrimSpace(name)}
+				byName[key] = keyword
+				keywords = append(keywords, keyword)
+			}
+			keyword.Resources++
+		}
+	}
+
+	sort.SliceStable(keywords, func(i, j int) bool {
+		if keywords[i].Resources != keywords[j].Resources {
+			return keywords[i].Resources > keywords[j].Resources
+		}
+		return strings.ToLower(keywords[i].Keyword) < strings.ToLower(keywords[j].Keyword)
+	})
+	return keywords
+}
@@ -0,0 +1,24 @@
+package resource
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCountKeywordsListsTheMostUsedFirst(t *testing.T) {
+	resources := []*Resource{
+		{ID: "mongodb", Keywords: []string{"database"}},
+		{ID: "apache", Keywords: []string{"web", "http"}},
+		{ID: "nginx", Keywords: []string{"Web", "WEB"}},
+	}
+
+	assert.Equal(t, []*Keyword{
+		{Keyword: "web", Resources: 2},
+		{Keyword: "database", Resources: 1},
+		{Keyword: "http", Resources: 1},
+	}, CountKeywords(resources))
+}
+
+func TestCountKeywordsWithoutResources(t *testing.T) {
+	assert.Equal(t, []*Keyword{}, CountKeywords(nil))
+}
@@ -0,0 +1,101 @@
+package resource
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// KindDefinition describes a kind of resource: how its payload is checked and
+// which exporters can render it.
+type KindDefinition interface {
+	// Validate returns the problems found in the payload of the resource.
+	Validate(resource *Resource) []string
+	// ExportFormats lists the formats the resources of the kind can be
+	// exported to.
+	ExportFormats() []string
+}
+
+var kinds = map[Kind]KindDefinition{}
+
+// legacyKinds maps the names kinds were once published under to their
+// current name.
+var legacyKinds = map[Kind]Kind{
+	"FalcoRule": FALCO_RULES,
+}
+
+// RegisterKind makes a kind of resource available, resources of any other
+// kind are rejected.
+func RegisterKind(kind Kind, definition KindDefinition) {
+	kinds[kind] = definition
+}
+
+// Kinds returns every registered kind, sorted.
+func Kinds() []Kind {
+	names := make([]Kind, 0, len(kinds))
+	for kind := range kinds {
+		names = append(names, kind)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// canonicalKind returns the current name of a kind published under a legacy
+// name, and any other kind untouched.
+func canonicalKind(kind Kind) Kind {
+	if current, ok := legacyKinds[kind]; ok {
+		return current
+	}
+	return kind
+}
+
+// kindError checks the kind of the resource is registered, returning an
+// empty string when it is.
+func (r *Resource) kindError() string {
+	if r.Kind == "" {
+		return "the resource must have a defined Kind"
+	}
+	if _, ok := kinds[r.Kind]; !ok {
+		var names []string
+		for _, kind := range Kinds() {
+			names = append(names, string(kind))
+		}
+		return fmt.Sprintf("the resource kind %q is not supported, use one of %s", r.Kind, strings.Join(names, ", "))
+	}
+	return ""
+}
+
+// payloadErrors checks the payload of the resource with the definition of
+// its kind. Resources of an unknown kind have no payload to check.
+func (r *Resource) payloadErrors() []string {
+	definition, ok := kinds[r.Kind]
+	if !ok {
+		return nil
+	}
+	return definition.Validate(r)
+}
+
+// exportable checks the resource can be rendered in the format. Resources
+// built without a kind predate the other kinds, they only hold Falco rules.
+func exportable(resource *Resource, format string) error {
+	kind := resource.Kind
+	if kind == "" {
+		kind = FALCO_RULES
+	}
+	if definition, ok := kinds[kind]; ok {
+		for _, supported := range definition.ExportFormats() {
+			if supported == format {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%w: %q is not available for the %s resource %q", ErrUnknownFormat, format, kind, resource.ID)
+}
+
+func init() {
+	RegisterKind(FALCO_RULES, falcoRulesKind{})
+	RegisterKind(OPA_POLICIES, regoKind{})
+	RegisterKind(POD_SECURITY_POLICIES, kubernetesKind{manifestKind: "PodSecurityPolicy"})
+	RegisterKind(NETWORK_POLICIES, kubernetesKind{manifestKind: "NetworkPolicy"})
+	RegisterKind(SCANNING_POLICIES, scanningKind{})
+}
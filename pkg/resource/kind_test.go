@@ -0,0 +1,100 @@
+package resource
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+	"testing"
+)
+
+func resourceWithPolicies(kind Kind, policies ...string) Resource {
+	resource := newResource()
+	resource.Kind = kind
+	for _, policy := range policies {
+		resource.Policies = append(resource.Policies, &PolicyData{Raw: policy})
+	}
+	return resource
+}
+
+func TestKindsListsTheRegisteredKinds(t *testing.T) {
+	assert.Equal(t, []Kind{FALCO_RULES, NETWORK_POLICIES, OPA_POLICIES, POD_SECURITY_POLICIES, SCANNING_POLICIES}, Kinds())
+}
+
+func TestResourceValidateRejectsUnknownKinds(t *testing.T) {
+	resource := newResource()
+	resource.Kind = "GrafanaDashboard"
+
+	assert.Equal(t, &ValidationError{Errors: []string{
+		`the resource kind "GrafanaDashboard" is not supported, use one of FalcoRules, NetworkPolicies, OPAPolicies, PodSecurityPolicies, ScanningPolicies`,
+	}}, resource.Validate())
+}
+
+func TestLegacyFalcoRuleKindIsRenamed(t *testing.T) {
+	var fromJSON, fromYAML Resource
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"kind": "FalcoRule", "name": "Nginx"}`), &fromJSON))
+	assert.NoError(t, yaml.Unmarshal([]byte("kind: FalcoRule\nname: Nginx\n"), &fromYAML))
+
+	assert.Equal(t, FALCO_RULES, fromJSON.Kind)
+	assert.Equal(t, FALCO_RULES, fromYAML.Kind)
+}
+
+func TestResourceValidateRejectsPayloadOfAnotherKind(t *testing.T) {
+	rulesWithPolicies := resourceWithPolicies(FALCO_RULES, "package hub\n")
+	policiesWithRules := resourceWithPolicies(OPA_POLICIES)
+	policiesWithRules.Rules = []*FalcoRuleData{{Raw: "- macro: nginx\n  condition: proc.name = nginx\n"}}
+
+	assert.Equal(t, &ValidationError{Errors: []string{
+		"the FalcoRules resources hold their payload in rules, not in policies",
+	}}, rulesWithPolicies.Validate())
+	assert.Equal(t, &ValidationError{Errors: []string{
+		"the OPAPolicies resources hold their payload in policies, not in rules",
+	}}, policiesWithRules.Validate())
+}
+
+func TestResourceValidateRegoPolicies(t *testing.T) {
+	valid := resourceWithPolicies(OPA_POLICIES, "package kubernetes.admission\n\ndeny[msg] {\n  msg := \"denied\"\n}\n")
+	withoutPackage := resourceWithPolicies(OPA_POLICIES, "deny[msg] {\n  msg := \"denied\"\n}\n")
+
+	assert.NoError(t, valid.Validate())
+	assert.Equal(t, &ValidationError{Errors: []string{
+		"policy 1 must declare its Rego package",
+	}}, withoutPackage.Validate())
+}
+
+func TestResourceValidateKubernetesPolicies(t *testing.T) {
+	valid := resourceWithPolicies(POD_SECURITY_POLICIES,
+		"apiVersion: policy/v1beta1\nkind: PodSecurityPolicy\nmetadata:\n  name: restricted\n---\napiVersion: policy/v1beta1\nkind: PodSecurityPolicy\nmetadata:\n  name: privileged\n")
+	invalid := resourceWithPolicies(NETWORK_POLICIES,
+		"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: rules\n",
+		"kind: NetworkPolicy\nmetadata:\n  name: deny-all\n",
+		"apiVersion: networking.k8s.io/v1\nkind: NetworkPolicy\nmetadata:\n  name: Deny All\n",
+		"---\n",
+		"kind: [",
+	)
+
+	assert.NoError(t, valid.Validate())
+	assert.Equal(t, &ValidationError{Errors: []string{
+		`policy 1 must only have NetworkPolicy manifests, not "ConfigMap"`,
+		"policy 2 has a NetworkPolicy without apiVersion",
+		`policy 3 has a NetworkPolicy with an invalid name "Deny All"`,
+		"policy 4 must have at least one NetworkPolicy",
+		"policy 5 is not a valid manifest: yaml: line 1: did not find expected node content",
+	}}, invalid.Validate())
+}
+
+func TestResourceValidateScanningPolicies(t *testing.T) {
+	valid := resourceWithPolicies(SCANNING_POLICIES,
+		"name: No critical vulnerabilities\nrules:\n  - gate: vulnerabilities\n    trigger: package\n    action: stop\n")
+	invalid := resourceWithPolicies(SCANNING_POLICIES,
+		"rules:\n  - gate: vulnerabilities\n",
+		"name: Empty\n",
+	)
+
+	assert.NoError(t, valid.Validate())
+	assert.Equal(t, &ValidationError{Errors: []string{
+		"policy 1 must have a name",
+		"rule 1 of policy 1 must have a gate and a trigger",
+		"policy 2 must have at least one rule",
+	}}, invalid.Validate())
+}
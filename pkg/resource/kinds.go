@@ -0,0 +1,155 @@
+package resource
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// falcoRulesKind holds Falco rules files, which are parsed and checked the way
+// Falco would load them.
+type falcoRulesKind struct{}
+
+func (falcoRulesKind) Validate(resource *Resource) []string {
+	var errors []string
+	if len(resource.Policies) > 0 {
+		errors = append(errors, fmt.Sprintf("the %s resources hold their payload in rules, not in policies", FALCO_RULES))
+	}
+	for _, rule := range resource.Rules {
+		errors = append(errors, rule.Validate()...)
+	}
+	return errors
+}
+
+func (falcoRulesKind) ExportFormats() []string {
+	return []string{"custom-rules.yaml", "rules.yaml", "configmap.yaml", "kustomization.yaml", "rulesfile.yaml"}
+}
+
+var regoPackage = regexp.MustCompile(`(?m)^\s*package\s+\S+`)
+
+// regoKind holds Open Policy Agent policies written in Rego, one module per
+// policy.
+type regoKind struct{}
+
+func (regoKind) Validate(resource *Resource) []string {
+	errors := policiesOnly(resource)
+	for position, policy := range resource.Policies {
+		if !regoPackage.MatchString(policy.Raw) {
+			errors = append(errors, fmt.Sprintf("policy %d must declare its Rego package", position+1))
+		}
+	}
+	return errors
+}
+
+func (regoKind) ExportFormats() []string {
+	return []string{"opa-configmap.yaml"}
+}
+
+type manifest struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Metadata   struct {
+		Name string `yaml:"name"`
+	} `yaml:"metadata"`
+}
+
+// kubernetesKind holds Kubernetes manifests of a single kind, like
+// NetworkPolicies. A policy may hold several manifests as YAML documents.
+type kubernetesKind struct {
+	manifestKind string
+}
+
+func (k kubernetesKind) Validate(resource *Resource) []string {
+	errors := policiesOnly(resource)
+	for position, policy := range resource.Policies {
+		manifests, err := manifestsIn(policy.Raw)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("policy %d is not a valid manifest: %s", position+1, err))
+			continue
+		}
+		if len(manifests) == 0 {
+			errors = append(errors, fmt.Sprintf("policy %d must have at least one %s", position+1, k.manifestKind))
+		}
+		for _, m := range manifests {
+			switch {
+			case m.Kind != k.manifestKind:
+				errors = append(errors, fmt.Sprintf("policy %d must only have %s manifests, not %q", position+1, k.manifestKind, m.Kind))
+			case m.APIVersion == "":
+				errors = append(errors, fmt.Sprintf("policy %d has a %s without apiVersion", position+1, k.manifestKind))
+			case len(m.Metadata.Name) > 253 || !dnsSubdomain.MatchString(m.Metadata.Name):
+				errors = append(errors, fmt.Sprintf("policy %d has a %s with an invalid name %q", position+1, k.manifestKind, m.Metadata.Name))
+			}
+		}
+	}
+	return errors
+}
+
+func (kubernetesKind) ExportFormats() []string {
+	return []string{"policies.yaml"}
+}
+
+type scanningPolicy struct {
+	Name  string                   `yaml:"name"`
+	Rules []map[string]interface{} `yaml:"rules"`
+}
+
+// scanningKind holds image scanning policies, a named list of rules made of a
+// gate and the trigger evaluated in it.
+type scanningKind struct{}
+
+func (scanningKind) Validate(resource *Resource) []string {
+	errors := policiesOnly(resource)
+	for position, policy := range resource.Policies {
+		var scanning scanningPolicy
+		if err := yaml.Unmarshal([]byte(policy.Raw), &scanning); err != nil {
+			errors = append(errors, fmt.Sprintf("policy %d is not a valid scanning policy: %s", position+1, err))
+			continue
+		}
+		if scanning.Name == "" {
+			errors = append(errors, fmt.Sprintf("policy %d must have a name", position+1))
+		}
+		if len(scanning.Rules) == 0 {
+			errors = append(errors, fmt.Sprintf("policy %d must have at least one rule", position+1))
+		}
+		for number, rule := range scanning.Rules {
+			if rule["gate"] == nil || rule["trigger"] == nil {
+				errors = append(errors, fmt.Sprintf("rule %d of policy %d must have a gate and a trigger", number+1, position+1))
+			}
+		}
+	}
+	return errors
+}
+
+func (scanningKind) ExportFormats() []string {
+	return []string{"policies.yaml"}
+}
+
+// policiesOnly rejects Falco rules in the resources of kinds holding their
+// payload in policies.
+func policiesOnly(resource *Resource) []string {
+	if len(resource.Rules) > 0 {
+		return []string{fmt.Sprintf("the %s resources hold their payload in policies, not in rules", resource.Kind)}
+	}
+	return nil
+}
+
+// manifestsIn decodes every non empty YAML document of content.
+func manifestsIn(content string) ([]*manifest, error) {
+	var manifests []*manifest
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	for {
+		var m *manifest
+		err := decoder.Decode(&m)
+		if err == io.EOF {
+			return manifests, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			manifests = append(manifests, m)
+		}
+	}
+}
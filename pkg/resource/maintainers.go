@@ -0,0 +1,91 @@
+package resource
+
+import (
+	"fmt"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/slug"
+	"net/mail"
+	"sort"
+	"strings"
+)
+
+// MaintainerSummary is a maintainer of one or several resources, with the IDs
+// of the resources they maintain.
+type MaintainerSummary struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Email     string   `json:"email,omitempty"`
+	Resources []string `json:"resources"`
+}
+
+// MaintainerID identifies a maintainer across resources by their email,
+// falling back to their name when they have no email. It is empty for the
+// empty entries of a list of maintainers.
+func MaintainerID(maintainer *Maintainer) string {
+	if maintainer == nil {
+		return ""
+	}
+	if email := strings.TrimSpace(maintainer.Email); email != "" {
+		return strings.ToLower(email)
+	}
+	return slug.Make(maintainer.Name)
+}
+
+// SummarizeMaintainers lists every maintainer of the resources once, sorted
+// by name. A maintainer listed with several names keeps the first one.
+func SummarizeMaintainers(resources []*Resource) []*MaintainerSummary {
+	summaries := []*MaintainerSummary{}
+	byID := map[string]*MaintainerSummary{}
+	for _, resource := range resources {
+		for _, maintainer := range resource.Maintainers {
+			id := MaintainerID(maintainer)
+			if id == "" {
+				continue
+			}
+
+			summary, ok := byID[id]
+			if !ok {
+				summary = &MaintainerSummary{ID: id, Name: maintainer.Name, Email: strings.TrimSpace(maintainer.Email), Resources: []string{}}
+				byID[id] = summary
+				summaries = append(summaries, summary)
+			}
+			if !contains(summary.Resources, resource.ID) {
+				summary.Resources = append(summary.Resources, resource.ID)
+			}
+		}
+	}
+
+	sort.SliceStable(summaries, func(i, j int) bool {
+		a, b := strings.ToLower(summaries[i].Name), strings.ToLower(summaries[j].Name)
+		if a != b {
+			return a < b
+		}
+		return summaries[i].ID < summaries[j].ID
+	})
+	return summaries
+}
+
+// MaintainedBy tells whether the maintainer with the given ID maintains the
+// resource.
+func (r *Resource) MaintainedBy(maintainerID string) bool {
+	for _, maintainer := range r.Maintainers {
+		if id := MaintainerID(maintainer); id != "" && id == strings.ToLower(maintainerID) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Resource) maintainerErrors() []string {
+	var errors []string
+	for position, maintainer := range r.Maintainers {
+		if maintainer == nil {
+			errors = append(errors, fmt.Sprintf("the maintainer %d must have a name and an email", position+1))
+			continue
+		}
+		address, err := mail.ParseAddress(maintainer.Email)
+		if err != nil || address.Address != maintainer.Email {
+			errors = append(errors, fmt.Sprintf("the maintainer %q must have a valid email, like name@example.com", maintainer.Name))
+		}
+	}
+	return errors
+}
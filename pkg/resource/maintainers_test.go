@@ -0,0 +1,63 @@
+package resource
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSummarizeMaintainersDeduplicatesByEmail(t *testing.T) {
+	resources := []*Resource{
+		{ID: "nginx", Maintainers: []*Maintainer{{Name: "Néstor", Email: "nestor@sysdig.com"}, {Name: "bencer", Email: "bencer@sysdig.com"}}},
+		{ID: "apache", Maintainers: []*Maintainer{{Name: "Nestor Salceda", Email: "Nestor@sysdig.com"}}},
+		{ID: "mongodb", Maintainers: []*Maintainer{{Name: "Fede Barcelona"}}},
+	}
+
+	assert.Equal(t, []*MaintainerSummary{
+		{ID: "bencer@sysdig.com", Name: "bencer", Email: "bencer@sysdig.com", Resources: []string{"nginx"}},
+		{ID: "fede-barcelona", Name: "Fede Barcelona", Resources: []string{"mongodb"}},
+		{ID: "nestor@sysdig.com", Name: "Néstor", Email: "nestor@sysdig.com", Resources: []string{"nginx", "apache"}},
+	}, SummarizeMaintainers(resources))
+}
+
+func TestResourceMaintainedBy(t *testing.T) {
+	resource := &Resource{Maintainers: []*Maintainer{{Name: "bencer", Email: "bencer@sysdig.com"}, {Name: "Fede Barcelona"}}}
+
+	assert.True(t, resource.MaintainedBy("Bencer@Sysdig.com"))
+	assert.True(t, resource.MaintainedBy("fede-barcelona"))
+	assert.False(t, resource.MaintainedBy("nestor@sysdig.com"))
+}
+
+func TestResourceValidateMaintainerEmails(t *testing.T) {
+	resourceWithInvalidEmails := newResource()
+
+	resourceWithInvalidEmails.Maintainers = []*Maintainer{
+		{Name: "bencer", Email: "bencer@sysdig.com"},
+		{Name: "nestor", Email: "nestor"},
+		{Name: "fede", Email: "Fede <fede@sysdig.com>"},
+		{Name: "anonymous"},
+	}
+
+	assert.Equal(t, &ValidationError{Errors: []string{
+		`the maintainer "nestor" must have a valid email, like name@example.com`,
+		`the maintainer "fede" must have a valid email, like name@example.com`,
+		`the maintainer "anonymous" must have a valid email, like name@example.com`,
+	}}, resourceWithInvalidEmails.Validate())
+}
+
+func TestResourceValidateReportsEmptyMaintainers(t *testing.T) {
+	resourceWithEmptyMaintainer := newResource()
+
+	resourceWithEmptyMaintainer.Maintainers = []*Maintainer{{Name: "bencer", Email: "bencer@sysdig.com"}, nil}
+
+	assert.Equal(t, &ValidationError{Errors: []string{
+		`the maintainer 2 must have a name and an email`,
+	}}, resourceWithEmptyMaintainer.Validate())
+}
+
+func TestEmptyMaintainersAreSkipped(t *testing.T) {
+	resource := &Resource{ID: "nginx", Maintainers: []*Maintainer{nil, {Name: "bencer", Email: "bencer@sysdig.com"}}}
+
+	assert.Len(t, SummarizeMaintainers([]*Resource{resource}), 1)
+	assert.True(t, resource.MaintainedBy("bencer@sysdig.com"))
+	assert.False(t, resource.MaintainedBy(""))
+}
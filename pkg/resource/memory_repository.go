@@ -1,12 +1,12 @@
 package resource
 
 import (
-	"fmt"
 	"strings"
 )
 
 type MemoryRepository struct {
 	resources []*Resource
+	index     *Index
 }
 
 func NewMemoryRepository(resources []*Resource) Repository {
@@ -16,19 +16,91 @@ func NewMemoryRepository(resources []*Resource) Repository {
 }
 
 func (r *MemoryRepository) FindAll() ([]*Resource, error) {
-	return r.resources, nil
+	latest, _ := latestVersions(r.resources)
+	return latest, nil
 }
 
 func (r *MemoryRepository) FindById(id string) (*Resource, error) {
-	idToFind := strings.ToLower(id)
+	versions, err := r.FindVersions(id)
+	if err != nil {
+		return nil, err
+	}
+	return versions[0], nil
+}
+
+func (r *MemoryRepository) FindVersions(id string) ([]*Resource, error) {
+	_, versions := latestVersions(r.resources)
+	found, ok := versions[strings.ToLower(id)]
+	if !ok {
+		if to, ok := aliasesOf(r.resources)[strings.ToLower(id)]; ok {
+			return nil, &MovedError{ID: id, To: to}
+		}
+		return nil, notFound(id)
+	}
+	return found, nil
+}
+
+func (r *MemoryRepository) FindVersion(id, version string) (*Resource, error) {
+	position := r.positionOf(id, version)
+	if position == -1 {
+		return nil, versionNotFound(id, version)
+	}
+	return r.resources[position], nil
+}
+
+func (r *MemoryRepository) Save(resource *Resource) error {
+	if r.positionOf(resource.ID, resource.Version) != -1 {
+		return alreadyExists(resource.ID, resource.Version)
+	}
+	r.Add(*resource)
+	return nil
+}
+
+func (r *MemoryRepository) Update(resource *Resource) error {
+	position := r.positionOf(resource.ID, resource.Version)
+	if position == -1 {
+		return versionNotFound(resource.ID, resource.Version)
+	}
+	r.resources[position] = resource
+	r.index = nil
+	return nil
+}
+
+func (r *MemoryRepository) Delete(id string) error {
+	idToDelete := strings.ToLower(id)
+	var kept []*Resource
 	for _, res := range r.resources {
-		if res.ID == idToFind {
-			return res, nil
+		if res.ID != idToDelete {
+			kept = append(kept, res)
 		}
 	}
-	return nil, fmt.Errorf("not found")
+	if len(kept) == len(r.resources) {
+		return notFound(id)
+	}
+	r.resources = kept
+	r.index = nil
+	return nil
+}
+
+func (r *MemoryRepository) Index() (*Index, error) {
+	if r.index == nil {
+		latest, _ := latestVersions(r.resources)
+		r.index = NewIndex(latest)
+	}
+	return r.index, nil
 }
 
 func (r *MemoryRepository) Add(resource Resource) {
 	r.resources = append(r.resources, &resource)
+	r.index = nil
+}
+
+func (r *MemoryRepository) positionOf(id, version string) int {
+	idToFind := strings.ToLower(id)
+	for position, res := range r.resources {
+		if res.ID == idToFind && res.Version == version {
+			return position
+		}
+	}
+	return -1
 }
@@ -1,6 +1,61 @@
 package resource
 
+import (
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/diagnostic"
+	"time"
+)
+
+// Repository holds every version of the resources. FindAll and FindById
+// return the latest version of each resource.
 type Repository interface {
 	FindAll() ([]*Resource, error)
 	FindById(id string) (*Resource, error)
+	// FindVersions returns every version of a resource, newest first.
+	FindVersions(id string) ([]*Resource, error)
+	FindVersion(id, version string) (*Resource, error)
+	// Save stores a new resource or a new version of a resource, failing if
+	// its ID and version are already taken.
+	Save(resource *Resource) error
+	// Update replaces the stored resource with the same ID and version.
+	Update(resource *Resource) error
+	// Delete removes every version of a resource.
+	Delete(id string) error
+}
+
+// IndexedRepository is implemented by repositories which keep a search index
+// up to date with their contents.
+type IndexedRepository interface {
+	Repository
+	Index() (*Index, error)
+}
+
+// DiagnosedRepository is implemented by repositories which skip the resources
+// they can't serve while loading, reporting them as diagnostics.
+type DiagnosedRepository interface {
+	Repository
+	Diagnostics() []*diagnostic.Diagnostic
+}
+
+// ModifiedRepository is implemented by repositories which know when their
+// resources last changed.
+type ModifiedRepository interface {
+	Repository
+	LastModified() time.Time
+}
+
+// LoadStats tells how loading the resources of a repository went, for monitoring.
+type LoadStats struct {
+	// Loads counts every time the resources were read from the source.
+	Loads int
+	// Failures counts the loads which failed, keeping the resources loaded
+	// previously.
+	Failures     int
+	LastDuration time.Duration
+}
+
+// MonitoredRepository is implemented by repositories which read their resources
+// in bulk, keeping track of how loading them went.
+type MonitoredRepository interface {
+	Repository
+	LoadStats() LoadStats
 }
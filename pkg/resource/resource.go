@@ -3,18 +3,26 @@ package resource
 import (
 	"encoding/json"
 	"fmt"
-	"gopkg.in/yaml.v2"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/diagnostic"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/slug"
 	"strings"
+	"time"
 )
 
 type Kind string
 
 const (
-	FALCO_RULE Kind = "FalcoRule"
+	FALCO_RULES           Kind = "FalcoRules"
+	OPA_POLICIES          Kind = "OPAPolicies"
+	POD_SECURITY_POLICIES Kind = "PodSecurityPolicies"
+	NETWORK_POLICIES      Kind = "NetworkPolicies"
+	SCANNING_POLICIES     Kind = "ScanningPolicies"
 )
 
 type Resource struct {
 	ID               string           `json:"id,omitempty" yaml:"id,omitempty"`
+	Aliases          []string         `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+	Version          string           `json:"version,omitempty" yaml:"version,omitempty"`
 	Kind             Kind             `json:"kind" yaml:"kind"`
 	Vendor           string           `json:"vendor" yaml:"vendor"`
 	Name             string           `json:"name" yaml:"name"`
@@ -25,18 +33,8 @@ type Resource struct {
 	Website          string           `json:"website" yaml:"website"`
 	Maintainers      []*Maintainer    `json:"maintainers" yaml:"maintainers"`
 	Rules            []*FalcoRuleData `json:"rules" yaml:"rules"`
-}
-
-func (r *Resource) GenerateRulesForHelmChart() []byte {
-	raw := make(map[string]map[string]string)
-	raw["customRules"] = map[string]string{}
-
-	for _, rule := range r.Rules {
-		raw["customRules"]["rules-"+r.ID+".yaml"] += rule.Raw
-	}
-
-	result, _ := yaml.Marshal(raw)
-	return result
+	Policies         []*PolicyData    `json:"policies,omitempty" yaml:"policies,omitempty"`
+	Commit           *Commit          `json:"commit,omitempty" yaml:"-"`
 }
 
 type resourceAlias Resource // Avoid stack overflow while marshalling / unmarshalling
@@ -48,14 +46,34 @@ func (r *Resource) UnmarshalYAML(unmarshal func(interface{}) error) (err error)
 		return
 	}
 	*r = Resource(res)
-	r.ID = r.generateID()
+	r.ID = idOf(r)
+	r.Kind = canonicalKind(r.Kind)
+	r.dropEmptyPayload()
 	return
 }
 
+// dropEmptyPayload removes the rules and policies decoded from empty list
+// items, like a "-" with nothing after it, so no code has to expect them.
+func (r *Resource) dropEmptyPayload() {
+	rules := r.Rules[:0]
+	for _, rule := range r.Rules {
+		if rule != nil {
+			rules = append(rules, rule)
+		}
+	}
+	policies := r.Policies[:0]
+	for _, policy := range r.Policies {
+		if policy != nil {
+			policies = append(policies, policy)
+		}
+	}
+	r.Rules, r.Policies = rules, policies
+}
+
 func (r *Resource) MarshalYAML() (interface{}, error) {
 	x := resourceAlias(*r)
-	x.ID = r.generateID()
-	return yaml.Marshal(x)
+	x.ID = idOf(r)
+	return x, nil
 }
 
 func (r *Resource) UnmarshalJSON(data []byte) (err error) {
@@ -65,13 +83,15 @@ func (r *Resource) UnmarshalJSON(data []byte) (err error) {
 		return
 	}
 	*r = Resource(res)
-	r.ID = r.generateID()
+	r.ID = idOf(r)
+	r.Kind = canonicalKind(r.Kind)
+	r.dropEmptyPayload()
 	return
 }
 
 func (r *Resource) MarshalJSON() ([]byte, error) {
 	x := resourceAlias(*r)
-	x.ID = r.generateID()
+	x.ID = idOf(r)
 	return json.Marshal(x)
 }
 
@@ -80,15 +100,42 @@ type Maintainer struct {
 	Email string `json:"email" yaml:"email"`
 }
 
+// Commit describes the last change of a resource read from a git repository.
+type Commit struct {
+	Hash    string    `json:"hash"`
+	Author  string    `json:"author"`
+	Date    time.Time `json:"date"`
+	Message string    `json:"message"`
+}
+
 type FalcoRuleData struct {
 	Raw string `json:"raw" yaml:"raw"`
+	// parsed holds the items of Raw once the repositories loading the rules
+	// parse them, so they aren't parsed again on every use.
+	parsed *parsedFalcoRules
+}
+
+// PolicyData is a policy of a kind other than Falco rules, like a Rego module
+// or a Kubernetes manifest, in the format of its kind.
+type PolicyData struct {
+	Raw string `json:"raw" yaml:"raw"`
+}
+
+// ValidationError lists every problem found while validating a resource.
+type ValidationError struct {
+	Errors []string
+}
+
+func (e *ValidationError) Error() string {
+	return strings.Join(e.Errors, ",")
 }
 
 func (r *Resource) Validate() error {
 	var errors []string
 
-	if r.Kind == "" {
-		errors = append(errors, "the resource must have a defined Kind")
+	errors = append(errors, r.idErrors()...)
+	if problem := r.kindError(); problem != "" {
+		errors = append(errors, problem)
 	}
 	if r.Vendor == "" {
 		errors = append(errors, "the resource must be assigned to a vendor")
@@ -96,17 +143,68 @@ func (r *Resource) Validate() error {
 	if len(r.Maintainers) == 0 {
 		errors = append(errors, "the resource must have at least one maintainer")
 	}
+	errors = append(errors, r.maintainerErrors()...)
 	if r.Icon == "" {
 		errors = append(errors, "the resource must have a valid icon")
 	}
+	if r.Version != "" && ValidateVersion(r.Version) != nil {
+		errors = append(errors, "the resource version must be a semantic version, like 1.2.0")
+	}
+	errors = append(errors, r.payloadErrors()...)
 
 	if len(errors) > 0 {
-		return fmt.Errorf(strings.Join(errors, ","))
+		return &ValidationError{Errors: errors}
 	}
 
 	return nil
 }
 
+// payloadDiagnostic reports a resource skipped while loading because Falco or
+// the tools of its kind would refuse its rules or policies.
+func payloadDiagnostic(resource *Resource, source string, problems []string) *diagnostic.Diagnostic {
+	return &diagnostic.Diagnostic{
+		Code:    diagnostic.INVALID_PAYLOAD,
+		Source:  source,
+		Message: fmt.Sprintf("the resource %q was skipped: %s", resource.ID, strings.Join(problems, "; ")),
+	}
+}
+
+// idErrors checks the ID and aliases of the resource are safe to use in URLs
+// and file names.
+func (r *Resource) idErrors() []string {
+	var errors []string
+
+	id := idOf(r)
+	switch {
+	case id == "":
+		errors = append(errors, "the resource must have an ID or a name to derive it from")
+	case !slug.Valid(id):
+		errors = append(errors, fmt.Sprintf("the resource ID %q must only have lowercase letters, digits, \".\", \"-\" and \"_\"", id))
+	case reserved(id):
+		errors = append(errors, fmt.Sprintf("the resource ID %q is reserved by the API", id))
+	}
+	for _, alias := range r.Aliases {
+		switch {
+		case !slug.Valid(alias):
+			errors = append(errors, fmt.Sprintf("the resource alias %q must only have lowercase letters, digits, \".\", \"-\" and \"_\"", alias))
+		case reserved(alias):
+			errors = append(errors, fmt.Sprintf("the resource alias %q is reserved by the API", alias))
+		case alias == id:
+			errors = append(errors, fmt.Sprintf("the resource alias %q is the ID of the resource", alias))
+		}
+	}
+
+	return errors
+}
+
 func (r *Resource) generateID() string {
-	return strings.ToLower(r.Name)
+	return slug.Make(r.Name)
+}
+
+// idOf returns the ID of resources built without one from their name.
+func idOf(resource *Resource) string {
+	if resource.ID == "" {
+		return resource.generateID()
+	}
+	return resource.ID
 }
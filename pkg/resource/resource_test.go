@@ -1,7 +1,10 @@
 package resource
 
 import (
+	"encoding/json"
+	"errors"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
 	"testing"
 )
 
@@ -43,11 +46,72 @@ func TestResourceValidateIcon(t *testing.T) {
 	assert.Error(t, resourceWithoutIcon.Validate())
 }
 
+func TestResourceValidateVersion(t *testing.T) {
+	resourceWithInvalidVersion := newResource()
+
+	resourceWithInvalidVersion.Version = "v1"
+
+	assert.Error(t, resourceWithInvalidVersion.Validate())
+}
+
+func TestResourceValidateID(t *testing.T) {
+	resourceWithInvalidID := newResource()
+
+	resourceWithInvalidID.ID = "Grafana Dashboard"
+
+	assert.Error(t, resourceWithInvalidID.Validate())
+}
+
+func TestResourceValidateAliases(t *testing.T) {
+	resourceWithInvalidAliases := newResource()
+
+	resourceWithInvalidAliases.Aliases = []string{"grafana/dashboard", "grafana-dashboard"}
+
+	assert.Equal(t, &ValidationError{Errors: []string{
+		`the resource alias "grafana/dashboard" must only have lowercase letters, digits, ".", "-" and "_"`,
+		`the resource alias "grafana-dashboard" is the ID of the resource`,
+	}}, resourceWithInvalidAliases.Validate())
+}
+
+func TestResourceIDIsDerivedFromTheNameWhenMissing(t *testing.T) {
+	var withoutID, withID Resource
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"name": "Nginx / Ingress"}`), &withoutID))
+	assert.NoError(t, json.Unmarshal([]byte(`{"id": "nginx", "name": "Nginx / Ingress"}`), &withID))
+
+	assert.Equal(t, "nginx-ingress", withoutID.ID)
+	assert.Equal(t, "nginx", withID.ID)
+}
+
+func TestResourceDropsEmptyRulesAndPolicies(t *testing.T) {
+	var fromYAML, fromJSON Resource
+
+	assert.NoError(t, yaml.Unmarshal([]byte("name: Nginx\nrules:\n  -\n  - raw: \"- list: a\"\npolicies:\n  -\n"), &fromYAML))
+	assert.NoError(t, json.Unmarshal([]byte(`{"name": "Nginx", "rules": [null], "policies": [null, {"raw": "package a"}]}`), &fromJSON))
+
+	assert.Equal(t, []*FalcoRuleData{{Raw: "- list: a"}}, fromYAML.Rules)
+	assert.Empty(t, fromYAML.Policies)
+	assert.Empty(t, fromJSON.Rules)
+	assert.Equal(t, []*PolicyData{{Raw: "package a"}}, fromJSON.Policies)
+}
+
+func TestResourceValidateRulesConditions(t *testing.T) {
+	resourceWithBrokenRules := newResource()
+
+	resourceWithBrokenRules.Rules = []*FalcoRuleData{
+		{Raw: "- macro: spawned_process\n  condition: evt.type = execve and\n"},
+	}
+
+	assert.Equal(t, &ValidationError{Errors: []string{
+		`line 1: macro "spawned_process" has an invalid condition at 1:22: unexpected end of condition, expected a field, a macro or "("`,
+	}}, resourceWithBrokenRules.Validate())
+}
+
 func newResource() Resource {
 	return Resource{
-		Kind:        "GrafanaDashboard",
+		Kind:        FALCO_RULES,
 		Vendor:      "Sysdig",
-		Name:        "",
+		Name:        "Grafana Dashboard",
 		Description: "",
 		Rules:       nil,
 		Keywords:    []string{"monitoring"},
@@ -60,3 +124,20 @@ func newResource() Resource {
 		},
 	}
 }
+
+func TestValidateIDRejectsPathsAndBlankIDs(t *testing.T) {
+	assert.NoError(t, ValidateID("apache"))
+	assert.True(t, errors.Is(ValidateID(""), ErrInvalidID))
+	assert.True(t, errors.Is(ValidateID("../apache"), ErrInvalidID))
+}
+
+func TestResourceIDsReservedByTheAPIAreRejected(t *testing.T) {
+	reservedID := newResource()
+	reservedID.ID = "search"
+	reservedAlias := newResource()
+	reservedAlias.Aliases = []string{"search"}
+
+	assert.True(t, errors.Is(ValidateID("Search"), ErrInvalidID))
+	assert.Equal(t, &ValidationError{Errors: []string{`the resource ID "search" is reserved by the API`}}, reservedID.Validate())
+	assert.Equal(t, &ValidationError{Errors: []string{`the resource alias "search" is reserved by the API`}}, reservedAlias.Validate())
+}
@@ -0,0 +1,383 @@
+package resource
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+type sqlRepository struct {
+	db       *sql.DB
+	taxonomy *Taxonomy
+}
+
+// FromDatabase stores resources in a database with the schema created by the
+// database package.
+func FromDatabase(db *sql.DB) *sqlRepository {
+	return &sqlRepository{db: db}
+}
+
+// UseTaxonomy replaces the keywords of the resources with their canonical
+// keyword when they are read from the database.
+func (s *sqlRepository) UseTaxonomy(taxonomy *Taxonomy) {
+	s.taxonomy = taxonomy
+}
+
+const selectResources = `SELECT id, version, kind, vendor, name, short_description, description, icon, website FROM resources`
+
+func (s *sqlRepository) FindAll() ([]*Resource, error) {
+	resources, err := s.query(selectResources + ` ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, _ := latestVersions(resources)
+	return latest, nil
+}
+
+func (s *sqlRepository) FindById(id string) (*Resource, error) {
+	versions, err := s.FindVersions(id)
+	if err != nil {
+		return nil, err
+	}
+	return versions[0], nil
+}
+
+func (s *sqlRepository) FindVersions(id string) ([]*Resource, error) {
+	resources, err := s.query(selectResources+` WHERE id = $1`, strings.ToLower(id))
+	if err != nil {
+		return nil, err
+	}
+	if len(resources) == 0 {
+		return nil, s.movedOrNotFound(id, notFound(id))
+	}
+
+	sortNewestFirst(resources)
+	return resources, nil
+}
+
+func (s *sqlRepository) FindVersion(id, version string) (*Resource, error) {
+	resources, err := s.query(selectResources+` WHERE id = $1 AND version = $2`, strings.ToLower(id), version)
+	if err != nil {
+		return nil, err
+	}
+	if len(resources) == 0 {
+		return nil, s.movedOrNotFound(id, versionNotFound(id, version))
+	}
+
+	return resources[0], nil
+}
+
+// movedOrNotFound returns a MovedError when id is the alias of a resource, or
+// notFound otherwise.
+func (s *sqlRepository) movedOrNotFound(id string, notFound error) error {
+	var to string
+	err := s.db.QueryRow(`SELECT resource_id FROM resource_aliases WHERE alias = $1`, strings.ToLower(id)).Scan(&to)
+	switch {
+	case err == sql.ErrNoRows:
+		return notFound
+	case err != nil:
+		return backendError(err)
+	}
+	return &MovedError{ID: id, To: to}
+}
+
+func (s *sqlRepository) query(query string, args ...interface{}) ([]*Resource, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, backendError(err)
+	}
+
+	resources, err := scanResources(rows)
+	if err != nil {
+		return nil, backendError(err)
+	}
+
+	if err := s.fillChildren(resources); err != nil {
+		return nil, backendError(err)
+	}
+	s.taxonomy.Normalize(resources)
+	return resources, nil
+}
+
+func (s *sqlRepository) Save(resource *Resource) error {
+	return s.inTransaction(func(tx *sql.Tx) error {
+		id := idOf(resource)
+		exists, err := resourceExists(tx, id, resource.Version)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return alreadyExists(id, resource.Version)
+		}
+		return insertResource(tx, id, resource)
+	})
+}
+
+func (s *sqlRepository) Update(resource *Resource) error {
+	return s.inTransaction(func(tx *sql.Tx) error {
+		id := idOf(resource)
+		exists, err := resourceExists(tx, id, resource.Version)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return versionNotFound(id, resource.Version)
+		}
+		if err := deleteVersion(tx, id, resource.Version); err != nil {
+			return err
+		}
+		return insertResource(tx, id, resource)
+	})
+}
+
+func (s *sqlRepository) Delete(id string) error {
+	return s.inTransaction(func(tx *sql.Tx) error {
+		var count int
+		err := tx.QueryRow(`SELECT COUNT(*) FROM resources WHERE id = $1`, strings.ToLower(id)).Scan(&count)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return notFound(id)
+		}
+		return deleteAllVersions(tx, strings.ToLower(id))
+	})
+}
+
+func (s *sqlRepository) inTransaction(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return backendError(err)
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return backendError(err)
+	}
+
+	return backendError(tx.Commit())
+}
+
+func resourceExists(tx *sql.Tx, id, version string) (bool, error) {
+	var count int
+	err := tx.QueryRow(`SELECT COUNT(*) FROM resources WHERE id = $1 AND version = $2`, id, version).Scan(&count)
+	return count > 0, err
+}
+
+func deleteVersion(tx *sql.Tx, id, version string) error {
+	return deleteRows(tx, `resource_id = $1 AND resource_version = $2`, `id = $1 AND version = $2`, id, version)
+}
+
+func deleteAllVersions(tx *sql.Tx, id string) error {
+	return deleteRows(tx, `resource_id = $1`, `id = $1`, id)
+}
+
+// deleteRows removes the resources matching condition and the child rows
+// matching childCondition.
+func deleteRows(tx *sql.Tx, childCondition, condition string, args ...interface{}) error {
+	for _, statement := range []string{
+		`DELETE FROM resource_maintainers WHERE ` + childCondition,
+		`DELETE FROM resource_keywords WHERE ` + childCondition,
+		`DELETE FROM resource_aliases WHERE ` + childCondition,
+		`DELETE FROM resource_rules WHERE ` + childCondition,
+		`DELETE FROM resource_policies WHERE ` + childCondition,
+		`DELETE FROM resources WHERE ` + condition,
+	} {
+		if _, err := tx.Exec(statement, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insertResource(tx *sql.Tx, id string, resource *Resource) error {
+	_, err := tx.Exec(
+		`INSERT INTO resources (id, version, kind, vendor, name, short_description, description, icon, website)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		id, resource.Version, string(resource.Kind), resource.Vendor, resource.Name, resource.ShortDescription,
+		resource.Description, resource.Icon, resource.Website)
+	if err != nil {
+		return err
+	}
+
+	for position, maintainer := range resource.Maintainers {
+		_, err := tx.Exec(
+			`INSERT INTO resource_maintainers (resource_id, resource_version, position, name, email) VALUES ($1, $2, $3, $4, $5)`,
+			id, resource.Version, position, maintainer.Name, maintainer.Email)
+		if err != nil {
+			return err
+		}
+	}
+
+	for position, keyword := range resource.Keywords {
+		_, err := tx.Exec(
+			`INSERT INTO resource_keywords (resource_id, resource_version, position, keyword) VALUES ($1, $2, $3, $4)`,
+			id, resource.Version, position, keyword)
+		if err != nil {
+			return err
+		}
+	}
+
+	for position, alias := range resource.Aliases {
+		_, err := tx.Exec(
+			`INSERT INTO resource_aliases (resource_id, resource_version, position, alias) VALUES ($1, $2, $3, $4)`,
+			id, resource.Version, position, alias)
+		if err != nil {
+			return err
+		}
+	}
+
+	for position, rule := range resource.Rules {
+		_, err := tx.Exec(
+			`INSERT INTO resource_rules (resource_id, resource_version, position, raw) VALUES ($1, $2, $3, $4)`,
+			id, resource.Version, position, rule.Raw)
+		if err != nil {
+			return err
+		}
+	}
+
+	for position, policy := range resource.Policies {
+		_, err := tx.Exec(
+			`INSERT INTO resource_policies (resource_id, resource_version, position, raw) VALUES ($1, $2, $3, $4)`,
+			id, resource.Version, position, policy.Raw)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func scanResources(rows *sql.Rows) (resources []*Resource, err error) {
+	defer rows.Close()
+
+	for rows.Next() {
+		var resource Resource
+		var kind string
+		err = rows.Scan(&resource.ID, &resource.Version, &kind, &resource.Vendor, &resource.Name, &resource.ShortDescription,
+			&resource.Description, &resource.Icon, &resource.Website)
+		if err != nil {
+			return
+		}
+		resource.Kind = Kind(kind)
+		resources = append(resources, &resource)
+	}
+
+	err = rows.Err()
+	return
+}
+
+// fillChildren loads the maintainers, keywords, aliases, rules and policies
+// of the resources with one query per child table.
+func (s *sqlRepository) fillChildren(resources []*Resource) error {
+	if len(resources) == 0 {
+		return nil
+	}
+
+	type key struct{ id, version string }
+	byKey := make(map[key]*Resource, len(resources))
+	seen := map[string]bool{}
+	var ids []interface{}
+	var placeholders []string
+	for _, resource := range resources {
+		byKey[key{resource.ID, resource.Version}] = resource
+		if !seen[resource.ID] {
+			seen[resource.ID] = true
+			ids = append(ids, resource.ID)
+			placeholders = append(placeholders, fmt.Sprintf("$%d", len(ids)))
+		}
+	}
+	in := "(" + strings.Join(placeholders, ", ") + ")"
+	order := ` ORDER BY resource_id, resource_version, position`
+
+	err := s.eachRow(`SELECT resource_id, resource_version, name, email FROM resource_maintainers WHERE resource_id IN `+in+order, ids,
+		func(rows *sql.Rows) error {
+			var id, version string
+			var maintainer Maintainer
+			if err := rows.Scan(&id, &version, &maintainer.Name, &maintainer.Email); err != nil {
+				return err
+			}
+			if resource := byKey[key{id, version}]; resource != nil {
+				resource.Maintainers = append(resource.Maintainers, &maintainer)
+			}
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	err = s.eachRow(`SELECT resource_id, resource_version, keyword FROM resource_keywords WHERE resource_id IN `+in+order, ids,
+		func(rows *sql.Rows) error {
+			var id, version, keyword string
+			if err := rows.Scan(&id, &version, &keyword); err != nil {
+				return err
+			}
+			if resource := byKey[key{id, version}]; resource != nil {
+				resource.Keywords = append(resource.Keywords, keyword)
+			}
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	err = s.eachRow(`SELECT resource_id, resource_version, alias FROM resource_aliases WHERE resource_id IN `+in+order, ids,
+		func(rows *sql.Rows) error {
+			var id, version, alias string
+			if err := rows.Scan(&id, &version, &alias); err != nil {
+				return err
+			}
+			if resource := byKey[key{id, version}]; resource != nil {
+				resource.Aliases = append(resource.Aliases, alias)
+			}
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	err = s.eachRow(`SELECT resource_id, resource_version, raw FROM resource_rules WHERE resource_id IN `+in+order, ids,
+		func(rows *sql.Rows) error {
+			var id, version string
+			var rule FalcoRuleData
+			if err := rows.Scan(&id, &version, &rule.Raw); err != nil {
+				return err
+			}
+			if resource := byKey[key{id, version}]; resource != nil {
+				resource.Rules = append(resource.Rules, &rule)
+			}
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	return s.eachRow(`SELECT resource_id, resource_version, raw FROM resource_policies WHERE resource_id IN `+in+order, ids,
+		func(rows *sql.Rows) error {
+			var id, version string
+			var policy PolicyData
+			if err := rows.Scan(&id, &version, &policy.Raw); err != nil {
+				return err
+			}
+			if resource := byKey[key{id, version}]; resource != nil {
+				resource.Policies = append(resource.Policies, &policy)
+			}
+			return nil
+		})
+}
+
+func (s *sqlRepository) eachRow(query string, args []interface{}, fn func(rows *sql.Rows) error) error {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := fn(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,195 @@
+package resource
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/database"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSQLRepositoryReturnsSavedResources(t *testing.T) {
+	db := openTestDatabase(t)
+	defer db.Close()
+	sqlRepository := FromDatabase(db)
+	for _, resource := range buildResourcesFromFixtures() {
+		assert.NoError(t, sqlRepository.Save(resource))
+	}
+
+	resources, err := sqlRepository.FindAll()
+
+	assert.NoError(t, err)
+	assert.Equal(t, buildResourcesFromFixtures(), resources)
+}
+
+func TestSQLRepositoryFindsResourceById(t *testing.T) {
+	db := openTestDatabase(t)
+	defer db.Close()
+	sqlRepository := FromDatabase(db)
+	for _, resource := range buildResourcesFromFixtures() {
+		sqlRepository.Save(resource)
+	}
+
+	resource, err := sqlRepository.FindById("MongoDB")
+
+	assert.NoError(t, err)
+	assert.Equal(t, buildResourcesFromFixtures()[1], resource)
+}
+
+func TestSQLRepositorySaveFailsIfResourceExists(t *testing.T) {
+	db := openTestDatabase(t)
+	defer db.Close()
+	sqlRepository := FromDatabase(db)
+	resource := buildResourcesFromFixtures()[0]
+	sqlRepository.Save(resource)
+
+	assert.Error(t, sqlRepository.Save(resource))
+}
+
+func TestSQLRepositoryUpdateReplacesExistingResource(t *testing.T) {
+	db := openTestDatabase(t)
+	defer db.Close()
+	sqlRepository := FromDatabase(db)
+	resource := buildResourcesFromFixtures()[0]
+	sqlRepository.Save(resource)
+
+	resource.Keywords = []string{"http", "web"}
+	resource.Maintainers = resource.Maintainers[:1]
+	err := sqlRepository.Update(resource)
+
+	resources, _ := sqlRepository.FindAll()
+	assert.NoError(t, err)
+	assert.Equal(t, []*Resource{resource}, resources)
+}
+
+func TestSQLRepositoryStoresPolicies(t *testing.T) {
+	db := openTestDatabase(t)
+	defer db.Close()
+	sqlRepository := FromDatabase(db)
+	resource := &Resource{
+		ID:       "registries",
+		Kind:     OPA_POLICIES,
+		Name:     "Trusted registries",
+		Policies: []*PolicyData{{Raw: "package images\n"}, {Raw: "package registries\n"}},
+	}
+
+	err := sqlRepository.Save(resource)
+
+	resources, _ := sqlRepository.FindAll()
+	assert.NoError(t, err)
+	assert.Equal(t, []*Resource{resource}, resources)
+}
+
+func TestSQLRepositoryUpdateFailsIfResourceDoesNotExist(t *testing.T) {
+	db := openTestDatabase(t)
+	defer db.Close()
+
+	err := FromDatabase(db).Update(buildResourcesFromFixtures()[0])
+
+	assert.Error(t, err)
+}
+
+func TestSQLRepositoryDeletesResource(t *testing.T) {
+	db := openTestDatabase(t)
+	defer db.Close()
+	sqlRepository := FromDatabase(db)
+	for _, resource := range buildResourcesFromFixtures() {
+		sqlRepository.Save(resource)
+	}
+
+	err := sqlRepository.Delete("apache")
+
+	resources, _ := sqlRepository.FindAll()
+	assert.NoError(t, err)
+	assert.Equal(t, buildResourcesFromFixtures()[1:], resources)
+}
+
+func TestSQLRepositoryReturnsNotFound(t *testing.T) {
+	db := openTestDatabase(t)
+	defer db.Close()
+
+	_, err := FromDatabase(db).FindById("apache")
+
+	assert.Error(t, err)
+}
+
+func TestSQLRepositoryHoldsSeveralVersionsOfAResource(t *testing.T) {
+	db := openTestDatabase(t)
+	defer db.Close()
+	sqlRepository := FromDatabase(db)
+	for _, version := range []string{"1.0.0", "1.10.0", "1.9.0"} {
+		resource := buildResourcesFromFixtures()[0]
+		resource.Version = version
+		resource.Keywords = []string{"web", version}
+		assert.NoError(t, sqlRepository.Save(resource))
+	}
+
+	latest, _ := sqlRepository.FindById("apache")
+	versions, _ := sqlRepository.FindVersions("apache")
+	pinned, err := sqlRepository.FindVersion("apache", "1.9.0")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1.10.0", latest.Version)
+	assert.Equal(t, []string{"web", "1.10.0"}, latest.Keywords)
+	assert.Len(t, versions, 3)
+	assert.Equal(t, []string{"1.10.0", "1.9.0", "1.0.0"}, []string{versions[0].Version, versions[1].Version, versions[2].Version})
+	assert.Equal(t, []string{"web", "1.9.0"}, pinned.Keywords)
+	assert.Equal(t, buildResourcesFromFixtures()[0].Maintainers, pinned.Maintainers)
+}
+
+func TestSQLRepositoryUpdatesAndDeletesVersions(t *testing.T) {
+	db := openTestDatabase(t)
+	defer db.Close()
+	sqlRepository := FromDatabase(db)
+	for _, version := range []string{"1.0.0", "1.1.0"} {
+		resource := buildResourcesFromFixtures()[0]
+		resource.Version = version
+		sqlRepository.Save(resource)
+	}
+	updated := buildResourcesFromFixtures()[0]
+	updated.Version = "1.0.0"
+	updated.Vendor = "ASF"
+
+	assert.NoError(t, sqlRepository.Update(updated))
+	pinned, _ := sqlRepository.FindVersion("apache", "1.0.0")
+	latest, _ := sqlRepository.FindById("apache")
+	assert.Equal(t, "ASF", pinned.Vendor)
+	assert.Equal(t, "Apache", latest.Vendor)
+
+	missing := buildResourcesFromFixtures()[0]
+	missing.Version = "2.0.0"
+	assert.True(t, errors.Is(sqlRepository.Update(missing), ErrNotFound))
+
+	assert.NoError(t, sqlRepository.Delete("apache"))
+	_, err := sqlRepository.FindVersions("apache")
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestSQLRepositoryRedirectsAliasesToTheCurrentID(t *testing.T) {
+	db := openTestDatabase(t)
+	defer db.Close()
+	sqlRepository := FromDatabase(db)
+	resource := buildResourcesFromFixtures()[0]
+	resource.Aliases = []string{"apache-httpd"}
+	assert.NoError(t, sqlRepository.Save(resource))
+
+	found, err := sqlRepository.FindById("apache")
+	_, aliasErr := sqlRepository.FindById("apache-httpd")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"apache-httpd"}, found.Aliases)
+	assert.Equal(t, &MovedError{ID: "apache-httpd", To: "apache"}, aliasErr)
+
+	assert.NoError(t, sqlRepository.Delete("apache"))
+	_, aliasErr = sqlRepository.FindById("apache-httpd")
+	assert.True(t, errors.Is(aliasErr, ErrNotFound))
+}
+
+func openTestDatabase(t *testing.T) *sql.DB {
+	db, err := database.Open("sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
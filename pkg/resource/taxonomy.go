@@ -0,0 +1,105 @@
+package resource
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// Taxonomy maps the synonyms of keywords to their canonical keyword, so
+// resources tagged "db" and "databases" are all listed under "database".
+type Taxonomy struct {
+	canonical map[string]string
+}
+
+// NewTaxonomy builds a taxonomy from the synonyms of each canonical keyword.
+// Keywords are compared ignoring case, and a synonym can't belong to two
+// canonical keywords.
+func NewTaxonomy(synonyms map[string][]string) (*Taxonomy, error) {
+	taxonomy := &Taxonomy{canonical: map[string]string{}}
+
+	keywords := make([]string, 0, len(synonyms))
+	for keyword := range synonyms {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+
+	for _, keyword := range keywords {
+		if err := taxonomy.add(keyword, keyword); err != nil {
+			return nil, err
+		}
+	}
+	for _, keyword := range keywords {
+		for _, synonym := range synonyms[keyword] {
+			if err := taxonomy.add(synonym, keyword); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return taxonomy, nil
+}
+
+func (t *Taxonomy) add(synonym, keyword string) error {
+	key := strings.ToLower(strings.TrimSpace(synonym))
+	if key == "" {
+		return fmt.Errorf("the synonyms of %q must not be empty", keyword)
+	}
+	if previous, ok := t.canonical[key]; ok && previous != keyword {
+		return fmt.Errorf("%q can't be a synonym of %q, it already stands for %q", synonym, keyword, previous)
+	}
+	t.canonical[key] = keyword
+	return nil
+}
+
+// TaxonomyFromFile reads a YAML file mapping each canonical keyword to the
+// list of its synonyms.
+func TaxonomyFromFile(path string) (*Taxonomy, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var synonyms map[string][]string
+	if err := yaml.Unmarshal(content, &synonyms); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	taxonomy, err := NewTaxonomy(synonyms)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	return taxonomy, nil
+}
+
+// Canonical returns the canonical keyword a synonym stands for, and any
+// other keyword untouched. A nil taxonomy has no synonyms.
+func (t *Taxonomy) Canonical(keyword string) string {
+	if t == nil {
+		return keyword
+	}
+	if canonical, ok := t.canonical[strings.ToLower(strings.TrimSpace(keyword))]; ok {
+		return canonical
+	}
+	return keyword
+}
+
+// Normalize replaces the keywords of the resources with their canonical
+// keyword, dropping the ones repeated once replaced.
+func (t *Taxonomy) Normalize(resources []*Resource) {
+	if t == nil {
+		return
+	}
+	for _, resource := range resources {
+		var keywords []string
+		seen := map[string]bool{}
+		for _, keyword := range resource.Keywords {
+			canonical := t.Canonical(keyword)
+			if seen[strings.ToLower(canonical)] {
+				continue
+			}
+			seen[strings.ToLower(canonical)] = true
+			keywords = append(keywords, canonical)
+		}
+		resource.Keywords = keywords
+	}
+}
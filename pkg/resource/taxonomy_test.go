@@ -0,0 +1,70 @@
+package resource
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTaxonomyReturnsTheCanonicalKeyword(t *testing.T) {
+	taxonomy, err := NewTaxonomy(map[string][]string{"database": {"db", "Databases"}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "database", taxonomy.Canonical("DB"))
+	assert.Equal(t, "database", taxonomy.Canonical("databases"))
+	assert.Equal(t, "database", taxonomy.Canonical("Database"))
+	assert.Equal(t, "web", taxonomy.Canonical("web"))
+}
+
+func TestTaxonomyRejectsSynonymsOfSeveralKeywords(t *testing.T) {
+	_, err := NewTaxonomy(map[string][]string{"database": {"db"}, "storage": {"DB"}})
+
+	assert.EqualError(t, err, `"DB" can't be a synonym of "storage", it already stands for "database"`)
+}
+
+func TestTaxonomyNormalizesTheKeywordsOfResources(t *testing.T) {
+	taxonomy, _ := NewTaxonomy(map[string][]string{"database": {"db", "databases"}})
+	resources := []*Resource{{ID: "mongodb", Keywords: []string{"db", "nosql", "databases", "Database"}}}
+
+	taxonomy.Normalize(resources)
+
+	assert.Equal(t, []string{"database", "nosql"}, resources[0].Keywords)
+}
+
+func TestNilTaxonomyLeavesKeywordsUntouched(t *testing.T) {
+	var taxonomy *Taxonomy
+	resources := []*Resource{{ID: "mongodb", Keywords: []string{"db", "DB"}}}
+
+	taxonomy.Normalize(resources)
+
+	assert.Equal(t, "db", taxonomy.Canonical("db"))
+	assert.Equal(t, []string{"db", "DB"}, resources[0].Keywords)
+}
+
+func TestTaxonomyFromFile(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "taxonomy")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "taxonomy.yaml")
+	writeFile(t, path, "database:\n  - db\nweb: [http]\n")
+
+	taxonomy, err := TaxonomyFromFile(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "database", taxonomy.Canonical("db"))
+	assert.Equal(t, "web", taxonomy.Canonical("HTTP"))
+}
+
+func TestFileRepositoryNormalizesKeywordsWithTheTaxonomy(t *testing.T) {
+	path := copyFixturesToTempDir(t)
+	defer os.RemoveAll(path)
+	taxonomy, _ := NewTaxonomy(map[string][]string{"databases": {"database"}})
+	fileRepository, _ := FromPath(path)
+	fileRepository.UseTaxonomy(taxonomy)
+
+	mongo, err := fileRepository.FindById("mongodb")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"databases"}, mongo.Keywords)
+}
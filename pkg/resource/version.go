@@ -0,0 +1,123 @@
+package resource
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var semanticVersion = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+	`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// ValidateVersion checks that version is a semantic version, like 1.2.0 or
+// 2.0.0-rc.1.
+func ValidateVersion(version string) error {
+	if !semanticVersion.MatchString(version) {
+		return fmt.Errorf("%w: %q", ErrInvalidVersion, version)
+	}
+	return nil
+}
+
+// CompareVersions returns -1, 0 or 1 when a precedes, equals or follows b
+// following the semantic versioning precedence rules. Resources without a
+// version, or with an invalid one, precede every valid version.
+func CompareVersions(a, b string) int {
+	partsA, validA := versionParts(a)
+	partsB, validB := versionParts(b)
+	switch {
+	case !validA && !validB:
+		return strings.Compare(a, b)
+	case !validA:
+		return -1
+	case !validB:
+		return 1
+	}
+
+	for i := 0; i < 3; i++ {
+		if result := compareNumbers(partsA[i], partsB[i]); result != 0 {
+			return result
+		}
+	}
+
+	// A version without pre-release identifiers follows those which have them.
+	prereleaseA, prereleaseB := partsA[3], partsB[3]
+	switch {
+	case prereleaseA == prereleaseB:
+		return 0
+	case prereleaseA == "":
+		return 1
+	case prereleaseB == "":
+		return -1
+	}
+
+	identifiersA, identifiersB := strings.Split(prereleaseA, "."), strings.Split(prereleaseB, ".")
+	for i := 0; i < len(identifiersA) && i < len(identifiersB); i++ {
+		if result := compareIdentifiers(identifiersA[i], identifiersB[i]); result != 0 {
+			return result
+		}
+	}
+	return compareNumbers(strconv.Itoa(len(identifiersA)), strconv.Itoa(len(identifiersB)))
+}
+
+func versionParts(version string) ([]string, bool) {
+	match := semanticVersion.FindStringSubmatch(version)
+	if match == nil {
+		return nil, false
+	}
+	return match[1:5], true
+}
+
+func compareIdentifiers(a, b string) int {
+	_, errA := strconv.Atoi(a)
+	_, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return compareNumbers(a, b)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// compareNumbers compares numeric strings without leading zeros, which may
+// not fit in an int.
+func compareNumbers(a, b string) int {
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// latestVersions returns the newest version of each resource, in the order
+// each ID first appears, and every version of each resource, newest first.
+func latestVersions(resources []*Resource) (latest []*Resource, versions map[string][]*Resource) {
+	versions = map[string][]*Resource{}
+	var ids []string
+	for _, resource := range resources {
+		id := idOf(resource)
+		if _, ok := versions[id]; !ok {
+			ids = append(ids, id)
+		}
+		versions[id] = append(versions[id], resource)
+	}
+
+	for _, id := range ids {
+		sortNewestFirst(versions[id])
+		latest = append(latest, versions[id][0])
+	}
+	return
+}
+
+func sortNewestFirst(resources []*Resource) {
+	sort.SliceStable(resources, func(i, j int) bool {
+		return CompareVersions(resources[i].Version, resources[j].Version) > 0
+	})
+}
@@ -0,0 +1,56 @@
+package resource
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"sort"
+	"testing"
+)
+
+func TestValidateVersionAcceptsSemanticVersions(t *testing.T) {
+	for _, version := range []string{"0.1.0", "1.2.3", "2.0.0-rc.1", "1.0.0-alpha+001", "10.20.30+build.5"} {
+		assert.NoError(t, ValidateVersion(version), version)
+	}
+}
+
+func TestValidateVersionRejectsOtherVersions(t *testing.T) {
+	for _, version := range []string{"", "1", "1.2", "v1.2.3", "01.2.3", "1.2.3-", "1.2.3/../x"} {
+		assert.True(t, errors.Is(ValidateVersion(version), ErrInvalidVersion), version)
+	}
+}
+
+func TestCompareVersionsFollowsSemanticVersioningPrecedence(t *testing.T) {
+	versions := []string{"1.0.0", "", "1.0.0-rc.1", "1.0.0-alpha.beta", "0.9.10", "1.0.0-beta.11", "1.0.0-beta.2",
+		"1.0.0-alpha.1", "1.0.0-beta", "1.0.0-alpha", "10.0.0", "0.9.9", "2.0.0"}
+
+	sort.Slice(versions, func(i, j int) bool { return CompareVersions(versions[i], versions[j]) < 0 })
+
+	assert.Equal(t, []string{"", "0.9.9", "0.9.10", "1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta",
+		"1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "2.0.0", "10.0.0"}, versions)
+}
+
+func TestCompareVersionsIgnoresBuildMetadata(t *testing.T) {
+	assert.Equal(t, 0, CompareVersions("1.0.0+20130313", "1.0.0+exp.sha.5114f85"))
+}
+
+func versionOf(id, version string) *Resource {
+	return &Resource{ID: id, Name: id, Version: version}
+}
+
+func TestMemoryRepositoryHoldsSeveralVersionsOfAResource(t *testing.T) {
+	repository := NewMemoryRepository([]*Resource{versionOf("apache", "1.0.0"), versionOf("apache", "1.1.0"), versionOf("mongodb", "")})
+	assert.NoError(t, repository.Save(versionOf("apache", "1.0.1")))
+	assert.True(t, errors.Is(repository.Save(versionOf("apache", "1.0.1")), ErrAlreadyExists))
+
+	all, _ := repository.FindAll()
+	latest, _ := repository.FindById("apache")
+	versions, _ := repository.FindVersions("apache")
+	pinned, _ := repository.FindVersion("apache", "1.0.0")
+	_, missingErr := repository.FindVersion("apache", "2.0.0")
+
+	assert.Equal(t, []*Resource{versionOf("apache", "1.1.0"), versionOf("mongodb", "")}, all)
+	assert.Equal(t, versionOf("apache", "1.1.0"), latest)
+	assert.Equal(t, []*Resource{versionOf("apache", "1.1.0"), versionOf("apache", "1.0.1"), versionOf("apache", "1.0.0")}, versions)
+	assert.Equal(t, versionOf("apache", "1.0.0"), pinned)
+	assert.True(t, errors.Is(missingErr, ErrNotFound))
+}
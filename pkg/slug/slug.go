@@ -0,0 +1,31 @@
+package slug
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MaxLength is the longest ID accepted, so they fit in URLs and file names.
+const MaxLength = 128
+
+var (
+	valid     = regexp.MustCompile(`^[a-z0-9]([a-z0-9._-]*[a-z0-9])?$`)
+	separator = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// Make derives an ID from a display name, keeping lowercase letters and
+// digits and joining the words with dashes, so "Nginx / Ingress" becomes
+// "nginx-ingress".
+func Make(name string) string {
+	slug := strings.Trim(separator.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if len(slug) > MaxLength {
+		slug = strings.TrimRight(slug[:MaxLength], "-")
+	}
+	return slug
+}
+
+// Valid reports whether id only has lowercase letters, digits, dots, dashes
+// and underscores, and starts and ends with a letter or a digit.
+func Valid(id string) bool {
+	return len(id) <= MaxLength && valid.MatchString(id)
+}
@@ -0,0 +1,31 @@
+package slug
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestMakeJoinsWordsWithDashes(t *testing.T) {
+	assert.Equal(t, "nginx", Make("Nginx"))
+	assert.Equal(t, "nginx-ingress", Make("Nginx / Ingress"))
+	assert.Equal(t, "mongodb-4-0", Make("  MongoDB 4.0!  "))
+	assert.Equal(t, "", Make("!!"))
+}
+
+func TestMakeTruncatesLongNames(t *testing.T) {
+	slug := Make(strings.Repeat("a", MaxLength-1) + " b")
+
+	assert.Equal(t, strings.Repeat("a", MaxLength-1), slug)
+}
+
+func TestValidAcceptsURLSafeIDs(t *testing.T) {
+	assert.True(t, Valid("nginx"))
+	assert.True(t, Valid("nginx-ingress_1.0"))
+	assert.False(t, Valid(""))
+	assert.False(t, Valid("Nginx"))
+	assert.False(t, Valid("nginx ingress"))
+	assert.False(t, Valid("../nginx"))
+	assert.False(t, Valid("-nginx"))
+	assert.False(t, Valid(strings.Repeat("a", MaxLength+1)))
+}
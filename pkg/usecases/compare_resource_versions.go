@@ -0,0 +1,35 @@
+package usecases
+
+import (
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+)
+
+type CompareResourceVersions struct {
+	ResourceRepository resource.Repository
+	ResourceID         string
+	From               string
+	To                 string
+}
+
+func (useCase *CompareResourceVersions) Execute() (*resource.Diff, error) {
+	if err := resource.ValidateID(useCase.ResourceID); err != nil {
+		return nil, err
+	}
+	if err := resource.ValidateVersion(useCase.From); err != nil {
+		return nil, err
+	}
+	if err := resource.ValidateVersion(useCase.To); err != nil {
+		return nil, err
+	}
+
+	from, err := useCase.ResourceRepository.FindVersion(useCase.ResourceID, useCase.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := useCase.ResourceRepository.FindVersion(useCase.ResourceID, useCase.To)
+	if err != nil {
+		return nil, err
+	}
+
+	return resource.Compare(from, to)
+}
@@ -0,0 +1,59 @@
+package usecases
+
+import (
+	"errors"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func memoryResourceRepositoryWithRuleVersions() resource.Repository {
+	return resource.NewMemoryRepository(
+		[]*resource.Resource{
+			{ID: "nginx", Name: "Nginx", Version: "1.0.0", Rules: []*resource.FalcoRuleData{{Raw: "- macro: nginx_process\n  condition: proc.name = nginx\n"}}},
+			{ID: "nginx", Name: "Nginx", Version: "1.1.0", Rules: []*resource.FalcoRuleData{{Raw: "- macro: nginx_process\n  condition: proc.name = nginx\n- list: nginx_ports\n  items: [80]\n"}}},
+		},
+	)
+}
+
+func TestComparesTwoVersionsOfAResource(t *testing.T) {
+	useCase := CompareResourceVersions{
+		ResourceRepository: memoryResourceRepositoryWithRuleVersions(),
+		ResourceID:         "nginx",
+		From:               "1.0.0",
+		To:                 "1.1.0",
+	}
+
+	result, err := useCase.Execute()
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Added, 1)
+	assert.Equal(t, "nginx_ports", result.Added[0].Name)
+	assert.Empty(t, result.Removed)
+	assert.Empty(t, result.Modified)
+}
+
+func TestCompareResourceVersionsReturnsNotFound(t *testing.T) {
+	useCase := CompareResourceVersions{
+		ResourceRepository: memoryResourceRepositoryWithRuleVersions(),
+		ResourceID:         "nginx",
+		From:               "1.0.0",
+		To:                 "2.0.0",
+	}
+
+	_, err := useCase.Execute()
+
+	assert.True(t, errors.Is(err, resource.ErrNotFound))
+}
+
+func TestCompareResourceVersionsRequiresBothVersions(t *testing.T) {
+	useCase := CompareResourceVersions{
+		ResourceRepository: memoryResourceRepositoryWithRuleVersions(),
+		ResourceID:         "nginx",
+		From:               "1.0.0",
+	}
+
+	_, err := useCase.Execute()
+
+	assert.True(t, errors.Is(err, resource.ErrInvalidVersion))
+}
@@ -0,0 +1,43 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+)
+
+type CreateResource struct {
+	ResourceRepository resource.Repository
+	FalcoDefaults      *resource.FalcoDefaults
+	Resource           *resource.Resource
+}
+
+func (useCase *CreateResource) Execute() error {
+	if err := useCase.Resource.Validate(); err != nil {
+		return err
+	}
+	if err := validateIDs(useCase.ResourceRepository, useCase.Resource); err != nil {
+		return err
+	}
+	if err := validateDependencies(useCase.ResourceRepository, useCase.FalcoDefaults, useCase.Resource); err != nil {
+		return err
+	}
+	return useCase.ResourceRepository.Save(useCase.Resource)
+}
+
+// validateIDs fails when the ID of the resource is an alias of another
+// resource, or one of its aliases is used by another resource, so links keep
+// leading to the same resource.
+func validateIDs(repository resource.Repository, res *resource.Resource) error {
+	for _, id := range append([]string{res.ID}, res.Aliases...) {
+		existing, err := repository.FindById(id)
+		var moved *resource.MovedError
+		switch {
+		case errors.As(err, &moved) && moved.To != res.ID:
+			return fmt.Errorf("%q is an alias of resource %q: %w", id, moved.To, resource.ErrAlreadyExists)
+		case err == nil && id != res.ID && existing.ID != res.ID:
+			return fmt.Errorf("the alias %q is the ID of resource %q: %w", id, existing.ID, resource.ErrAlreadyExists)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package usecases
+
+import (
+	"errors"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func validResource(id string) *resource.Resource {
+	return &resource.Resource{
+		ID:     id,
+		Kind:   resource.FALCO_RULES,
+		Name:   id,
+		Vendor: "Nginx",
+		Icon:   "https://nginx.org/icon.png",
+		Maintainers: []*resource.Maintainer{
+			{Name: "bencer", Email: "bencer@sysdig.com"},
+		},
+	}
+}
+
+func TestCreatesResource(t *testing.T) {
+	resourceRepository := resource.NewMemoryRepository([]*resource.Resource{})
+	useCase := CreateResource{
+		ResourceRepository: resourceRepository,
+		Resource:           validResource("nginx"),
+	}
+
+	err := useCase.Execute()
+
+	created, _ := resourceRepository.FindById("nginx")
+	assert.NoError(t, err)
+	assert.Equal(t, validResource("nginx"), created)
+}
+
+func TestCreateResourceReturnsValidationErrors(t *testing.T) {
+	invalid := validResource("nginx")
+	invalid.Icon = ""
+	invalid.Maintainers = nil
+	useCase := CreateResource{
+		ResourceRepository: resource.NewMemoryRepository([]*resource.Resource{}),
+		Resource:           invalid,
+	}
+
+	err := useCase.Execute()
+
+	assert.IsType(t, &resource.ValidationError{}, err)
+	assert.Len(t, err.(*resource.ValidationError).Errors, 2)
+}
+
+func TestCreateResourceFailsIfResourceExists(t *testing.T) {
+	useCase := CreateResource{
+		ResourceRepository: resource.NewMemoryRepository([]*resource.Resource{validResource("nginx")}),
+		Resource:           validResource("nginx"),
+	}
+
+	err := useCase.Execute()
+
+	assert.Error(t, err)
+}
+
+func TestCreateResourceFailsIfAnAliasIsTheIDOfAnotherResource(t *testing.T) {
+	withAlias := validResource("nginx-ingress")
+	withAlias.Aliases = []string{"nginx"}
+	useCase := CreateResource{
+		ResourceRepository: resource.NewMemoryRepository([]*resource.Resource{validResource("nginx")}),
+		Resource:           withAlias,
+	}
+
+	err := useCase.Execute()
+
+	assert.True(t, errors.Is(err, resource.ErrAlreadyExists))
+}
+
+func TestCreateResourceFailsIfTheIDIsAnAliasOfAnotherResource(t *testing.T) {
+	withAlias := validResource("nginx-ingress")
+	withAlias.Aliases = []string{"nginx"}
+	useCase := CreateResource{
+		ResourceRepository: resource.NewMemoryRepository([]*resource.Resource{withAlias}),
+		Resource:           validResource("nginx"),
+	}
+
+	err := useCase.Execute()
+
+	assert.True(t, errors.Is(err, resource.ErrAlreadyExists))
+}
+
+func TestCreateResourceFailsWithUnresolvedMacros(t *testing.T) {
+	withUnknownMacro := validResource("nginx")
+	withUnknownMacro.Rules = []*resource.FalcoRuleData{
+		{Raw: "- rule: Shell in nginx\n  condition: spawned_process and nginx_shell\n"},
+	}
+	useCase := CreateResource{
+		ResourceRepository: resource.NewMemoryRepository([]*resource.Resource{}),
+		FalcoDefaults:      &resource.FalcoDefaults{Macros: []string{"spawned_process"}},
+		Resource:           withUnknownMacro,
+	}
+
+	err := useCase.Execute()
+
+	assert.Equal(t, &resource.ValidationError{Errors: []string{
+		`the macro "nginx_shell" used by "Shell in nginx" is not defined in the resource, the hub or Falco`,
+	}}, err)
+}
+
+func TestCreateResourceAcceptsMacrosMissingFromPartialFalcoDefaults(t *testing.T) {
+	withFalcoMacro := validResource("nginx")
+	withFalcoMacro.Rules = []*resource.FalcoRuleData{
+		{Raw: "- rule: Shell in nginx\n  condition: spawned_process and container_entrypoint\n"},
+	}
+	useCase := CreateResource{
+		ResourceRepository: resource.NewMemoryRepository([]*resource.Resource{}),
+		FalcoDefaults:      resource.NewFalcoDefaults(),
+		Resource:           withFalcoMacro,
+	}
+
+	assert.NoError(t, useCase.Execute())
+}
@@ -0,0 +1,17 @@
+package usecases
+
+import (
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+)
+
+type DeleteResource struct {
+	ResourceRepository resource.Repository
+	ResourceID         string
+}
+
+func (useCase *DeleteResource) Execute() error {
+	if err := resource.ValidateID(useCase.ResourceID); err != nil {
+		return err
+	}
+	return useCase.ResourceRepository.Delete(useCase.ResourceID)
+}
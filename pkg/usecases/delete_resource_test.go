@@ -0,0 +1,32 @@
+package usecases
+
+import (
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDeletesResource(t *testing.T) {
+	resourceRepository := resource.NewMemoryRepository([]*resource.Resource{validResource("nginx")})
+	useCase := DeleteResource{
+		ResourceRepository: resourceRepository,
+		ResourceID:         "nginx",
+	}
+
+	err := useCase.Execute()
+
+	resources, _ := resourceRepository.FindAll()
+	assert.NoError(t, err)
+	assert.Empty(t, resources)
+}
+
+func TestDeleteResourceReturnsNotFound(t *testing.T) {
+	useCase := DeleteResource{
+		ResourceRepository: resource.NewMemoryRepository([]*resource.Resource{}),
+		ResourceID:         "nginx",
+	}
+
+	err := useCase.Execute()
+
+	assert.Error(t, err)
+}
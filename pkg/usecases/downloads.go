@@ -0,0 +1,77 @@
+package usecases
+
+import (
+	"sort"
+	"sync"
+)
+
+// DownloadCounter counts the exports delivered to clients since the server
+// started, to rank resources by popularity. Exports answered with 304 Not
+// Modified are not downloads. Counts are kept in memory, so they start over
+// on restart and each replica of the server counts its own. A nil counter
+// counts nothing.
+type DownloadCounter struct {
+	mutex  sync.Mutex
+	counts map[downloadKey]int
+	totals map[string]int
+}
+
+type downloadKey struct {
+	resourceID string
+	format     string
+}
+
+// Download is the number of times a resource was downloaded in a format.
+type Download struct {
+	ResourceID string
+	Format     string
+	Count      int
+}
+
+func NewDownloadCounter() *DownloadCounter {
+	return &DownloadCounter{counts: map[downloadKey]int{}, totals: map[string]int{}}
+}
+
+// Record counts a download of each resource in the format.
+func (c *DownloadCounter) Record(format string, resourceIDs ...string) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, id := range resourceIDs {
+		c.counts[downloadKey{resourceID: id, format: format}]++
+		c.totals[id]++
+	}
+}
+
+// Count returns the downloads of a resource in every format.
+func (c *DownloadCounter) Count(resourceID string) int {
+	if c == nil {
+		return 0
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.totals[resourceID]
+}
+
+// Downloads returns the downloads of every resource in each format, sorted by
+// resource and format.
+func (c *DownloadCounter) Downloads() []*Download {
+	downloads := []*Download{}
+	if c == nil {
+		return downloads
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for key, count := range c.counts {
+		downloads = append(downloads, &Download{ResourceID: key.resourceID, Format: key.format, Count: count})
+	}
+	sort.Slice(downloads, func(i, j int) bool {
+		if downloads[i].ResourceID != downloads[j].ResourceID {
+			return downloads[i].ResourceID < downloads[j].ResourceID
+		}
+		return downloads[i].Format < downloads[j].Format
+	})
+	return downloads
+}
@@ -0,0 +1,66 @@
+package usecases
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDeliveredExportsAreCountedAsDownloads(t *testing.T) {
+	downloads := NewDownloadCounter()
+	exportResource := ExportResource{
+		ResourceRepository: memoryResourceRepositoryWithRules(),
+		ResourceID:         "nginx",
+		Format:             "rules.yaml",
+		Downloads:          downloads,
+	}
+	exportBundle := ExportBundle{
+		ResourceRepository: memoryResourceRepositoryWithBundledRules(),
+		ResourceIDs:        []string{"nginx", "traefik"},
+		Format:             "custom-rules.yaml",
+		Downloads:          downloads,
+	}
+
+	exportResource.Execute()
+	exportResource.RecordDownload()
+	exportBundle.Execute()
+	exportBundle.RecordDownload()
+
+	assert.Equal(t, 2, downloads.Count("nginx"))
+	assert.Equal(t, 1, downloads.Count("traefik"))
+	assert.Equal(t, 0, downloads.Count("apache"))
+	assert.Equal(t, []*Download{
+		{ResourceID: "nginx", Format: "custom-rules.yaml", Count: 1},
+		{ResourceID: "nginx", Format: "rules.yaml", Count: 1},
+		{ResourceID: "traefik", Format: "custom-rules.yaml", Count: 1},
+	}, (&RetrieveDownloads{Downloads: downloads}).Execute())
+}
+
+func TestExportsAreNotCountedUntilDelivered(t *testing.T) {
+	downloads := NewDownloadCounter()
+	useCase := ExportResource{
+		ResourceRepository: memoryResourceRepositoryWithRules(),
+		ResourceID:         "nginx",
+		Format:             "rules.yaml",
+		Downloads:          downloads,
+	}
+
+	useCase.Execute()
+
+	assert.Equal(t, 0, downloads.Count("nginx"))
+}
+
+func TestFailedExportsAreNotCountedAsDownloads(t *testing.T) {
+	downloads := NewDownloadCounter()
+	useCase := ExportResource{
+		ResourceRepository: memoryResourceRepositoryWithRules(),
+		ResourceID:         "nginx",
+		Format:             "policies.yaml",
+		Downloads:          downloads,
+	}
+
+	_, err := useCase.Execute()
+	useCase.RecordDownload()
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, downloads.Count("nginx"))
+}
@@ -0,0 +1,16 @@
+package usecases
+
+import "errors"
+
+var (
+	ErrInvalidQuery         = errors.New("invalid query")
+	ErrInvalidListOptions   = errors.New("invalid list options")
+	ErrNoResourcesForVendor = errors.New("no resources available for this vendor")
+	// ErrNoResourcesForKeyword is returned when no resource is tagged with a
+	// keyword.
+	ErrNoResourcesForKeyword = errors.New("no resources available for this keyword")
+	// ErrNoResourcesForMaintainer is returned when nobody with the given ID
+	// maintains a resource.
+	ErrNoResourcesForMaintainer = errors.New("no resources available for this maintainer")
+	ErrNoResourcesSelected      = errors.New("no resources selected")
+)
@@ -0,0 +1,65 @@
+package usecases
+
+import (
+	"errors"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+)
+
+// ExportBundle exports several resources together, failing when they define
+// the same rules, macros or lists. Resources may be selected by a former ID
+// listed in their aliases.
+type ExportBundle struct {
+	ResourceRepository resource.Repository
+	ResourceIDs        []string
+	Format             string
+	Options            resource.ExportOptions
+	// Downloads counts the exports delivered, it may be nil.
+	Downloads *DownloadCounter
+	exported  []string
+}
+
+func (useCase *ExportBundle) Execute() (*resource.Export, error) {
+	if len(useCase.ResourceIDs) == 0 {
+		return nil, ErrNoResourcesSelected
+	}
+
+	var resources []*resource.Resource
+	selected := map[string]bool{}
+	for _, id := range useCase.ResourceIDs {
+		if err := resource.ValidateID(id); err != nil {
+			return nil, err
+		}
+
+		res, err := useCase.ResourceRepository.FindById(id)
+		var moved *resource.MovedError
+		if errors.As(err, &moved) {
+			res, err = useCase.ResourceRepository.FindById(moved.To)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if selected[res.ID] {
+			continue
+		}
+		selected[res.ID] = true
+		resources = append(resources, res)
+	}
+
+	if err := resource.CheckConflicts(resources); err != nil {
+		return nil, err
+	}
+	export, err := resource.ExportResources(useCase.Format, resources, useCase.Options)
+	if err != nil {
+		return nil, err
+	}
+	for _, res := range resources {
+		useCase.exported = append(useCase.exported, res.ID)
+	}
+	return export, nil
+}
+
+// RecordDownload counts each resource exported as a download, once the
+// export was delivered to the client.
+func (useCase *ExportBundle) RecordDownload() {
+	useCase.Downloads.Record(useCase.Format, useCase.exported...)
+}
@@ -0,0 +1,87 @@
+package usecases
+
+import (
+	"errors"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func memoryResourceRepositoryWithBundledRules() resource.Repository {
+	return resource.NewMemoryRepository(
+		[]*resource.Resource{
+			{ID: "nginx", Rules: []*resource.FalcoRuleData{{Raw: "- macro: nginx\n  condition: proc.name = nginx\n"}}},
+			{ID: "traefik", Rules: []*resource.FalcoRuleData{{Raw: "- macro: traefik\n  condition: proc.name = traefik\n"}}},
+			{ID: "nginx-plus", Rules: []*resource.FalcoRuleData{{Raw: "- macro: nginx\n  condition: proc.name = nginx-plus\n"}}},
+		},
+	)
+}
+
+func TestExportsBundleOfResources(t *testing.T) {
+	useCase := ExportBundle{
+		ResourceRepository: memoryResourceRepositoryWithBundledRules(),
+		ResourceIDs:        []string{"nginx", "traefik", "Nginx"},
+		Format:             "custom-rules.yaml",
+	}
+
+	result, err := useCase.Execute()
+	expected := `customRules:
+  rules-nginx.yaml: |
+    - macro: nginx
+      condition: proc.name = nginx
+  rules-traefik.yaml: |
+    - macro: traefik
+      condition: proc.name = traefik
+`
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(result.Content))
+}
+
+func TestExportBundleSelectsResourcesByAlias(t *testing.T) {
+	useCase := ExportBundle{
+		ResourceRepository: resource.NewMemoryRepository([]*resource.Resource{
+			{ID: "nginx", Aliases: []string{"nginx-ingress"}, Rules: []*resource.FalcoRuleData{{Raw: "- macro: nginx\n  condition: proc.name = nginx\n"}}},
+		}),
+		ResourceIDs: []string{"nginx-ingress", "nginx"},
+		Format:      "custom-rules.yaml",
+	}
+
+	result, err := useCase.Execute()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "customRules:\n  rules-nginx.yaml: |\n    - macro: nginx\n      condition: proc.name = nginx\n", string(result.Content))
+}
+
+func TestExportBundleReturnsConflicts(t *testing.T) {
+	useCase := ExportBundle{
+		ResourceRepository: memoryResourceRepositoryWithBundledRules(),
+		ResourceIDs:        []string{"nginx", "nginx-plus"},
+		Format:             "rules.yaml",
+	}
+
+	_, err := useCase.Execute()
+
+	assert.IsType(t, &resource.ConflictError{}, err)
+}
+
+func TestExportBundleReturnsNotFound(t *testing.T) {
+	useCase := ExportBundle{
+		ResourceRepository: memoryResourceRepositoryWithBundledRules(),
+		ResourceIDs:        []string{"nginx", "notFound"},
+	}
+
+	_, err := useCase.Execute()
+
+	assert.True(t, errors.Is(err, resource.ErrNotFound))
+}
+
+func TestExportBundleRequiresResources(t *testing.T) {
+	useCase := ExportBundle{
+		ResourceRepository: memoryResourceRepositoryWithBundledRules(),
+	}
+
+	_, err := useCase.Execute()
+
+	assert.Equal(t, ErrNoResourcesSelected, err)
+}
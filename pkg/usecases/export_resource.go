@@ -0,0 +1,49 @@
+package usecases
+
+import "github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+
+type ExportResource struct {
+	ResourceRepository resource.Repository
+	ResourceID         string
+	// Version selects the version to export, the latest one when empty.
+	Version string
+	Format  string
+	Options resource.ExportOptions
+	// Downloads counts the exports delivered, it may be nil.
+	Downloads *DownloadCounter
+	exported  string
+}
+
+func (useCase *ExportResource) Execute() (*resource.Export, error) {
+	if err := resource.ValidateID(useCase.ResourceID); err != nil {
+		return nil, err
+	}
+	res, err := useCase.findResource()
+	if err != nil {
+		return nil, err
+	}
+	export, err := resource.ExportResources(useCase.Format, []*resource.Resource{res}, useCase.Options)
+	if err != nil {
+		return nil, err
+	}
+	useCase.exported = res.ID
+	return export, nil
+}
+
+// RecordDownload counts the resource exported as a download, once the export
+// was delivered to the client.
+func (useCase *ExportResource) RecordDownload() {
+	if useCase.exported != "" {
+		useCase.Downloads.Record(useCase.Format, useCase.exported)
+	}
+}
+
+func (useCase *ExportResource) findResource() (*resource.Resource, error) {
+	if useCase.Version == "" {
+		return useCase.ResourceRepository.FindById(useCase.ResourceID)
+	}
+	if err := resource.ValidateVersion(useCase.Version); err != nil {
+		return nil, err
+	}
+	return useCase.ResourceRepository.FindVersion(useCase.ResourceID, useCase.Version)
+}
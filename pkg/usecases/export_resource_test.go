@@ -0,0 +1,100 @@
+package usecases
+
+import (
+	"errors"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func memoryResourceRepositoryWithRules() resource.Repository {
+	return resource.NewMemoryRepository(
+		[]*resource.Resource{
+			{
+				ID:     "nginx",
+				Kind:   resource.FALCO_RULES,
+				Name:   "Falco profile for Nginx",
+				Vendor: "Nginx",
+				Rules: []*resource.FalcoRuleData{
+					{Raw: "nginxRule"},
+				},
+			},
+			{
+				ID:     "traefik",
+				Kind:   "GrafanaDashboard",
+				Name:   "Grafana Dashboard for Traefik",
+				Vendor: "Traefik",
+				Rules: []*resource.FalcoRuleData{
+					{Raw: "traefikRule"},
+				},
+			},
+		},
+	)
+}
+
+func TestReturnsFalcoRulesForHelmChart(t *testing.T) {
+	useCase := ExportResource{
+		ResourceRepository: memoryResourceRepositoryWithRules(),
+		ResourceID:         "nginx",
+		Format:             "custom-rules.yaml",
+	}
+
+	result, _ := useCase.Execute()
+	expected := `customRules:
+  rules-nginx.yaml: nginxRule
+`
+
+	assert.Equal(t, expected, string(result.Content))
+}
+
+func TestExportResourcePassesOptionsToTheExporter(t *testing.T) {
+	useCase := ExportResource{
+		ResourceRepository: memoryResourceRepositoryWithRules(),
+		ResourceID:         "nginx",
+		Format:             "configmap.yaml",
+		Options:            resource.ExportOptions{"name": "nginx-rules"},
+	}
+
+	result, err := useCase.Execute()
+
+	assert.NoError(t, err)
+	assert.Contains(t, string(result.Content), "name: nginx-rules\n")
+}
+
+func TestFalcoRulesForHelmChartReturnsNotFound(t *testing.T) {
+	useCase := ExportResource{
+		ResourceRepository: memoryResourceRepositoryWithRules(),
+		ResourceID:         "notFound",
+		Format:             "custom-rules.yaml",
+	}
+
+	_, err := useCase.Execute()
+
+	assert.Error(t, err)
+}
+
+func TestExportResourceFailsWithUnknownFormats(t *testing.T) {
+	useCase := ExportResource{
+		ResourceRepository: memoryResourceRepositoryWithRules(),
+		ResourceID:         "nginx",
+		Format:             "rules.json",
+	}
+
+	_, err := useCase.Execute()
+
+	assert.True(t, errors.Is(err, resource.ErrUnknownFormat))
+}
+
+func TestExportResourceSelectsAVersion(t *testing.T) {
+	useCase := ExportResource{
+		ResourceRepository: memoryResourceRepositoryWithVersions(),
+		ResourceID:         "nginx",
+		Version:            "1.0.0",
+		Format:             "custom-rules.yaml",
+	}
+
+	result, err := useCase.Execute()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "customRules:\n  rules-nginx.yaml: nginxRule\n", string(result.Content))
+}
@@ -1,19 +1,42 @@
 package usecases
 
 import (
+	"database/sql"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/database"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/git"
 	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
 	"github.com/falcosecurity/cloud-native-security-hub/pkg/vendor"
+	"io/ioutil"
 	"log"
 	"os"
+	"strconv"
+	"time"
 )
 
 type Factory interface {
-	NewRetrieveAllResourcesUseCase() *RetrieveAllResources
+	NewRetrieveAllResourcesUseCase(options ResourceListOptions) *RetrieveAllResources
 	NewRetrieveOneResourceUseCase(resourceID string) *RetrieveOneResource
-	NewRetrieveFalcoRulesForHelmChartUseCase(resourceID string) *RetrieveFalcoRulesForHelmChart
-	NewRetrieveAllVendorsUseCase() *RetrieveAllVendors
+	NewRetrieveResourcesMatchingQueryUseCase(query string) *RetrieveResourcesMatchingQuery
+	NewRetrieveResourceVersionsUseCase(resourceID string) *RetrieveResourceVersions
+	NewRetrieveResourceVersionUseCase(resourceID, version string) *RetrieveResourceVersion
+	NewCompareResourceVersionsUseCase(resourceID, from, to string) *CompareResourceVersions
+	NewExportResourceUseCase(resourceID, version, format string, options resource.ExportOptions) *ExportResource
+	NewExportBundleUseCase(resourceIDs []string, format string, options resource.ExportOptions) *ExportBundle
+	NewCreateResourceUseCase(res *resource.Resource) *CreateResource
+	NewUpdateResourceUseCase(resourceID string, res *resource.Resource) *UpdateResource
+	NewDeleteResourceUseCase(resourceID string) *DeleteResource
+	NewRetrieveResourceDependenciesUseCase(resourceID string) *RetrieveResourceDependencies
+	NewRetrieveKeywordsUseCase() *RetrieveKeywords
+	NewRetrieveResourcesWithKeywordUseCase(keyword string, options ResourceListOptions) *RetrieveResourcesWithKeyword
+	NewRetrieveAllMaintainersUseCase() *RetrieveAllMaintainers
+	NewRetrieveAllResourcesFromMaintainerUseCase(maintainerID string, options ResourceListOptions) *RetrieveAllResourcesFromMaintainer
+	NewRetrieveAllVendorsUseCase(options VendorListOptions) *RetrieveAllVendors
 	NewRetrieveOneVendorUseCase(vendorID string) *RetrieveOneVendor
-	NewRetrieveAllResourcesFromVendorUseCase(vendorID string) *RetrieveAllResourcesFromVendor
+	NewRetrieveAllResourcesFromVendorUseCase(vendorID string, options ResourceListOptions) *RetrieveAllResourcesFromVendor
+	NewRetrieveDiagnosticsUseCase() *RetrieveDiagnostics
+	NewRetrieveLastModifiedUseCase() *RetrieveLastModified
+	NewRetrieveRepositoryStatsUseCase() *RetrieveRepositoryStats
+	NewRetrieveDownloadsUseCase() *RetrieveDownloads
 
 	NewResourcesRepository() resource.Repository
 	NewVendorRepository() vendor.Repository
@@ -21,19 +44,32 @@ type Factory interface {
 
 func NewFactory() Factory {
 	factory := &factory{}
+	factory.taxonomy = newTaxonomy()
 	factory.resourceRepository = factory.NewResourcesRepository()
 	factory.vendorRepository = factory.NewVendorRepository()
+	factory.falcoDefaults = newFalcoDefaults()
+	factory.downloads = NewDownloadCounter()
+	if strictMode() {
+		factory.failOnDiagnostics()
+	}
 	return factory
 }
 
 type factory struct {
 	vendorRepository   vendor.Repository
 	resourceRepository resource.Repository
+	falcoDefaults      *resource.FalcoDefaults
+	downloads          *DownloadCounter
+	taxonomy           *resource.Taxonomy
+	gitSource          *git.Repository
+	db                 *sql.DB
 }
 
-func (f *factory) NewRetrieveAllResourcesUseCase() *RetrieveAllResources {
+func (f *factory) NewRetrieveAllResourcesUseCase(options ResourceListOptions) *RetrieveAllResources {
 	return &RetrieveAllResources{
 		ResourceRepository: f.resourceRepository,
+		Downloads:          f.downloads,
+		Options:            options,
 	}
 }
 
@@ -44,16 +80,125 @@ func (f *factory) NewRetrieveOneResourceUseCase(resourceID string) *RetrieveOneR
 	}
 }
 
-func (f *factory) NewRetrieveFalcoRulesForHelmChartUseCase(resourceID string) *RetrieveFalcoRulesForHelmChart {
-	return &RetrieveFalcoRulesForHelmChart{
+func (f *factory) NewRetrieveResourcesMatchingQueryUseCase(query string) *RetrieveResourcesMatchingQuery {
+	return &RetrieveResourcesMatchingQuery{
+		ResourceRepository: f.resourceRepository,
+		Query:              query,
+	}
+}
+
+func (f *factory) NewRetrieveResourceVersionsUseCase(resourceID string) *RetrieveResourceVersions {
+	return &RetrieveResourceVersions{
+		ResourceRepository: f.resourceRepository,
+		ResourceID:         resourceID,
+	}
+}
+
+func (f *factory) NewRetrieveResourceVersionUseCase(resourceID, version string) *RetrieveResourceVersion {
+	return &RetrieveResourceVersion{
+		ResourceRepository: f.resourceRepository,
+		ResourceID:         resourceID,
+		Version:            version,
+	}
+}
+
+func (f *factory) NewCompareResourceVersionsUseCase(resourceID, from, to string) *CompareResourceVersions {
+	return &CompareResourceVersions{
+		ResourceRepository: f.resourceRepository,
+		ResourceID:         resourceID,
+		From:               from,
+		To:                 to,
+	}
+}
+
+func (f *factory) NewExportResourceUseCase(resourceID, version, format string, options resource.ExportOptions) *ExportResource {
+	return &ExportResource{
+		ResourceRepository: f.resourceRepository,
+		ResourceID:         resourceID,
+		Version:            version,
+		Format:             format,
+		Options:            options,
+		Downloads:          f.downloads,
+	}
+}
+
+func (f *factory) NewExportBundleUseCase(resourceIDs []string, format string, options resource.ExportOptions) *ExportBundle {
+	return &ExportBundle{
+		ResourceRepository: f.resourceRepository,
+		ResourceIDs:        resourceIDs,
+		Format:             format,
+		Options:            options,
+		Downloads:          f.downloads,
+	}
+}
+
+func (f *factory) NewCreateResourceUseCase(res *resource.Resource) *CreateResource {
+	return &CreateResource{
+		ResourceRepository: f.resourceRepository,
+		FalcoDefaults:      f.falcoDefaults,
+		Resource:           res,
+	}
+}
+
+func (f *factory) NewUpdateResourceUseCase(resourceID string, res *resource.Resource) *UpdateResource {
+	return &UpdateResource{
+		ResourceRepository: f.resourceRepository,
+		FalcoDefaults:      f.falcoDefaults,
+		ResourceID:         resourceID,
+		Resource:           res,
+	}
+}
+
+func (f *factory) NewDeleteResourceUseCase(resourceID string) *DeleteResource {
+	return &DeleteResource{
+		ResourceRepository: f.resourceRepository,
+		ResourceID:         resourceID,
+	}
+}
+
+func (f *factory) NewRetrieveResourceDependenciesUseCase(resourceID string) *RetrieveResourceDependencies {
+	return &RetrieveResourceDependencies{
 		ResourceRepository: f.resourceRepository,
+		FalcoDefaults:      f.falcoDefaults,
 		ResourceID:         resourceID,
 	}
 }
 
-func (f *factory) NewRetrieveAllVendorsUseCase() *RetrieveAllVendors {
+func (f *factory) NewRetrieveKeywordsUseCase() *RetrieveKeywords {
+	return &RetrieveKeywords{
+		ResourceRepository: f.resourceRepository,
+	}
+}
+
+func (f *factory) NewRetrieveResourcesWithKeywordUseCase(keyword string, options ResourceListOptions) *RetrieveResourcesWithKeyword {
+	return &RetrieveResourcesWithKeyword{
+		ResourceRepository: f.resourceRepository,
+		Taxonomy:           f.taxonomy,
+		Downloads:          f.downloads,
+		Keyword:            keyword,
+		Options:            options,
+	}
+}
+
+func (f *factory) NewRetrieveAllMaintainersUseCase() *RetrieveAllMaintainers {
+	return &RetrieveAllMaintainers{
+		ResourceRepository: f.resourceRepository,
+	}
+}
+
+func (f *factory) NewRetrieveAllResourcesFromMaintainerUseCase(maintainerID string, options ResourceListOptions) *RetrieveAllResourcesFromMaintainer {
+	return &RetrieveAllResourcesFromMaintainer{
+		ResourceRepository: f.resourceRepository,
+		Downloads:          f.downloads,
+		MaintainerID:       maintainerID,
+		Options:            options,
+	}
+}
+
+func (f *factory) NewRetrieveAllVendorsUseCase(options VendorListOptions) *RetrieveAllVendors {
 	return &RetrieveAllVendors{
 		VendorRepository: f.vendorRepository,
+		Options:          options,
 	}
 }
 
@@ -64,38 +209,243 @@ func (f *factory) NewRetrieveOneVendorUseCase(vendorID string) *RetrieveOneVendo
 	}
 }
 
-func (f *factory) NewRetrieveAllResourcesFromVendorUseCase(vendorID string) *RetrieveAllResourcesFromVendor {
+func (f *factory) NewRetrieveAllResourcesFromVendorUseCase(vendorID string, options ResourceListOptions) *RetrieveAllResourcesFromVendor {
 	return &RetrieveAllResourcesFromVendor{
 		VendorID:           vendorID,
 		VendorRepository:   f.vendorRepository,
 		ResourceRepository: f.resourceRepository,
+		Downloads:          f.downloads,
+		Options:            options,
+	}
+}
+
+func (f *factory) NewRetrieveDiagnosticsUseCase() *RetrieveDiagnostics {
+	return &RetrieveDiagnostics{
+		ResourceRepository: f.resourceRepository,
+		VendorRepository:   f.vendorRepository,
+	}
+}
+
+func (f *factory) NewRetrieveLastModifiedUseCase() *RetrieveLastModified {
+	return &RetrieveLastModified{
+		ResourceRepository: f.resourceRepository,
+		VendorRepository:   f.vendorRepository,
+	}
+}
+
+func (f *factory) NewRetrieveRepositoryStatsUseCase() *RetrieveRepositoryStats {
+	return &RetrieveRepositoryStats{
+		ResourceRepository: f.resourceRepository,
+		VendorRepository:   f.vendorRepository,
 	}
 }
 
+func (f *factory) NewRetrieveDownloadsUseCase() *RetrieveDownloads {
+	return &RetrieveDownloads{Downloads: f.downloads}
+}
+
 func (f *factory) NewResourcesRepository() resource.Repository {
+	if db := f.newDatabase(); db != nil {
+		repo := resource.FromDatabase(db)
+		repo.UseTaxonomy(f.taxonomy)
+		return repo
+	}
 	resourcesPath, ok := os.LookupEnv("RESOURCES_PATH")
 	if !ok {
 		log.Println("The RESOURCES_PATH env var is not set")
 		os.Exit(1)
 	}
+	if source := f.newGitSource(); source != nil {
+		repo, err := resource.FromGit(source, gitRef(), resourcesPath)
+		if err != nil {
+			log.Printf("the resource repository of type git cannot be read: %s", err)
+			os.Exit(1)
+		}
+		repo.UseTaxonomy(f.taxonomy)
+		watch(repo)
+		return repo
+	}
 	repo, err := resource.FromPath(resourcesPath)
 	if err != nil {
 		log.Println("the resource repository of type file does not exist")
 		os.Exit(1)
 	}
+	repo.UseTaxonomy(f.taxonomy)
+	watch(repo)
 	return repo
 }
 
 func (f *factory) NewVendorRepository() vendor.Repository {
+	if db := f.newDatabase(); db != nil {
+		return vendor.FromDatabase(db)
+	}
 	vendorPath, ok := os.LookupEnv("VENDOR_PATH")
 	if !ok {
 		log.Println("The VENDOR_PATH env var is not set")
 		os.Exit(1)
 	}
+	if source := f.newGitSource(); source != nil {
+		repo, err := vendor.FromGit(source, gitRef(), vendorPath)
+		if err != nil {
+			log.Printf("the vendor repository of type git cannot be read: %s", err)
+			os.Exit(1)
+		}
+		watch(repo)
+		return repo
+	}
 	repo, err := vendor.FromPath(vendorPath)
 	if err != nil {
 		log.Println("the resource repository of type file does not exist")
 		os.Exit(1)
 	}
+	watch(repo)
 	return repo
 }
+
+// newDatabase connects to the database set in DATABASE_URL the first time it
+// is called, and returns nil when resources are not stored in a database.
+func (f *factory) newDatabase() *sql.DB {
+	if f.db != nil {
+		return f.db
+	}
+	dataSource, ok := os.LookupEnv("DATABASE_URL")
+	if !ok {
+		return nil
+	}
+
+	driver, ok := os.LookupEnv("DATABASE_DRIVER")
+	if !ok {
+		driver = "postgres"
+	}
+
+	db, err := database.Open(driver, dataSource)
+	if err != nil {
+		log.Printf("unable to open the %s database: %s", driver, err)
+		os.Exit(1)
+	}
+	f.db = db
+	return db
+}
+
+// newGitSource clones the repository set in GIT_REPOSITORY the first time it
+// is called, and returns nil when resources are not read from git.
+func (f *factory) newGitSource() *git.Repository {
+	if f.gitSource != nil {
+		return f.gitSource
+	}
+	url, ok := os.LookupEnv("GIT_REPOSITORY")
+	if !ok {
+		return nil
+	}
+
+	clonePath, ok := os.LookupEnv("GIT_CLONE_PATH")
+	if !ok {
+		tempDir, err := ioutil.TempDir("", "securityhub")
+		if err != nil {
+			log.Printf("unable to create a directory to clone the git repository: %s", err)
+			os.Exit(1)
+		}
+		clonePath = tempDir
+	}
+
+	source, err := git.Clone(url, clonePath)
+	if err != nil {
+		log.Printf("unable to clone the git repository: %s", err)
+		os.Exit(1)
+	}
+	f.gitSource = source
+	return source
+}
+
+// newFalcoDefaults reads the macros and lists rules can use without defining
+// them from the Falco rules file set in FALCO_RULES_PATH, falling back to the
+// partial list of NewFalcoDefaults, which doesn't reject unknown macros.
+func newFalcoDefaults() *resource.FalcoDefaults {
+	path, ok := os.LookupEnv("FALCO_RULES_PATH")
+	if !ok {
+		return resource.NewFalcoDefaults()
+	}
+	defaults, err := resource.FalcoDefaultsFromFile(path)
+	if err != nil {
+		log.Printf("unable to read the Falco rules in %s: %s", path, err)
+		os.Exit(1)
+	}
+	return defaults
+}
+
+// newTaxonomy reads the synonyms of the keywords from the file set in
+// TAXONOMY_PATH, leaving keywords untouched when it is not set.
+func newTaxonomy() *resource.Taxonomy {
+	path, ok := os.LookupEnv("TAXONOMY_PATH")
+	if !ok {
+		return nil
+	}
+	taxonomy, err := resource.TaxonomyFromFile(path)
+	if err != nil {
+		log.Printf("unable to read the keyword taxonomy: %s", err)
+		os.Exit(1)
+	}
+	return taxonomy
+}
+
+// failOnDiagnostics stops the server when the resources or vendors have any
+// problem, instead of serving the ones which could be loaded.
+func (f *factory) failOnDiagnostics() {
+	diagnostics, err := f.NewRetrieveDiagnosticsUseCase().Execute()
+	if err != nil {
+		log.Printf("unable to check the resources and vendors: %s", err)
+		os.Exit(1)
+	}
+	if len(diagnostics) == 0 {
+		return
+	}
+
+	for _, diagnostic := range diagnostics {
+		log.Printf("%s: %s", diagnostic.Code, diagnostic)
+	}
+	log.Printf("refusing to start in strict mode, %d problems found in the resources and vendors", len(diagnostics))
+	os.Exit(1)
+}
+
+func strictMode() bool {
+	value, ok := os.LookupEnv("STRICT_MODE")
+	if !ok {
+		return false
+	}
+	strict, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Println("The STRICT_MODE env var must be true or false")
+		os.Exit(1)
+	}
+	return strict
+}
+
+func gitRef() string {
+	if ref, ok := os.LookupEnv("GIT_REF"); ok {
+		return ref
+	}
+	return "master"
+}
+
+type watcher interface {
+	Watch(interval time.Duration) (stop func())
+}
+
+func watch(repo watcher) {
+	if interval, ok := reloadInterval(); ok {
+		repo.Watch(interval)
+	}
+}
+
+func reloadInterval() (time.Duration, bool) {
+	value, ok := os.LookupEnv("RELOAD_INTERVAL")
+	if !ok {
+		return 0, false
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		log.Println("The RELOAD_INTERVAL env var must be a positive duration, like 30s")
+		os.Exit(1)
+	}
+	return interval, true
+}
@@ -0,0 +1,242 @@
+package usecases
+
+import (
+	"fmt"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/slug"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/vendor"
+	"sort"
+	"strings"
+)
+
+// MaxPageSize is the largest limit a page can be asked for. Without a limit,
+// the page holds every item.
+const MaxPageSize = 100
+
+type SortField string
+
+const (
+	SORT_BY_NAME       SortField = "name"
+	SORT_BY_UPDATED    SortField = "updated"
+	SORT_BY_POPULARITY SortField = "popularity"
+)
+
+// Pagination selects a page of a list. A zero limit returns every item from
+// the offset on.
+type Pagination struct {
+	Offset int
+	Limit  int
+}
+
+func (p Pagination) validate() error {
+	if p.Offset < 0 {
+		return fmt.Errorf("%w: the offset must not be negative", ErrInvalidListOptions)
+	}
+	if p.Limit < 0 || p.Limit > MaxPageSize {
+		return fmt.Errorf("%w: the limit must be between 0 and %d", ErrInvalidListOptions, MaxPageSize)
+	}
+	return nil
+}
+
+// bounds returns the range of a list of total items within the page.
+func (p Pagination) bounds(total int) (start, end int) {
+	start, end = p.Offset, total
+	if start > total {
+		start = total
+	}
+	if p.Limit > 0 && start+p.Limit < end {
+		end = start + p.Limit
+	}
+	return
+}
+
+// ResourceFilter keeps the resources matching every field set. Values are
+// compared ignoring case.
+type ResourceFilter struct {
+	Kind    string
+	Vendor  string
+	Keyword string
+	// Maintainer matches the name or the email of a maintainer.
+	Maintainer string
+}
+
+func (f ResourceFilter) matches(res *resource.Resource) bool {
+	if f.Kind != "" && !strings.EqualFold(string(res.Kind), f.Kind) {
+		return false
+	}
+	if f.Vendor != "" && !strings.EqualFold(res.Vendor, f.Vendor) && slug.Make(res.Vendor) != strings.ToLower(f.Vendor) {
+		return false
+	}
+	if f.Keyword != "" && !anyEqualFold(res.Keywords, f.Keyword) {
+		return false
+	}
+	if f.Maintainer != "" {
+		found := false
+		for _, maintainer := range res.Maintainers {
+			if maintainer != nil && (strings.EqualFold(maintainer.Name, f.Maintainer) || strings.EqualFold(maintainer.Email, f.Maintainer)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// ResourceListOptions filter, sort and paginate a list of resources. Sort
+// names a SortField, in descending order when prefixed with "-", and keeps
+// the order of the repository when empty.
+type ResourceListOptions struct {
+	Filter     ResourceFilter
+	Sort       string
+	Pagination Pagination
+}
+
+// ResourcePage is a page of a list of resources, with the number of
+// resources matching the filter across every page.
+type ResourcePage struct {
+	Resources  []*resource.Resource
+	Total      int
+	Pagination Pagination
+}
+
+// list applies the options to the resources. Downloads ranks the resources
+// by popularity, the downloads counted by this server process since it
+// started, and may be nil when nothing was downloaded.
+func (o ResourceListOptions) list(resources []*resource.Resource, downloads *DownloadCounter) (*ResourcePage, error) {
+	field, descending, err := parseSort(o.Sort, SORT_BY_NAME, SORT_BY_UPDATED, SORT_BY_POPULARITY)
+	if err != nil {
+		return nil, err
+	}
+	if err := o.Pagination.validate(); err != nil {
+		return nil, err
+	}
+	if field == SORT_BY_UPDATED && !dated(resources) {
+		return nil, fmt.Errorf("%w: the resources can only be sorted by %s when they are read from git", ErrInvalidListOptions, SORT_BY_UPDATED)
+	}
+
+	matching := []*resource.Resource{}
+	for _, res := range resources {
+		if o.Filter.matches(res) {
+			matching = append(matching, res)
+		}
+	}
+
+	var less func(a, b *resource.Resource) bool
+	switch field {
+	case SORT_BY_NAME:
+		less = func(a, b *resource.Resource) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) }
+	case SORT_BY_UPDATED:
+		less = func(a, b *resource.Resource) bool { return updatedAt(a) < updatedAt(b) }
+	case SORT_BY_POPULARITY:
+		less = func(a, b *resource.Resource) bool { return downloads.Count(a.ID) < downloads.Count(b.ID) }
+	}
+	if less != nil {
+		sort.SliceStable(matching, func(i, j int) bool {
+			if descending {
+				return less(matching[j], matching[i])
+			}
+			return less(matching[i], matching[j])
+		})
+	}
+
+	start, end := o.Pagination.bounds(len(matching))
+	return &ResourcePage{
+		Resources:  matching[start:end],
+		Total:      len(matching),
+		Pagination: o.Pagination,
+	}, nil
+}
+
+// dated tells whether every resource has the date it was last changed, which
+// only the ones read from git have.
+func dated(resources []*resource.Resource) bool {
+	for _, res := range resources {
+		if res.Commit == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// updatedAt returns when the resource was last changed, as a Unix time in
+// nanoseconds.
+func updatedAt(res *resource.Resource) int64 {
+	return res.Commit.Date.UnixNano()
+}
+
+// VendorListOptions sort and paginate a list of vendors, which can only be
+// sorted by name.
+type VendorListOptions struct {
+	Sort       string
+	Pagination Pagination
+}
+
+// VendorPage is a page of a list of vendors, with the number of vendors
+// across every page.
+type VendorPage struct {
+	Vendors    []*vendor.Vendor
+	Total      int
+	Pagination Pagination
+}
+
+func (o VendorListOptions) list(vendors []*vendor.Vendor) (*VendorPage, error) {
+	field, descending, err := parseSort(o.Sort, SORT_BY_NAME)
+	if err != nil {
+		return nil, err
+	}
+	if err := o.Pagination.validate(); err != nil {
+		return nil, err
+	}
+
+	sorted := append([]*vendor.Vendor{}, vendors...)
+	if field == SORT_BY_NAME {
+		sort.SliceStable(sorted, func(i, j int) bool {
+			if descending {
+				i, j = j, i
+			}
+			return strings.ToLower(sorted[i].Name) < strings.ToLower(sorted[j].Name)
+		})
+	}
+
+	start, end := o.Pagination.bounds(len(sorted))
+	return &VendorPage{
+		Vendors:    sorted[start:end],
+		Total:      len(sorted),
+		Pagination: o.Pagination,
+	}, nil
+}
+
+// parseSort reads a sort parameter like "-updated", checking the field is
+// one of the supported ones.
+func parseSort(value string, supported ...SortField) (field SortField, descending bool, err error) {
+	if value == "" {
+		return "", false, nil
+	}
+	if strings.HasPrefix(value, "-") {
+		descending = true
+		value = value[1:]
+	}
+	for _, candidate := range supported {
+		if SortField(value) == candidate {
+			return candidate, descending, nil
+		}
+	}
+
+	var names []string
+	for _, candidate := range supported {
+		names = append(names, string(candidate))
+	}
+	return "", false, fmt.Errorf("%w: cannot sort by %q, use one of %s", ErrInvalidListOptions, value, strings.Join(names, ", "))
+}
+
+func anyEqualFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,17 @@
+package usecases
+
+import "github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+
+// RetrieveAllMaintainers lists the maintainers of every resource, each one
+// once even when they maintain several resources.
+type RetrieveAllMaintainers struct {
+	ResourceRepository resource.Repository
+}
+
+func (useCase *RetrieveAllMaintainers) Execute() ([]*resource.MaintainerSummary, error) {
+	resources, err := useCase.ResourceRepository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	return resource.SummarizeMaintainers(resources), nil
+}
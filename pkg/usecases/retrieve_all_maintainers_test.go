@@ -0,0 +1,19 @@
+package usecases
+
+import (
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRetrieveAllMaintainers(t *testing.T) {
+	useCase := RetrieveAllMaintainers{ResourceRepository: memoryResourceRepositoryToList()}
+
+	maintainers, err := useCase.Execute()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*resource.MaintainerSummary{
+		{ID: "bencer@sysdig.com", Name: "bencer", Email: "bencer@sysdig.com", Resources: []string{"apache", "deny-all"}},
+		{ID: "nestor@sysdig.com", Name: "nestor", Email: "nestor@sysdig.com", Resources: []string{"nginx"}},
+	}, maintainers)
+}
@@ -6,8 +6,14 @@ import (
 
 type RetrieveAllResources struct {
 	ResourceRepository resource.Repository
+	Downloads          *DownloadCounter
+	Options            ResourceListOptions
 }
 
-func (useCase *RetrieveAllResources) Execute() ([]*resource.Resource, error) {
-	return useCase.ResourceRepository.FindAll()
+func (useCase *RetrieveAllResources) Execute() (*ResourcePage, error) {
+	resources, err := useCase.ResourceRepository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	return useCase.Options.list(resources, useCase.Downloads)
 }
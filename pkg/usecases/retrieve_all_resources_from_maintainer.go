@@ -0,0 +1,39 @@
+package usecases
+
+import (
+	"fmt"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"strings"
+)
+
+// RetrieveAllResourcesFromMaintainer lists the resources maintained by the
+// maintainer with the given ID, their email or the slug of their name.
+type RetrieveAllResourcesFromMaintainer struct {
+	ResourceRepository resource.Repository
+	Downloads          *DownloadCounter
+	MaintainerID       string
+	Options            ResourceListOptions
+}
+
+func (useCase *RetrieveAllResourcesFromMaintainer) Execute() (*ResourcePage, error) {
+	if strings.TrimSpace(useCase.MaintainerID) == "" {
+		return nil, fmt.Errorf("%w: the maintainer must not be empty", ErrInvalidQuery)
+	}
+
+	resources, err := useCase.ResourceRepository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var maintained []*resource.Resource
+	for _, res := range resources {
+		if res.MaintainedBy(useCase.MaintainerID) {
+			maintained = append(maintained, res)
+		}
+	}
+	if len(maintained) == 0 {
+		return nil, fmt.Errorf("maintainer %q: %w", useCase.MaintainerID, ErrNoResourcesForMaintainer)
+	}
+
+	return useCase.Options.list(maintained, useCase.Downloads)
+}
@@ -0,0 +1,31 @@
+package usecases
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRetrieveAllResourcesFromMaintainer(t *testing.T) {
+	useCase := RetrieveAllResourcesFromMaintainer{
+		ResourceRepository: memoryResourceRepositoryToList(),
+		MaintainerID:       "BENCER@sysdig.com",
+		Options:            ResourceListOptions{Filter: ResourceFilter{Kind: "FalcoRules"}},
+	}
+
+	page, err := useCase.Execute()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"apache"}, idsOf(page.Resources))
+}
+
+func TestRetrieveAllResourcesFromUnknownMaintainerReturnsNotFound(t *testing.T) {
+	useCase := RetrieveAllResourcesFromMaintainer{
+		ResourceRepository: memoryResourceRepositoryToList(),
+		MaintainerID:       "nobody@sysdig.com",
+	}
+
+	_, err := useCase.Execute()
+
+	assert.True(t, errors.Is(err, ErrNoResourcesForMaintainer))
+}
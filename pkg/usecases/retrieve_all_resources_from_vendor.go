@@ -11,30 +11,40 @@ type RetrieveAllResourcesFromVendor struct {
 	VendorID           string
 	VendorRepository   vendor.Repository
 	ResourceRepository resource.Repository
+	Downloads          *DownloadCounter
+	Options            ResourceListOptions
 }
 
-func (useCase *RetrieveAllResourcesFromVendor) Execute() (res []*resource.Resource, err error) {
+func (useCase *RetrieveAllResourcesFromVendor) Execute() (*ResourcePage, error) {
+	if err := vendor.ValidateID(useCase.VendorID); err != nil {
+		return nil, err
+	}
 	vendor, err := useCase.VendorRepository.FindById(useCase.VendorID)
 	if err != nil {
-		return
+		return nil, err
 	}
-	vendorName := strings.ToLower(vendor.Name)
 
 	resources, err := useCase.ResourceRepository.FindAll()
 	if err != nil {
-		return
+		return nil, err
 	}
 
+	var res []*resource.Resource
 	for _, r := range resources {
-		resourceVendorName := strings.ToLower(r.Vendor)
-		if vendorName == resourceVendorName {
+		if suppliedBy(r, vendor) {
 			res = append(res, r)
 		}
 	}
 
 	if len(res) == 0 {
-		err = fmt.Errorf("no resources available for this vendor")
+		return nil, fmt.Errorf("vendor %q: %w", useCase.VendorID, ErrNoResourcesForVendor)
 	}
 
-	return
+	return useCase.Options.list(res, useCase.Downloads)
+}
+
+// suppliedBy tells whether the resource is assigned to the vendor, which
+// resources refer to by name or by ID, ignoring case.
+func suppliedBy(res *resource.Resource, v *vendor.Vendor) bool {
+	return strings.EqualFold(res.Vendor, v.Name) || v.ID != "" && strings.EqualFold(res.Vendor, v.ID)
 }
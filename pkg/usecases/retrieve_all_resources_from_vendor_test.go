@@ -1,6 +1,7 @@
 package usecases
 
 import (
+	"errors"
 	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
 	"github.com/falcosecurity/cloud-native-security-hub/pkg/vendor"
 	"github.com/stretchr/testify/assert"
@@ -48,14 +49,14 @@ func TestReturnsAllResourcesFromVendor(t *testing.T) {
 		VendorRepository:   memoryVendorRepositoryFromVendor(),
 	}
 
-	resources, _ := useCase.Execute()
+	page, _ := useCase.Execute()
 
 	assert.Equal(t, []*resource.Resource{
 		{
 			Name:   "Falco profile for Nginx",
 			Vendor: "Nginx",
 		},
-	}, resources)
+	}, page.Resources)
 }
 
 func TestReturnsVendorNotFoundResourcesFromVendor(t *testing.T) {
@@ -79,5 +80,18 @@ func TestReturnsResourcesNotFoundResourcesFromVendor(t *testing.T) {
 
 	_, err := useCase.Execute()
 
-	assert.Error(t, err) //vendor exists but has no resources
+	assert.True(t, errors.Is(err, ErrNoResourcesForVendor)) //vendor exists but has no resources
+}
+
+func TestReturnsResourcesReferringToTheVendorByID(t *testing.T) {
+	useCase := RetrieveAllResourcesFromVendor{
+		VendorID:           "apache",
+		ResourceRepository: resource.NewMemoryRepository([]*resource.Resource{{ID: "apache", Vendor: "apache"}}),
+		VendorRepository:   vendor.NewMemoryRepository([]*vendor.Vendor{{ID: "apache", Name: "Apache Foundation"}}),
+	}
+
+	page, err := useCase.Execute()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*resource.Resource{{ID: "apache", Vendor: "apache"}}, page.Resources)
 }
@@ -1,9 +1,11 @@
 package usecases
 
 import (
+	"errors"
 	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func TestReturnsAllResources(t *testing.T) {
@@ -16,10 +18,160 @@ func TestReturnsAllResources(t *testing.T) {
 
 	useCase := RetrieveAllResources{ResourceRepository: resourceRepository}
 
-	resources, _ := useCase.Execute()
+	page, _ := useCase.Execute()
 
 	assert.Equal(t, []*resource.Resource{
 		{Name: "Falco profile for Nginx"},
 		{Name: "Falco profile for Grafana"},
-	}, resources)
+	}, page.Resources)
+}
+
+func memoryResourceRepositoryToList() resource.Repository {
+	return resource.NewMemoryRepository(
+		[]*resource.Resource{
+			{
+				ID:          "nginx",
+				Kind:        resource.FALCO_RULES,
+				Name:        "Nginx",
+				Vendor:      "Nginx",
+				Keywords:    []string{"web"},
+				Maintainers: []*resource.Maintainer{{Name: "nestor", Email: "nestor@sysdig.com"}},
+				Commit:      &resource.Commit{Date: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)},
+			},
+			{
+				ID:          "apache",
+				Kind:        resource.FALCO_RULES,
+				Name:        "Apache",
+				Vendor:      "Apache Software Foundation",
+				Keywords:    []string{"Web"},
+				Maintainers: []*resource.Maintainer{{Name: "bencer", Email: "bencer@sysdig.com"}},
+				Commit:      &resource.Commit{Date: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+			},
+			{
+				ID:          "deny-all",
+				Kind:        resource.NETWORK_POLICIES,
+				Name:        "Deny all traffic",
+				Vendor:      "Kubernetes",
+				Keywords:    []string{"network"},
+				Maintainers: []*resource.Maintainer{{Name: "bencer", Email: "bencer@sysdig.com"}},
+				Commit:      &resource.Commit{Date: time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+	)
+}
+
+func idsOf(resources []*resource.Resource) []string {
+	ids := []string{}
+	for _, res := range resources {
+		ids = append(ids, res.ID)
+	}
+	return ids
+}
+
+func TestRetrieveAllResourcesFilters(t *testing.T) {
+	for filter, expected := range map[ResourceFilter][]string{
+		{Kind: "networkpolicies"}:              {"deny-all"},
+		{Vendor: "apache-software-foundation"}: {"apache"},
+		{Vendor: "NGINX"}:                      {"nginx"},
+		{Keyword: "web"}:                       {"nginx", "apache"},
+		{Maintainer: "bencer@sysdig.com"}:      {"apache", "deny-all"},
+		{Keyword: "web", Maintainer: "bencer"}: {"apache"},
+		{Kind: "OPAPolicies"}:                  {},
+	} {
+		useCase := RetrieveAllResources{
+			ResourceRepository: memoryResourceRepositoryToList(),
+			Options:            ResourceListOptions{Filter: filter},
+		}
+
+		page, err := useCase.Execute()
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, idsOf(page.Resources), "%+v", filter)
+		assert.Equal(t, len(expected), page.Total)
+	}
+}
+
+func TestRetrieveAllResourcesSorts(t *testing.T) {
+	downloads := NewDownloadCounter()
+	downloads.Record("rules.yaml", "deny-all", "apache", "deny-all")
+
+	for sort, expected := range map[string][]string{
+		"name":        {"apache", "deny-all", "nginx"},
+		"-name":       {"nginx", "deny-all", "apache"},
+		"updated":     {"apache", "deny-all", "nginx"},
+		"-updated":    {"nginx", "deny-all", "apache"},
+		"-popularity": {"deny-all", "apache", "nginx"},
+	} {
+		useCase := RetrieveAllResources{
+			ResourceRepository: memoryResourceRepositoryToList(),
+			Downloads:          downloads,
+			Options:            ResourceListOptions{Sort: sort},
+		}
+
+		page, err := useCase.Execute()
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, idsOf(page.Resources), sort)
+	}
+}
+
+func TestRetrieveAllResourcesCanOnlySortResourcesReadFromGitByUpdate(t *testing.T) {
+	useCase := RetrieveAllResources{
+		ResourceRepository: memoryResourceRepository(),
+		Options:            ResourceListOptions{Sort: "-updated"},
+	}
+
+	_, err := useCase.Execute()
+
+	assert.True(t, errors.Is(err, ErrInvalidListOptions))
+}
+
+func TestRetrieveAllResourcesPaginates(t *testing.T) {
+	useCase := RetrieveAllResources{
+		ResourceRepository: memoryResourceRepositoryToList(),
+		Options:            ResourceListOptions{Sort: "name", Pagination: Pagination{Offset: 1, Limit: 1}},
+	}
+
+	page, err := useCase.Execute()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"deny-all"}, idsOf(page.Resources))
+	assert.Equal(t, 3, page.Total)
+	assert.Equal(t, Pagination{Offset: 1, Limit: 1}, page.Pagination)
+}
+
+func TestRetrieveAllResourcesReturnsAnEmptyPageAfterTheLastOne(t *testing.T) {
+	useCase := RetrieveAllResources{
+		ResourceRepository: memoryResourceRepositoryToList(),
+		Options:            ResourceListOptions{Pagination: Pagination{Offset: 10, Limit: 5}},
+	}
+
+	page, err := useCase.Execute()
+
+	assert.NoError(t, err)
+	assert.Empty(t, page.Resources)
+	assert.Equal(t, 3, page.Total)
+}
+
+func TestRetrieveAllResourcesRejectsInvalidOptions(t *testing.T) {
+	for _, options := range []ResourceListOptions{
+		{Sort: "downloads"},
+		{Pagination: Pagination{Offset: -1}},
+		{Pagination: Pagination{Limit: MaxPageSize + 1}},
+	} {
+		useCase := RetrieveAllResources{ResourceRepository: memoryResourceRepositoryToList(), Options: options}
+
+		_, err := useCase.Execute()
+
+		assert.True(t, errors.Is(err, ErrInvalidListOptions), "%+v", options)
+	}
+}
+
+func TestRetrieveAllResourcesListsEveryResourceWithoutALimit(t *testing.T) {
+	useCase := RetrieveAllResources{ResourceRepository: memoryResourceRepositoryToList()}
+
+	page, err := useCase.Execute()
+
+	assert.NoError(t, err)
+	assert.Len(t, page.Resources, 3)
 }
@@ -4,8 +4,13 @@ import "github.com/falcosecurity/cloud-native-security-hub/pkg/vendor"
 
 type RetrieveAllVendors struct {
 	VendorRepository vendor.Repository
+	Options          VendorListOptions
 }
 
-func (useCase *RetrieveAllVendors) Execute() ([]*vendor.Vendor, error) {
-	return useCase.VendorRepository.FindAll()
+func (useCase *RetrieveAllVendors) Execute() (*VendorPage, error) {
+	vendors, err := useCase.VendorRepository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	return useCase.Options.list(vendors)
 }
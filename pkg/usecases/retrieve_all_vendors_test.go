@@ -15,9 +15,9 @@ func TestReturnsAllVendors(t *testing.T) {
 	)
 	useCase := RetrieveAllVendors{VendorRepository: vendorRepository}
 
-	resources, _ := useCase.Execute()
+	page, _ := useCase.Execute()
 
-	assert.Equal(t, resources, []*vendor.Vendor{
+	assert.Equal(t, page.Vendors, []*vendor.Vendor{
 		{
 			Name: "Apache",
 		},
@@ -26,3 +26,34 @@ func TestReturnsAllVendors(t *testing.T) {
 		},
 	})
 }
+
+func TestRetrieveAllVendorsSortsAndPaginates(t *testing.T) {
+	vendorRepository := vendor.NewMemoryRepository(
+		[]*vendor.Vendor{
+			{Name: "Nginx"},
+			{Name: "apache"},
+			{Name: "Traefik"},
+		},
+	)
+	useCase := RetrieveAllVendors{
+		VendorRepository: vendorRepository,
+		Options:          VendorListOptions{Sort: "-name", Pagination: Pagination{Limit: 2}},
+	}
+
+	page, err := useCase.Execute()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*vendor.Vendor{{Name: "Traefik"}, {Name: "Nginx"}}, page.Vendors)
+	assert.Equal(t, 3, page.Total)
+}
+
+func TestRetrieveAllVendorsCanOnlySortByName(t *testing.T) {
+	useCase := RetrieveAllVendors{
+		VendorRepository: vendor.NewMemoryRepository([]*vendor.Vendor{{Name: "Nginx"}}),
+		Options:          VendorListOptions{Sort: "popularity"},
+	}
+
+	_, err := useCase.Execute()
+
+	assert.EqualError(t, err, `invalid list options: cannot sort by "popularity", use one of name`)
+}
@@ -0,0 +1,57 @@
+package usecases
+
+import (
+	"fmt"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/diagnostic"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/vendor"
+)
+
+// RetrieveDiagnostics reports the problems found in the resources and
+// vendors served: those skipped while loading them, resources assigned to a
+// vendor which doesn't exist, and rules, macros or lists defined by several
+// resources.
+type RetrieveDiagnostics struct {
+	ResourceRepository resource.Repository
+	VendorRepository   vendor.Repository
+}
+
+func (useCase *RetrieveDiagnostics) Execute() ([]*diagnostic.Diagnostic, error) {
+	diagnostics := []*diagnostic.Diagnostic{}
+	if diagnosed, ok := useCase.VendorRepository.(vendor.DiagnosedRepository); ok {
+		diagnostics = append(diagnostics, diagnosed.Diagnostics()...)
+	}
+	if diagnosed, ok := useCase.ResourceRepository.(resource.DiagnosedRepository); ok {
+		diagnostics = append(diagnostics, diagnosed.Diagnostics()...)
+	}
+
+	vendors, err := useCase.VendorRepository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	resources, err := useCase.ResourceRepository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, res := range resources {
+		if !suppliedByAny(res, vendors) {
+			diagnostics = append(diagnostics, &diagnostic.Diagnostic{
+				Code:     diagnostic.UNKNOWN_VENDOR,
+				Resource: res.ID,
+				Message:  fmt.Sprintf("resource %q is assigned to the vendor %q, which does not exist", res.ID, res.Vendor),
+			})
+		}
+	}
+
+	return append(diagnostics, resource.DiagnoseConflicts(resources)...), nil
+}
+
+func suppliedByAny(res *resource.Resource, vendors []*vendor.Vendor) bool {
+	for _, v := range vendors {
+		if suppliedBy(res, v) {
+			return true
+		}
+	}
+	return false
+}
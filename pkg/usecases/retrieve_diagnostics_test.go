@@ -0,0 +1,39 @@
+package usecases
+
+import (
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/diagnostic"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/vendor"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReturnsDiagnosticsOfResourcesAndVendors(t *testing.T) {
+	useCase := RetrieveDiagnostics{
+		ResourceRepository: resource.NewMemoryRepository([]*resource.Resource{
+			{ID: "apache", Vendor: "Apache", Rules: []*resource.FalcoRuleData{{Raw: "- macro: web_server\n  condition: proc.name = httpd\n"}}},
+			{ID: "nginx", Vendor: "F5", Rules: []*resource.FalcoRuleData{{Raw: "- macro: web_server\n  condition: proc.name = nginx\n"}}},
+		}),
+		VendorRepository: vendor.NewMemoryRepository([]*vendor.Vendor{{ID: "apache", Name: "Apache"}}),
+	}
+
+	diagnostics, err := useCase.Execute()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*diagnostic.Diagnostic{
+		{Code: diagnostic.UNKNOWN_VENDOR, Resource: "nginx", Message: `resource "nginx" is assigned to the vendor "F5", which does not exist`},
+		{Code: diagnostic.DUPLICATE_DEFINITION, Message: `the macro "web_server" is defined by "apache", "nginx"`},
+	}, diagnostics)
+}
+
+func TestReturnsNoDiagnosticsWhenEverythingIsFine(t *testing.T) {
+	useCase := RetrieveDiagnostics{
+		ResourceRepository: resource.NewMemoryRepository([]*resource.Resource{{ID: "apache", Vendor: "apache"}}),
+		VendorRepository:   vendor.NewMemoryRepository([]*vendor.Vendor{{ID: "apache", Name: "Apache Foundation"}}),
+	}
+
+	diagnostics, err := useCase.Execute()
+
+	assert.NoError(t, err)
+	assert.Empty(t, diagnostics)
+}
@@ -0,0 +1,11 @@
+package usecases
+
+// RetrieveDownloads returns how many times each resource was downloaded in
+// each format, as counted to rank resources by popularity.
+type RetrieveDownloads struct {
+	Downloads *DownloadCounter
+}
+
+func (useCase *RetrieveDownloads) Execute() []*Download {
+	return useCase.Downloads.Downloads()
+}
@@ -0,0 +1,17 @@
+package usecases
+
+import "github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+
+// RetrieveKeywords lists every keyword in use with the number of resources
+// tagged with it, to browse the resources by category.
+type RetrieveKeywords struct {
+	ResourceRepository resource.Repository
+}
+
+func (useCase *RetrieveKeywords) Execute() ([]*resource.Keyword, error) {
+	resources, err := useCase.ResourceRepository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	return resource.CountKeywords(resources), nil
+}
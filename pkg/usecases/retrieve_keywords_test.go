@@ -0,0 +1,19 @@
+package usecases
+
+import (
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRetrieveKeywordsCountsResourcesPerKeyword(t *testing.T) {
+	useCase := RetrieveKeywords{ResourceRepository: memoryResourceRepositoryToList()}
+
+	keywords, err := useCase.Execute()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*resource.Keyword{
+		{Keyword: "web", Resources: 2},
+		{Keyword: "network", Resources: 1},
+	}, keywords)
+}
@@ -0,0 +1,26 @@
+package usecases
+
+import (
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/vendor"
+	"time"
+)
+
+// RetrieveLastModified returns when the resources or vendors served last
+// changed, so clients can skip downloading them again. It is zero when
+// neither repository knows it.
+type RetrieveLastModified struct {
+	ResourceRepository resource.Repository
+	VendorRepository   vendor.Repository
+}
+
+func (useCase *RetrieveLastModified) Execute() time.Time {
+	var modified time.Time
+	if repository, ok := useCase.ResourceRepository.(resource.ModifiedRepository); ok {
+		modified = repository.LastModified()
+	}
+	if repository, ok := useCase.VendorRepository.(vendor.ModifiedRepository); ok && repository.LastModified().After(modified) {
+		modified = repository.LastModified()
+	}
+	return modified
+}
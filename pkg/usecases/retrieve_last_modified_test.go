@@ -0,0 +1,46 @@
+package usecases
+
+import (
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/vendor"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type modifiedResourceRepository struct {
+	resource.Repository
+	modified time.Time
+}
+
+func (r *modifiedResourceRepository) LastModified() time.Time {
+	return r.modified
+}
+
+type modifiedVendorRepository struct {
+	vendor.Repository
+	modified time.Time
+}
+
+func (r *modifiedVendorRepository) LastModified() time.Time {
+	return r.modified
+}
+
+func TestRetrieveLastModifiedReturnsTheNewestRepository(t *testing.T) {
+	modified := time.Date(2019, 11, 5, 10, 0, 0, 0, time.UTC)
+	useCase := RetrieveLastModified{
+		ResourceRepository: &modifiedResourceRepository{memoryResourceRepositoryToList(), modified.Add(-time.Hour)},
+		VendorRepository:   &modifiedVendorRepository{memoryVendorRepository(), modified},
+	}
+
+	assert.Equal(t, modified, useCase.Execute())
+}
+
+func TestRetrieveLastModifiedIsZeroWhenRepositoriesDontKnowIt(t *testing.T) {
+	useCase := RetrieveLastModified{
+		ResourceRepository: memoryResourceRepositoryToList(),
+		VendorRepository:   memoryVendorRepository(),
+	}
+
+	assert.True(t, useCase.Execute().IsZero())
+}
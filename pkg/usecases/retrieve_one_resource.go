@@ -10,5 +10,8 @@ type RetrieveOneResource struct {
 }
 
 func (useCase *RetrieveOneResource) Execute() (res *resource.Resource, err error) {
+	if err = resource.ValidateID(useCase.ResourceID); err != nil {
+		return
+	}
 	return useCase.ResourceRepository.FindById(useCase.ResourceID)
 }
@@ -1,6 +1,7 @@
 package usecases
 
 import (
+	"errors"
 	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -10,13 +11,13 @@ func memoryResourceRepository() resource.Repository {
 	return resource.NewMemoryRepository(
 		[]*resource.Resource{
 			&resource.Resource{
-				Kind:   resource.FALCO_RULE,
+				Kind:   resource.FALCO_RULES,
 				Name:   "Falco profile for Nginx",
 				Vendor: "Nginx",
 				ID:     "nginx",
 			},
 			&resource.Resource{
-				Kind:   resource.FALCO_RULE,
+				Kind:   resource.FALCO_RULES,
 				Name:   "Falco profile for Traefik",
 				Vendor: "Traefik",
 				ID:     "traefik",
@@ -34,7 +35,7 @@ func TestReturnsOneResource(t *testing.T) {
 	res, _ := useCase.Execute()
 
 	assert.Equal(t, &resource.Resource{
-		Kind:   resource.FALCO_RULE,
+		Kind:   resource.FALCO_RULES,
 		Name:   "Falco profile for Nginx",
 		Vendor: "Nginx",
 		ID:     "nginx",
@@ -49,5 +50,16 @@ func TestReturnsResourceNotFound(t *testing.T) {
 
 	_, err := useCase.Execute()
 
-	assert.Error(t, err)
+	assert.True(t, errors.Is(err, resource.ErrNotFound))
+}
+
+func TestReturnsInvalidResourceID(t *testing.T) {
+	useCase := RetrieveOneResource{
+		ResourceRepository: memoryResourceRepository(),
+		ResourceID:         "../etc",
+	}
+
+	_, err := useCase.Execute()
+
+	assert.True(t, errors.Is(err, resource.ErrInvalidID))
 }
@@ -10,5 +10,8 @@ type RetrieveOneVendor struct {
 }
 
 func (useCase *RetrieveOneVendor) Execute() (res *vendor.Vendor, err error) {
+	if err = vendor.ValidateID(useCase.VendorID); err != nil {
+		return
+	}
 	return useCase.VendorRepository.FindById(useCase.VendorID)
 }
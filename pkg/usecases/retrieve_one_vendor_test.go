@@ -1,6 +1,7 @@
 package usecases
 
 import (
+	"errors"
 	"github.com/falcosecurity/cloud-native-security-hub/pkg/vendor"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -57,5 +58,5 @@ func TestReturnsOneVendorNotFound(t *testing.T) {
 
 	_, err := useCase.Execute()
 
-	assert.Error(t, err)
+	assert.True(t, errors.Is(err, vendor.ErrNotFound))
 }
@@ -0,0 +1,55 @@
+package usecases
+
+import (
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/vendor"
+)
+
+// RepositoryStats describes what the repositories hold and how loading them
+// went, for monitoring.
+type RepositoryStats struct {
+	// Resources counts the resources loaded by kind, including the registered
+	// kinds without resources.
+	Resources map[resource.Kind]int
+	Vendors   map[vendor.Kind]int
+	// ResourceLoads and VendorLoads are nil when the repositories don't load
+	// in bulk.
+	ResourceLoads *resource.LoadStats
+	VendorLoads   *vendor.LoadStats
+}
+
+// RetrieveRepositoryStats never fails: a repository which can't be read
+// counts no resources, its failed loads telling why.
+type RetrieveRepositoryStats struct {
+	ResourceRepository resource.Repository
+	VendorRepository   vendor.Repository
+}
+
+func (useCase *RetrieveRepositoryStats) Execute() *RepositoryStats {
+	stats := &RepositoryStats{
+		Resources: map[resource.Kind]int{},
+		Vendors:   map[vendor.Kind]int{},
+	}
+
+	for _, kind := range resource.Kinds() {
+		stats.Resources[kind] = 0
+	}
+	resources, _ := useCase.ResourceRepository.FindAll()
+	for _, res := range resources {
+		stats.Resources[res.Kind]++
+	}
+	vendors, _ := useCase.VendorRepository.FindAll()
+	for _, v := range vendors {
+		stats.Vendors[v.Kind]++
+	}
+
+	if repository, ok := useCase.ResourceRepository.(resource.MonitoredRepository); ok {
+		loads := repository.LoadStats()
+		stats.ResourceLoads = &loads
+	}
+	if repository, ok := useCase.VendorRepository.(vendor.MonitoredRepository); ok {
+		loads := repository.LoadStats()
+		stats.VendorLoads = &loads
+	}
+	return stats
+}
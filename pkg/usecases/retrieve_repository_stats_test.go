@@ -0,0 +1,48 @@
+package usecases
+
+import (
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/vendor"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type monitoredResourceRepository struct {
+	resource.Repository
+	stats resource.LoadStats
+}
+
+func (r *monitoredResourceRepository) LoadStats() resource.LoadStats {
+	return r.stats
+}
+
+func TestRetrieveRepositoryStatsCountsResourcesByKind(t *testing.T) {
+	useCase := RetrieveRepositoryStats{
+		ResourceRepository: memoryResourceRepositoryToList(),
+		VendorRepository: vendor.NewMemoryRepository([]*vendor.Vendor{
+			{ID: "apache", Kind: vendor.VENDOR},
+			{ID: "nginx", Kind: vendor.VENDOR},
+		}),
+	}
+
+	stats := useCase.Execute()
+
+	assert.Equal(t, 2, stats.Resources[resource.FALCO_RULES])
+	assert.Equal(t, 1, stats.Resources[resource.NETWORK_POLICIES])
+	assert.Contains(t, stats.Resources, resource.OPA_POLICIES)
+	assert.Equal(t, map[vendor.Kind]int{vendor.VENDOR: 2}, stats.Vendors)
+}
+
+func TestRetrieveRepositoryStatsReportsLoadsOfMonitoredRepositories(t *testing.T) {
+	loads := resource.LoadStats{Loads: 3, Failures: 1, LastDuration: time.Second}
+	useCase := RetrieveRepositoryStats{
+		ResourceRepository: &monitoredResourceRepository{memoryResourceRepositoryToList(), loads},
+		VendorRepository:   memoryVendorRepository(),
+	}
+
+	stats := useCase.Execute()
+
+	assert.Equal(t, &loads, stats.ResourceLoads)
+	assert.Nil(t, stats.VendorLoads)
+}
@@ -0,0 +1,36 @@
+package usecases
+
+import (
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+)
+
+type RetrieveResourceDependencies struct {
+	ResourceRepository resource.Repository
+	FalcoDefaults      *resource.FalcoDefaults
+	ResourceID         string
+}
+
+func (useCase *RetrieveResourceDependencies) Execute() (*resource.Dependencies, error) {
+	if err := resource.ValidateID(useCase.ResourceID); err != nil {
+		return nil, err
+	}
+	res, err := useCase.ResourceRepository.FindById(useCase.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := useCase.ResourceRepository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	return resource.NewDependencyAnalyzer(resources, useCase.FalcoDefaults).Analyze(res)
+}
+
+// validateDependencies fails when the resource uses macros which are not
+// defined in the resource itself, in the repository or in Falco.
+func validateDependencies(repository resource.Repository, defaults *resource.FalcoDefaults, res *resource.Resource) error {
+	resources, err := repository.FindAll()
+	if err != nil {
+		return err
+	}
+	return resource.NewDependencyAnalyzer(resources, defaults).Validate(res)
+}
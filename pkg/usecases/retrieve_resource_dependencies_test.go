@@ -0,0 +1,52 @@
+package usecases
+
+import (
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func memoryResourceRepositoryWithDependencies() resource.Repository {
+	return resource.NewMemoryRepository(
+		[]*resource.Resource{
+			{
+				ID:    "shared",
+				Rules: []*resource.FalcoRuleData{{Raw: "- macro: web_server\n  condition: proc.name = nginx\n"}},
+			},
+			{
+				ID:    "nginx",
+				Rules: []*resource.FalcoRuleData{{Raw: "- rule: Shell in nginx\n  condition: spawned_process and web_server\n"}},
+			},
+		},
+	)
+}
+
+func TestReturnsDependenciesOfAResource(t *testing.T) {
+	useCase := RetrieveResourceDependencies{
+		ResourceRepository: memoryResourceRepositoryWithDependencies(),
+		FalcoDefaults:      resource.NewFalcoDefaults(),
+		ResourceID:         "nginx",
+	}
+
+	dependencies, err := useCase.Execute()
+
+	assert.NoError(t, err)
+	assert.Equal(t, &resource.Dependencies{
+		Resource: "nginx",
+		Dependencies: []*resource.Dependency{
+			{Type: resource.FALCO_MACRO_ITEM, Name: "spawned_process", Source: resource.FALCO_DEPENDENCY, UsedBy: []string{"Shell in nginx"}},
+			{Type: resource.FALCO_MACRO_ITEM, Name: "web_server", Source: resource.HUB_DEPENDENCY, Resources: []string{"shared"}, UsedBy: []string{"Shell in nginx"}},
+		},
+	}, dependencies)
+}
+
+func TestResourceDependenciesReturnsNotFound(t *testing.T) {
+	useCase := RetrieveResourceDependencies{
+		ResourceRepository: memoryResourceRepositoryWithDependencies(),
+		ResourceID:         "notFound",
+	}
+
+	_, err := useCase.Execute()
+
+	assert.Error(t, err)
+}
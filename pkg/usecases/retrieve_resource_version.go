@@ -0,0 +1,21 @@
+package usecases
+
+import (
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+)
+
+type RetrieveResourceVersion struct {
+	ResourceRepository resource.Repository
+	ResourceID         string
+	Version            string
+}
+
+func (useCase *RetrieveResourceVersion) Execute() (*resource.Resource, error) {
+	if err := resource.ValidateID(useCase.ResourceID); err != nil {
+		return nil, err
+	}
+	if err := resource.ValidateVersion(useCase.Version); err != nil {
+		return nil, err
+	}
+	return useCase.ResourceRepository.FindVersion(useCase.ResourceID, useCase.Version)
+}
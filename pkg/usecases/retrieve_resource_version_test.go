@@ -0,0 +1,45 @@
+package usecases
+
+import (
+	"errors"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReturnsOneVersionOfAResource(t *testing.T) {
+	useCase := RetrieveResourceVersion{
+		ResourceRepository: memoryResourceRepositoryWithVersions(),
+		ResourceID:         "nginx",
+		Version:            "1.0.0",
+	}
+
+	res, err := useCase.Execute()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "nginxRule", res.Rules[0].Raw)
+}
+
+func TestResourceVersionReturnsNotFound(t *testing.T) {
+	useCase := RetrieveResourceVersion{
+		ResourceRepository: memoryResourceRepositoryWithVersions(),
+		ResourceID:         "nginx",
+		Version:            "2.0.0",
+	}
+
+	_, err := useCase.Execute()
+
+	assert.True(t, errors.Is(err, resource.ErrNotFound))
+}
+
+func TestResourceVersionRejectsInvalidVersions(t *testing.T) {
+	useCase := RetrieveResourceVersion{
+		ResourceRepository: memoryResourceRepositoryWithVersions(),
+		ResourceID:         "nginx",
+		Version:            "latest",
+	}
+
+	_, err := useCase.Execute()
+
+	assert.True(t, errors.Is(err, resource.ErrInvalidVersion))
+}
@@ -0,0 +1,17 @@
+package usecases
+
+import (
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+)
+
+type RetrieveResourceVersions struct {
+	ResourceRepository resource.Repository
+	ResourceID         string
+}
+
+func (useCase *RetrieveResourceVersions) Execute() ([]*resource.Resource, error) {
+	if err := resource.ValidateID(useCase.ResourceID); err != nil {
+		return nil, err
+	}
+	return useCase.ResourceRepository.FindVersions(useCase.ResourceID)
+}
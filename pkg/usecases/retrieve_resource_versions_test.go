@@ -0,0 +1,42 @@
+package usecases
+
+import (
+	"errors"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func memoryResourceRepositoryWithVersions() resource.Repository {
+	return resource.NewMemoryRepository(
+		[]*resource.Resource{
+			{ID: "nginx", Name: "Nginx", Version: "1.0.0", Rules: []*resource.FalcoRuleData{{Raw: "nginxRule"}}},
+			{ID: "nginx", Name: "Nginx", Version: "1.1.0", Rules: []*resource.FalcoRuleData{{Raw: "nginxRule2"}}},
+		},
+	)
+}
+
+func TestReturnsEveryVersionOfAResource(t *testing.T) {
+	useCase := RetrieveResourceVersions{
+		ResourceRepository: memoryResourceRepositoryWithVersions(),
+		ResourceID:         "nginx",
+	}
+
+	versions, err := useCase.Execute()
+
+	assert.NoError(t, err)
+	assert.Len(t, versions, 2)
+	assert.Equal(t, "1.1.0", versions[0].Version)
+	assert.Equal(t, "1.0.0", versions[1].Version)
+}
+
+func TestResourceVersionsReturnsNotFound(t *testing.T) {
+	useCase := RetrieveResourceVersions{
+		ResourceRepository: memoryResourceRepositoryWithVersions(),
+		ResourceID:         "notFound",
+	}
+
+	_, err := useCase.Execute()
+
+	assert.True(t, errors.Is(err, resource.ErrNotFound))
+}
@@ -0,0 +1,37 @@
+package usecases
+
+import (
+	"fmt"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"strings"
+)
+
+type RetrieveResourcesMatchingQuery struct {
+	ResourceRepository resource.Repository
+	Query              string
+}
+
+func (useCase *RetrieveResourcesMatchingQuery) Execute() ([]*resource.Resource, error) {
+	if strings.TrimSpace(useCase.Query) == "" {
+		return nil, fmt.Errorf("%w: the search query must not be empty", ErrInvalidQuery)
+	}
+
+	index, err := useCase.index()
+	if err != nil {
+		return nil, err
+	}
+
+	return index.Search(useCase.Query), nil
+}
+
+func (useCase *RetrieveResourcesMatchingQuery) index() (*resource.Index, error) {
+	if indexed, ok := useCase.ResourceRepository.(resource.IndexedRepository); ok {
+		return indexed.Index()
+	}
+
+	resources, err := useCase.ResourceRepository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	return resource.NewIndex(resources), nil
+}
@@ -0,0 +1,100 @@
+package usecases
+
+import (
+	"errors"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func memoryResourceRepositoryToSearch() resource.Repository {
+	return resource.NewMemoryRepository(
+		[]*resource.Resource{
+			{
+				ID:          "nginx",
+				Name:        "Nginx",
+				Vendor:      "Nginx",
+				Description: "Detects suspicious activity in web servers",
+				Keywords:    []string{"web"},
+			},
+			{
+				ID:       "mongodb",
+				Name:     "MongoDB",
+				Vendor:   "Mongo",
+				Keywords: []string{"database"},
+				Rules: []*resource.FalcoRuleData{
+					{Raw: "- macro: mongo_consider_syscalls\n  condition: (evt.num < 0)"},
+				},
+			},
+			{
+				ID:       "web",
+				Name:     "Web",
+				Vendor:   "Generic",
+				Keywords: []string{"web"},
+			},
+		},
+	)
+}
+
+func TestSearchRanksResourcesByRelevance(t *testing.T) {
+	useCase := RetrieveResourcesMatchingQuery{
+		ResourceRepository: memoryResourceRepositoryToSearch(),
+		Query:              "web",
+	}
+
+	resources, _ := useCase.Execute()
+
+	assert.Equal(t, []string{"web", "nginx"}, resourceIDs(resources))
+}
+
+func TestSearchMatchesRulesContent(t *testing.T) {
+	useCase := RetrieveResourcesMatchingQuery{
+		ResourceRepository: memoryResourceRepositoryToSearch(),
+		Query:              "consider_syscalls",
+	}
+
+	resources, _ := useCase.Execute()
+
+	assert.Equal(t, []string{"mongodb"}, resourceIDs(resources))
+}
+
+func TestSearchRequiresEveryTermToMatch(t *testing.T) {
+	useCase := RetrieveResourcesMatchingQuery{
+		ResourceRepository: memoryResourceRepositoryToSearch(),
+		Query:              "web suspicious",
+	}
+
+	resources, _ := useCase.Execute()
+
+	assert.Equal(t, []string{"nginx"}, resourceIDs(resources))
+}
+
+func TestSearchMatchesPrefixes(t *testing.T) {
+	useCase := RetrieveResourcesMatchingQuery{
+		ResourceRepository: memoryResourceRepositoryToSearch(),
+		Query:              "mong",
+	}
+
+	resources, _ := useCase.Execute()
+
+	assert.Equal(t, []string{"mongodb"}, resourceIDs(resources))
+}
+
+func TestSearchReturnsErrorWithEmptyQuery(t *testing.T) {
+	useCase := RetrieveResourcesMatchingQuery{
+		ResourceRepository: memoryResourceRepositoryToSearch(),
+		Query:              " ",
+	}
+
+	_, err := useCase.Execute()
+
+	assert.True(t, errors.Is(err, ErrInvalidQuery))
+}
+
+func resourceIDs(resources []*resource.Resource) []string {
+	ids := []string{}
+	for _, r := range resources {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
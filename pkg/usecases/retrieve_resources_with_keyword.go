@@ -0,0 +1,39 @@
+package usecases
+
+import (
+	"fmt"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"strings"
+)
+
+// RetrieveResourcesWithKeyword lists the resources tagged with a keyword or
+// any of its synonyms.
+type RetrieveResourcesWithKeyword struct {
+	ResourceRepository resource.Repository
+	Taxonomy           *resource.Taxonomy
+	Downloads          *DownloadCounter
+	Keyword            string
+	Options            ResourceListOptions
+}
+
+func (useCase *RetrieveResourcesWithKeyword) Execute() (*ResourcePage, error) {
+	if strings.TrimSpace(useCase.Keyword) == "" {
+		return nil, fmt.Errorf("%w: the keyword must not be empty", ErrInvalidQuery)
+	}
+
+	resources, err := useCase.ResourceRepository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	options := useCase.Options
+	options.Filter.Keyword = useCase.Taxonomy.Canonical(useCase.Keyword)
+	page, err := options.list(resources, useCase.Downloads)
+	if err != nil {
+		return nil, err
+	}
+	if page.Total == 0 {
+		return nil, fmt.Errorf("keyword %q: %w", useCase.Keyword, ErrNoResourcesForKeyword)
+	}
+	return page, nil
+}
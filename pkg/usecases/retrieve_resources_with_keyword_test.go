@@ -0,0 +1,58 @@
+package usecases
+
+import (
+	"errors"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRetrieveResourcesWithKeyword(t *testing.T) {
+	useCase := RetrieveResourcesWithKeyword{
+		ResourceRepository: memoryResourceRepositoryToList(),
+		Keyword:            "WEB",
+		Options:            ResourceListOptions{Sort: "name"},
+	}
+
+	page, err := useCase.Execute()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"apache", "nginx"}, idsOf(page.Resources))
+	assert.Equal(t, 2, page.Total)
+}
+
+func TestRetrieveResourcesWithKeywordFollowsSynonyms(t *testing.T) {
+	taxonomy, _ := resource.NewTaxonomy(map[string][]string{"network": {"net", "networking"}})
+	useCase := RetrieveResourcesWithKeyword{
+		ResourceRepository: memoryResourceRepositoryToList(),
+		Taxonomy:           taxonomy,
+		Keyword:            "networking",
+	}
+
+	page, err := useCase.Execute()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"deny-all"}, idsOf(page.Resources))
+}
+
+func TestRetrieveResourcesWithUnusedKeywordReturnsNotFound(t *testing.T) {
+	useCase := RetrieveResourcesWithKeyword{
+		ResourceRepository: memoryResourceRepositoryToList(),
+		Keyword:            "database",
+	}
+
+	_, err := useCase.Execute()
+
+	assert.True(t, errors.Is(err, ErrNoResourcesForKeyword))
+}
+
+func TestRetrieveResourcesWithEmptyKeywordIsInvalid(t *testing.T) {
+	useCase := RetrieveResourcesWithKeyword{
+		ResourceRepository: memoryResourceRepositoryToList(),
+		Keyword:            " ",
+	}
+
+	_, err := useCase.Execute()
+
+	assert.True(t, errors.Is(err, ErrInvalidQuery))
+}
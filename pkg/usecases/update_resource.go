@@ -0,0 +1,35 @@
+package usecases
+
+import (
+	"fmt"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"strings"
+)
+
+type UpdateResource struct {
+	ResourceRepository resource.Repository
+	FalcoDefaults      *resource.FalcoDefaults
+	ResourceID         string
+	Resource           *resource.Resource
+}
+
+func (useCase *UpdateResource) Execute() error {
+	if err := resource.ValidateID(useCase.ResourceID); err != nil {
+		return err
+	}
+	if err := useCase.Resource.Validate(); err != nil {
+		return err
+	}
+	if useCase.Resource.ID != strings.ToLower(useCase.ResourceID) {
+		return &resource.ValidationError{
+			Errors: []string{fmt.Sprintf("the resource ID %q does not match %q", useCase.Resource.ID, useCase.ResourceID)},
+		}
+	}
+	if err := validateIDs(useCase.ResourceRepository, useCase.Resource); err != nil {
+		return err
+	}
+	if err := validateDependencies(useCase.ResourceRepository, useCase.FalcoDefaults, useCase.Resource); err != nil {
+		return err
+	}
+	return useCase.ResourceRepository.Update(useCase.Resource)
+}
@@ -0,0 +1,48 @@
+package usecases
+
+import (
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUpdatesResource(t *testing.T) {
+	resourceRepository := resource.NewMemoryRepository([]*resource.Resource{validResource("nginx")})
+	updated := validResource("nginx")
+	updated.Keywords = []string{"web"}
+	useCase := UpdateResource{
+		ResourceRepository: resourceRepository,
+		ResourceID:         "nginx",
+		Resource:           updated,
+	}
+
+	err := useCase.Execute()
+
+	res, _ := resourceRepository.FindById("nginx")
+	assert.NoError(t, err)
+	assert.Equal(t, updated, res)
+}
+
+func TestUpdateResourceFailsIfIDDoesNotMatch(t *testing.T) {
+	useCase := UpdateResource{
+		ResourceRepository: resource.NewMemoryRepository([]*resource.Resource{validResource("nginx")}),
+		ResourceID:         "nginx",
+		Resource:           validResource("traefik"),
+	}
+
+	err := useCase.Execute()
+
+	assert.IsType(t, &resource.ValidationError{}, err)
+}
+
+func TestUpdateResourceReturnsNotFound(t *testing.T) {
+	useCase := UpdateResource{
+		ResourceRepository: resource.NewMemoryRepository([]*resource.Resource{}),
+		ResourceID:         "nginx",
+		Resource:           validResource("nginx"),
+	}
+
+	err := useCase.Execute()
+
+	assert.Error(t, err)
+}
@@ -2,43 +2,130 @@ package web
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/metrics"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
 	"github.com/falcosecurity/cloud-native-security-hub/pkg/usecases"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/vendor"
 	"github.com/julienschmidt/httprouter"
 	"log"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 )
 
 type HandlerRepository interface {
 	notFound() http.HandlerFunc
+	methodNotAllowed() http.HandlerFunc
 	retrieveAllResourcesHandler(writer http.ResponseWriter, request *http.Request, _ httprouter.Params)
 	retrieveOneResourcesHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
-	retrieveFalcoRulesForHelmChartHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	searchResourcesHandler(writer http.ResponseWriter, request *http.Request, _ httprouter.Params)
+	retrieveResourceVersionsHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	retrieveResourceVersionHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	compareResourceVersionsHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	exportResourceHandler(format string) httprouter.Handle
+	retrieveResourceDependenciesHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	exportBundleHandler(format string) httprouter.Handle
+	createBundleHandler(format string) httprouter.Handle
+	createResourceHandler(writer http.ResponseWriter, request *http.Request, _ httprouter.Params)
+	updateResourceHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	deleteResourceHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	retrieveKeywordsHandler(writer http.ResponseWriter, request *http.Request, _ httprouter.Params)
+	retrieveResourcesWithKeywordHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	retrieveAllMaintainersHandler(writer http.ResponseWriter, request *http.Request, _ httprouter.Params)
+	retrieveAllResourcesFromMaintainerHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
 	retrieveAllVendorsHandler(writer http.ResponseWriter, request *http.Request, _ httprouter.Params)
 	retrieveOneVendorsHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
 	retrieveAllResourcesFromVendorHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	retrieveDiagnosticsHandler(writer http.ResponseWriter, request *http.Request, _ httprouter.Params)
 	healthCheckHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	openAPIHandler(writer http.ResponseWriter, request *http.Request, _ httprouter.Params)
+	metricsHandler(writer http.ResponseWriter, request *http.Request, _ httprouter.Params)
+	lastModified() time.Time
+	instrument(route string, handle httprouter.Handle) httprouter.Handle
+	instrumentUnmatched(handler http.HandlerFunc) http.HandlerFunc
+	requireToken(token string, handle httprouter.Handle) httprouter.Handle
 }
 
 type handlerRepository struct {
 	factory usecases.Factory
 	logger  *log.Logger
+	*hubMetrics
 }
 
 func NewHandlerRepository(logger *log.Logger) HandlerRepository {
+	factory := usecases.NewFactory()
 	return &handlerRepository{
-		factory: usecases.NewFactory(),
-		logger:  logger,
+		factory:    factory,
+		logger:     logger,
+		hubMetrics: newHubMetrics(factory.NewRetrieveRepositoryStatsUseCase().Execute, factory.NewRetrieveDownloadsUseCase().Execute),
 	}
 }
 
 func (h *handlerRepository) notFound() http.HandlerFunc {
 	return func(writer http.ResponseWriter, request *http.Request) {
-		h.logRequest(request, 404)
-		http.NotFound(writer, request)
+		h.writeErrorResponse(writer, request, http.StatusNotFound, &errorResponse{
+			Code:      "not_found",
+			Message:   "no route matches " + request.URL.Path,
+			RequestID: requestID(request),
+		})
+	}
+}
+
+func (h *handlerRepository) methodNotAllowed() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		h.writeErrorResponse(writer, request, http.StatusMethodNotAllowed, &errorResponse{
+			Code:      "method_not_allowed",
+			Message:   request.Method + " is not allowed on " + request.URL.Path,
+			RequestID: requestID(request),
+		})
+	}
+}
+
+func (h *handlerRepository) writeError(writer http.ResponseWriter, request *http.Request, err error) {
+	var moved *resource.MovedError
+	if errors.As(err, &moved) && h.redirectToMoved(writer, request, "resources", moved.ID, moved.To) {
+		return
+	}
+	var vendorMoved *vendor.MovedError
+	if errors.As(err, &vendorMoved) && h.redirectToMoved(writer, request, "vendors", vendorMoved.ID, vendorMoved.To) {
+		return
 	}
 
+	statusCode, response := newErrorResponse(err, requestID(request))
+	if statusCode == http.StatusInternalServerError && h.logger != nil {
+		h.logger.Printf("request %s failed: %s", response.RequestID, err)
+	}
+	h.writeErrorResponse(writer, request, statusCode, response)
+}
+
+// redirectToMoved sends clients looking up a resource or a vendor by a former
+// ID, following the collection in the path, to the same URL with its current
+// ID, keeping the query. It reports false when the former ID is not part of
+// the path.
+func (h *handlerRepository) redirectToMoved(writer http.ResponseWriter, request *http.Request, collection, id, to string) bool {
+	segments := strings.Split(request.URL.Path, "/")
+	for position := 1; position < len(segments); position++ {
+		if segments[position-1] == collection && strings.EqualFold(segments[position], id) {
+			segments[position] = to
+			location := url.URL{Path: strings.Join(segments, "/"), RawQuery: request.URL.RawQuery}
+			http.Redirect(writer, request, location.String(), http.StatusMovedPermanently)
+			h.logRequest(request, http.StatusMovedPermanently)
+			return true
+		}
+	}
+	return false
+}
+
+func (h *handlerRepository) writeErrorResponse(writer http.ResponseWriter, request *http.Request, statusCode int, response *errorResponse) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	h.logRequest(request, statusCode)
+	json.NewEncoder(writer).Encode(response)
 }
+
 func (h *handlerRepository) logRequest(request *http.Request, statusCode int) {
 	if h.logger == nil {
 		return
@@ -48,28 +135,36 @@ func (h *handlerRepository) logRequest(request *http.Request, statusCode int) {
 	h.logger.Println(line)
 }
 
+// lastModified returns when the resources or vendors served last changed, to
+// answer conditional requests.
+func (h *handlerRepository) lastModified() time.Time {
+	return h.factory.NewRetrieveLastModifiedUseCase().Execute()
+}
+
 func (h *handlerRepository) retrieveAllResourcesHandler(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) {
-	useCase := h.factory.NewRetrieveAllResourcesUseCase()
-	resources, err := useCase.Execute()
+	options, err := resourceListOptions(request)
 	if err != nil {
-		h.logRequest(request, 500)
-		writer.WriteHeader(500)
-		writer.Write([]byte(err.Error()))
+		h.writeError(writer, request, err)
+		return
+	}
+	useCase := h.factory.NewRetrieveAllResourcesUseCase(options)
+	page, err := useCase.Execute()
+	if err != nil {
+		h.writeError(writer, request, err)
 		return
 	}
 	writer.Header().Set("Content-Type", "application/json")
+	writePageHeaders(writer, request, page.Total, page.Pagination)
 
 	h.logRequest(request, 200)
-	json.NewEncoder(writer).Encode(resources)
+	json.NewEncoder(writer).Encode(page.Resources)
 }
 
 func (h *handlerRepository) retrieveOneResourcesHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 	useCase := h.factory.NewRetrieveOneResourceUseCase(params.ByName("resource"))
 	resources, err := useCase.Execute()
 	if err != nil {
-		writer.WriteHeader(500)
-		h.logRequest(request, 500)
-		writer.Write([]byte(err.Error()))
+		h.writeError(writer, request, err)
 		return
 	}
 	writer.Header().Set("Content-Type", "application/json")
@@ -77,27 +172,23 @@ func (h *handlerRepository) retrieveOneResourcesHandler(writer http.ResponseWrit
 	json.NewEncoder(writer).Encode(resources)
 }
 
-func (h *handlerRepository) retrieveFalcoRulesForHelmChartHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	useCase := h.factory.NewRetrieveFalcoRulesForHelmChartUseCase(params.ByName("resource"))
-	content, err := useCase.Execute()
+func (h *handlerRepository) searchResourcesHandler(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	useCase := h.factory.NewRetrieveResourcesMatchingQueryUseCase(request.URL.Query().Get("q"))
+	resources, err := useCase.Execute()
 	if err != nil {
-		writer.WriteHeader(500)
-		h.logRequest(request, 500)
-		writer.Write([]byte(err.Error()))
+		h.writeError(writer, request, err)
 		return
 	}
-	writer.Header().Set("Content-Type", "application/x-yaml")
+	writer.Header().Set("Content-Type", "application/json")
 	h.logRequest(request, 200)
-	writer.Write(content)
+	json.NewEncoder(writer).Encode(resources)
 }
 
-func (h *handlerRepository) retrieveAllVendorsHandler(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) {
-	useCase := h.factory.NewRetrieveAllVendorsUseCase()
+func (h *handlerRepository) retrieveResourceVersionsHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	useCase := h.factory.NewRetrieveResourceVersionsUseCase(params.ByName("resource"))
 	resources, err := useCase.Execute()
 	if err != nil {
-		writer.WriteHeader(500)
-		h.logRequest(request, 500)
-		writer.Write([]byte(err.Error()))
+		h.writeError(writer, request, err)
 		return
 	}
 	writer.Header().Set("Content-Type", "application/json")
@@ -105,13 +196,242 @@ func (h *handlerRepository) retrieveAllVendorsHandler(writer http.ResponseWriter
 	json.NewEncoder(writer).Encode(resources)
 }
 
+func (h *handlerRepository) retrieveResourceVersionHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	useCase := h.factory.NewRetrieveResourceVersionUseCase(params.ByName("resource"), params.ByName("version"))
+	res, err := useCase.Execute()
+	if err != nil {
+		h.writeError(writer, request, err)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	h.logRequest(request, 200)
+	json.NewEncoder(writer).Encode(res)
+}
+
+func (h *handlerRepository) compareResourceVersionsHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	query := request.URL.Query()
+	useCase := h.factory.NewCompareResourceVersionsUseCase(params.ByName("resource"), query.Get("from"), query.Get("to"))
+	result, err := useCase.Execute()
+	if err != nil {
+		h.writeError(writer, request, err)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	h.logRequest(request, 200)
+	json.NewEncoder(writer).Encode(result)
+}
+
+func (h *handlerRepository) exportResourceHandler(format string) httprouter.Handle {
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		useCase := h.factory.NewExportResourceUseCase(params.ByName("resource"), request.URL.Query().Get("version"), format, exportOptions(request))
+		h.writeExport(writer, request, useCase.Execute, useCase.RecordDownload)
+	}
+}
+
+func (h *handlerRepository) exportBundleHandler(format string) httprouter.Handle {
+	return func(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+		var resourceIDs []string
+		for _, id := range strings.Split(request.URL.Query().Get("resources"), ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				resourceIDs = append(resourceIDs, id)
+			}
+		}
+		useCase := h.factory.NewExportBundleUseCase(resourceIDs, format, exportOptions(request))
+		h.writeExport(writer, request, useCase.Execute, useCase.RecordDownload)
+	}
+}
+
+type bundleRequest struct {
+	Resources []string `json:"resources"`
+}
+
+func (h *handlerRepository) createBundleHandler(format string) httprouter.Handle {
+	return func(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+		var bundle bundleRequest
+		if err := json.NewDecoder(request.Body).Decode(&bundle); err != nil {
+			h.writeErrorResponse(writer, request, http.StatusBadRequest, &errorResponse{
+				Code:      "invalid_request",
+				Message:   "invalid JSON: " + err.Error(),
+				RequestID: requestID(request),
+			})
+			return
+		}
+		useCase := h.factory.NewExportBundleUseCase(bundle.Resources, format, exportOptions(request))
+		h.writeExport(writer, request, useCase.Execute, useCase.RecordDownload)
+	}
+}
+
+// writeExport writes the export, counting it as a download once it is
+// delivered.
+func (h *handlerRepository) writeExport(writer http.ResponseWriter, request *http.Request, export func() (*resource.Export, error), recordDownload func()) {
+	exported, err := export()
+	if err != nil {
+		h.writeError(writer, request, err)
+		return
+	}
+	writer.Header().Set("Content-Type", exported.ContentType)
+	h.logRequest(request, 200)
+	writer.Write(exported.Content)
+	onDelivered(request, recordDownload)
+}
+
+// exportOptions passes the query parameters to the exporter, which picks the
+// ones it knows.
+func exportOptions(request *http.Request) resource.ExportOptions {
+	options := resource.ExportOptions{}
+	for key, values := range request.URL.Query() {
+		if key != "resources" && key != "version" && len(values) > 0 {
+			options[key] = values[0]
+		}
+	}
+	return options
+}
+
+func (h *handlerRepository) retrieveResourceDependenciesHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	useCase := h.factory.NewRetrieveResourceDependenciesUseCase(params.ByName("resource"))
+	dependencies, err := useCase.Execute()
+	if err != nil {
+		h.writeError(writer, request, err)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	h.logRequest(request, 200)
+	json.NewEncoder(writer).Encode(dependencies)
+}
+
+func (h *handlerRepository) createResourceHandler(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	res, ok := h.decodeResource(writer, request)
+	if !ok {
+		return
+	}
+	useCase := h.factory.NewCreateResourceUseCase(res)
+	if err := useCase.Execute(); err != nil {
+		h.writeError(writer, request, err)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(201)
+	h.logRequest(request, 201)
+	json.NewEncoder(writer).Encode(res)
+}
+
+func (h *handlerRepository) updateResourceHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	res, ok := h.decodeResource(writer, request)
+	if !ok {
+		return
+	}
+	useCase := h.factory.NewUpdateResourceUseCase(params.ByName("resource"), res)
+	if err := useCase.Execute(); err != nil {
+		h.writeError(writer, request, err)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	h.logRequest(request, 200)
+	json.NewEncoder(writer).Encode(res)
+}
+
+func (h *handlerRepository) deleteResourceHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	useCase := h.factory.NewDeleteResourceUseCase(params.ByName("resource"))
+	if err := useCase.Execute(); err != nil {
+		h.writeError(writer, request, err)
+		return
+	}
+	writer.WriteHeader(204)
+	h.logRequest(request, 204)
+}
+
+func (h *handlerRepository) decodeResource(writer http.ResponseWriter, request *http.Request) (*resource.Resource, bool) {
+	var res resource.Resource
+	if err := json.NewDecoder(request.Body).Decode(&res); err != nil {
+		h.writeError(writer, request, &resource.ValidationError{Errors: []string{"invalid JSON: " + err.Error()}})
+		return nil, false
+	}
+	return &res, true
+}
+
+func (h *handlerRepository) retrieveKeywordsHandler(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	useCase := h.factory.NewRetrieveKeywordsUseCase()
+	keywords, err := useCase.Execute()
+	if err != nil {
+		h.writeError(writer, request, err)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	h.logRequest(request, 200)
+	json.NewEncoder(writer).Encode(keywords)
+}
+
+func (h *handlerRepository) retrieveResourcesWithKeywordHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	options, err := resourceListOptions(request)
+	if err != nil {
+		h.writeError(writer, request, err)
+		return
+	}
+	useCase := h.factory.NewRetrieveResourcesWithKeywordUseCase(params.ByName("keyword"), options)
+	page, err := useCase.Execute()
+	if err != nil {
+		h.writeError(writer, request, err)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writePageHeaders(writer, request, page.Total, page.Pagination)
+	h.logRequest(request, 200)
+	json.NewEncoder(writer).Encode(page.Resources)
+}
+
+func (h *handlerRepository) retrieveAllMaintainersHandler(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	useCase := h.factory.NewRetrieveAllMaintainersUseCase()
+	maintainers, err := useCase.Execute()
+	if err != nil {
+		h.writeError(writer, request, err)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	h.logRequest(request, 200)
+	json.NewEncoder(writer).Encode(maintainers)
+}
+
+func (h *handlerRepository) retrieveAllResourcesFromMaintainerHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	options, err := resourceListOptions(request)
+	if err != nil {
+		h.writeError(writer, request, err)
+		return
+	}
+	useCase := h.factory.NewRetrieveAllResourcesFromMaintainerUseCase(params.ByName("maintainer"), options)
+	page, err := useCase.Execute()
+	if err != nil {
+		h.writeError(writer, request, err)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writePageHeaders(writer, request, page.Total, page.Pagination)
+	h.logRequest(request, 200)
+	json.NewEncoder(writer).Encode(page.Resources)
+}
+
+func (h *handlerRepository) retrieveAllVendorsHandler(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	options, err := vendorListOptions(request)
+	if err != nil {
+		h.writeError(writer, request, err)
+		return
+	}
+	useCase := h.factory.NewRetrieveAllVendorsUseCase(options)
+	page, err := useCase.Execute()
+	if err != nil {
+		h.writeError(writer, request, err)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writePageHeaders(writer, request, page.Total, page.Pagination)
+	h.logRequest(request, 200)
+	json.NewEncoder(writer).Encode(page.Vendors)
+}
+
 func (h *handlerRepository) retrieveOneVendorsHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 	useCase := h.factory.NewRetrieveOneVendorUseCase(params.ByName("vendor"))
 	resources, err := useCase.Execute()
 	if err != nil {
-		writer.WriteHeader(500)
-		h.logRequest(request, 500)
-		writer.Write([]byte(err.Error()))
+		h.writeError(writer, request, err)
 		return
 	}
 	writer.Header().Set("Content-Type", "application/json")
@@ -120,17 +440,33 @@ func (h *handlerRepository) retrieveOneVendorsHandler(writer http.ResponseWriter
 }
 
 func (h *handlerRepository) retrieveAllResourcesFromVendorHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	useCase := h.factory.NewRetrieveAllResourcesFromVendorUseCase(params.ByName("vendor"))
-	resources, err := useCase.Execute()
+	options, err := resourceListOptions(request)
 	if err != nil {
-		writer.WriteHeader(500)
-		h.logRequest(request, 500)
-		writer.Write([]byte(err.Error()))
+		h.writeError(writer, request, err)
+		return
+	}
+	useCase := h.factory.NewRetrieveAllResourcesFromVendorUseCase(params.ByName("vendor"), options)
+	page, err := useCase.Execute()
+	if err != nil {
+		h.writeError(writer, request, err)
 		return
 	}
 	writer.Header().Set("Content-Type", "application/json")
+	writePageHeaders(writer, request, page.Total, page.Pagination)
 	h.logRequest(request, 200)
-	json.NewEncoder(writer).Encode(resources)
+	json.NewEncoder(writer).Encode(page.Resources)
+}
+
+func (h *handlerRepository) retrieveDiagnosticsHandler(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	useCase := h.factory.NewRetrieveDiagnosticsUseCase()
+	diagnostics, err := useCase.Execute()
+	if err != nil {
+		h.writeError(writer, request, err)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	h.logRequest(request, 200)
+	json.NewEncoder(writer).Encode(diagnostics)
 }
 
 func (h *handlerRepository) healthCheckHandler(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
@@ -138,3 +474,15 @@ func (h *handlerRepository) healthCheckHandler(writer http.ResponseWriter, reque
 	writer.Header().Set("Content-Type", "text/plain")
 	writer.Write([]byte("OK"))
 }
+
+func (h *handlerRepository) openAPIHandler(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	writer.Header().Set("Content-Type", "application/json")
+	h.logRequest(request, 200)
+	json.NewEncoder(writer).Encode(newOpenAPIDocument(routes(h)))
+}
+
+func (h *handlerRepository) metricsHandler(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	writer.Header().Set("Content-Type", metrics.ContentType)
+	h.logRequest(request, 200)
+	h.hubMetrics.write(writer)
+}
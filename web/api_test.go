@@ -5,12 +5,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/vendor"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -30,6 +33,23 @@ func TestRetrieveOneResourceHandlerReturnsHTTPOk(t *testing.T) {
 	testRetrieveAllReturnsHTTPOk(t, "/resources/"+apacheID)
 }
 
+func TestSearchResourcesHandlerReturnsHTTPOk(t *testing.T) {
+	testRetrieveAllReturnsHTTPOk(t, "/resources/search?q=apache")
+}
+
+func TestSearchResourcesHandlerReturnsMatchingResources(t *testing.T) {
+	request, _ := http.NewRequest("GET", "/resources/search?q=database", nil)
+	recorder := httptest.NewRecorder()
+
+	router := NewRouter()
+	router.ServeHTTP(recorder, request)
+
+	var result []*resource.Resource
+	json.Unmarshal(recorder.Body.Bytes(), &result)
+	assert.Len(t, result, 1)
+	assert.Equal(t, "mongodb", result[0].ID)
+}
+
 func TestRetrieveFalcoRulesForHelmChartHandlerReturnsHTTPOk(t *testing.T) {
 	apacheID := "apache"
 	testRetrieveAllReturnsHTTPOk(t, "/resources/"+apacheID+"/custom-rules.yaml")
@@ -61,8 +81,19 @@ func TestRetrieveAllResourcesHandlerReturnsResourcesSerializedAsJSON(t *testing.
 	testRetrieveallSerializedAsJSON(t, "/resources", "../test/fixtures/resources")
 }
 
-func TestRetrieveAllVendorsHandlerReturnsResourcesSerializedAsJSON(t *testing.T) {
-	testRetrieveallSerializedAsJSON(t, "/vendors", "../test/fixtures/vendors")
+func TestRetrieveAllVendorsHandlerReturnsVendorsSerializedAsJSON(t *testing.T) {
+	repo, _ := vendor.FromPath("../test/fixtures/vendors")
+	vendors, _ := repo.FindAll()
+
+	request, _ := http.NewRequest("GET", "/vendors", nil)
+	recorder := httptest.NewRecorder()
+	router := NewRouter()
+	router.ServeHTTP(recorder, request)
+
+	var result []*vendor.Vendor
+	body, _ := ioutil.ReadAll(recorder.Body)
+	json.Unmarshal([]byte(body), &result)
+	assert.Equal(t, vendors, result)
 }
 
 func testRetrieveallSerializedAsJSON(t *testing.T, urlPath, fixturesPath string) {
@@ -74,10 +105,117 @@ func testRetrieveallSerializedAsJSON(t *testing.T, urlPath, fixturesPath string)
 	router := NewRouter()
 	router.ServeHTTP(recorder, request)
 
-	var result []*resource.Resource
-	body, _ := ioutil.ReadAll(recorder.Body)
-	json.Unmarshal([]byte(body), &result)
-	assert.Equal(t, resources, result)
+	expected, _ := json.Marshal(resources)
+	assert.JSONEq(t, string(expected), recorder.Body.String())
+}
+
+func TestRetrieveAllResourcesHandlerFiltersAndSorts(t *testing.T) {
+	recorder := serve(NewRouter(), "GET", "/resources?keyword=database&kind=FalcoRules", "")
+	var filtered []*resource.Resource
+	json.Unmarshal(recorder.Body.Bytes(), &filtered)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "1", recorder.Header().Get("X-Total-Count"))
+	assert.Len(t, filtered, 1)
+	assert.Equal(t, "MongoDB", filtered[0].Name)
+
+	recorder = serve(NewRouter(), "GET", "/resources?sort=-name", "")
+	var sorted []*resource.Resource
+	json.Unmarshal(recorder.Body.Bytes(), &sorted)
+
+	assert.Equal(t, []string{"MongoDB", "Apache"}, []string{sorted[0].Name, sorted[1].Name})
+}
+
+func TestRetrieveAllResourcesHandlerSortsByDeliveredDownloads(t *testing.T) {
+	router := NewRouter()
+	etag := serve(router, "GET", "/resources/apache/custom-rules.yaml", "").Header().Get("ETag")
+	serve(router, "GET", "/resources/mongodb/custom-rules.yaml", "")
+	serve(router, "GET", "/resources/mongodb/rules.yaml", "")
+	for i := 0; i < 3; i++ {
+		serveWithHeaders(router, "/resources/apache/custom-rules.yaml", map[string]string{"If-None-Match": etag})
+	}
+
+	recorder := serve(router, "GET", "/resources?sort=-popularity", "")
+	var sorted []*resource.Resource
+	json.Unmarshal(recorder.Body.Bytes(), &sorted)
+
+	assert.Equal(t, []string{"MongoDB", "Apache"}, []string{sorted[0].Name, sorted[1].Name})
+}
+
+func TestRetrieveAllResourcesHandlerPaginates(t *testing.T) {
+	recorder := serve(NewRouter(), "GET", "/resources?sort=name&limit=1&offset=1", "")
+	var resources []*resource.Resource
+	json.Unmarshal(recorder.Body.Bytes(), &resources)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Len(t, resources, 1)
+	assert.Equal(t, "MongoDB", resources[0].Name)
+	assert.Equal(t, "2", recorder.Header().Get("X-Total-Count"))
+	assert.Equal(t, `</resources?limit=1&offset=0&sort=name>; rel="first", `+
+		`</resources?limit=1&offset=0&sort=name>; rel="prev", `+
+		`</resources?limit=1&offset=1&sort=name>; rel="last"`, recorder.Header().Get("Link"))
+}
+
+func TestRetrieveAllVendorsHandlerPaginates(t *testing.T) {
+	recorder := serve(NewRouter(), "GET", "/vendors?limit=1", "")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "2", recorder.Header().Get("X-Total-Count"))
+	assert.Contains(t, recorder.Header().Get("Link"), `</vendors?limit=1&offset=1>; rel="next"`)
+}
+
+func TestListHandlersReturnBadRequestWithInvalidListOptions(t *testing.T) {
+	testReturnsError(t, "GET", "/resources?limit=many", http.StatusBadRequest, "invalid_request")
+	testReturnsError(t, "GET", "/resources?sort=size", http.StatusBadRequest, "invalid_request")
+	testReturnsError(t, "GET", "/resources?sort=updated", http.StatusBadRequest, "invalid_request")
+	testReturnsError(t, "GET", "/vendors?sort=popularity", http.StatusBadRequest, "invalid_request")
+	testReturnsError(t, "GET", "/vendors/apache/resources?limit=1000", http.StatusBadRequest, "invalid_request")
+}
+
+func TestRetrieveKeywordsHandler(t *testing.T) {
+	recorder := serve(NewRouter(), "GET", "/keywords", "")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `[{"keyword": "database", "resources": 1}, {"keyword": "web", "resources": 1}]`, recorder.Body.String())
+}
+
+func TestRetrieveResourcesWithKeywordHandler(t *testing.T) {
+	recorder := serve(NewRouter(), "GET", "/keywords/web/resources", "")
+	var resources []*resource.Resource
+	json.Unmarshal(recorder.Body.Bytes(), &resources)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Len(t, resources, 1)
+	assert.Equal(t, "Apache", resources[0].Name)
+	assert.Equal(t, "1", recorder.Header().Get("X-Total-Count"))
+}
+
+func TestRetrieveResourcesWithKeywordHandlerReturnsNotFound(t *testing.T) {
+	testReturnsError(t, "GET", "/keywords/monitoring/resources", http.StatusNotFound, "not_found")
+}
+
+func TestRetrieveAllMaintainersHandler(t *testing.T) {
+	recorder := serve(NewRouter(), "GET", "/maintainers", "")
+	var maintainers []*resource.MaintainerSummary
+	json.Unmarshal(recorder.Body.Bytes(), &maintainers)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Len(t, maintainers, 2)
+	assert.Equal(t, "fede.barcelona@sysdig.com", maintainers[0].ID)
+	assert.Equal(t, []string{"apache", "mongodb"}, maintainers[0].Resources)
+}
+
+func TestRetrieveAllResourcesFromMaintainerHandler(t *testing.T) {
+	recorder := serve(NewRouter(), "GET", "/maintainers/nestor.salceda@sysdig.com/resources?sort=-name", "")
+	var resources []*resource.Resource
+	json.Unmarshal(recorder.Body.Bytes(), &resources)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, []string{"mongodb", "apache"}, []string{resources[0].ID, resources[1].ID})
+}
+
+func TestRetrieveAllResourcesFromMaintainerHandlerReturnsNotFound(t *testing.T) {
+	testReturnsError(t, "GET", "/maintainers/nobody@sysdig.com/resources", http.StatusNotFound, "not_found")
 }
 
 func TestRetrieveAllResourcesHandlerReturnsAJSONResponse(t *testing.T) {
@@ -147,3 +285,532 @@ func TestHealthCheckEndpoint(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, recorder.Code)
 }
+
+const nginxResource = `{
+  "kind": "FalcoRules",
+  "vendor": "Nginx",
+  "name": "Nginx",
+  "icon": "https://nginx.org/icon.png",
+  "maintainers": [{"name": "bencer", "email": "bencer@sysdig.com"}],
+  "rules": [{"raw": "- macro: nginx_consider_syscalls\n  condition: (evt.num < 0)"}]
+}`
+
+func TestCreateResourceHandlerReturnsHTTPCreated(t *testing.T) {
+	router, cleanup := newRouterWithWritableResources(t)
+	defer cleanup()
+
+	recorder := serve(router, "POST", "/resources", nginxResource)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+
+	recorder = serve(router, "GET", "/resources/nginx", "")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestCreateResourceHandlerReturnsValidationErrors(t *testing.T) {
+	router, cleanup := newRouterWithWritableResources(t)
+	defer cleanup()
+
+	recorder := serve(router, "POST", "/resources", `{"name": "Nginx"}`)
+
+	var result errorResponse
+	json.Unmarshal(recorder.Body.Bytes(), &result)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, result.Details, "the resource must have a defined Kind")
+}
+
+func TestCreateResourceHandlerReportsEmptyMaintainers(t *testing.T) {
+	router, cleanup := newRouterWithWritableResources(t)
+	defer cleanup()
+
+	recorder := serve(router, "POST", "/resources", strings.Replace(nginxResource, `"maintainers": [`, `"maintainers": [null, `, 1))
+
+	var result errorResponse
+	json.Unmarshal(recorder.Body.Bytes(), &result)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, result.Details, "the maintainer 1 must have a name and an email")
+}
+
+func TestCreateResourceHandlerRejectsTheIDsOfTheAPI(t *testing.T) {
+	router, cleanup := newRouterWithWritableResources(t)
+	defer cleanup()
+
+	recorder := serve(router, "POST", "/resources", strings.Replace(nginxResource, `"name": "Nginx",`, `"name": "Search",`, 1))
+
+	var result errorResponse
+	json.Unmarshal(recorder.Body.Bytes(), &result)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, result.Details, `the resource ID "search" is reserved by the API`)
+}
+
+func TestCreateResourceHandlerRejectsInvalidJSON(t *testing.T) {
+	router, cleanup := newRouterWithWritableResources(t)
+	defer cleanup()
+
+	recorder := serve(router, "POST", "/resources", `{`)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestUpdateResourceHandlerReturnsHTTPOk(t *testing.T) {
+	router, cleanup := newRouterWithWritableResources(t)
+	defer cleanup()
+	serve(router, "POST", "/resources", nginxResource)
+
+	updated := strings.Replace(nginxResource, `"vendor": "Nginx"`, `"vendor": "F5"`, 1)
+	recorder := serve(router, "PUT", "/resources/nginx", updated)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = serve(router, "GET", "/resources/nginx", "")
+	assert.Contains(t, recorder.Body.String(), `"vendor":"F5"`)
+}
+
+func TestDeleteResourceHandlerReturnsHTTPNoContent(t *testing.T) {
+	router, cleanup := newRouterWithWritableResources(t)
+	defer cleanup()
+
+	recorder := serve(router, "DELETE", "/resources/apache", "")
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+
+	recorder = serve(router, "GET", "/resources/apache", "")
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func serve(router http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	request, _ := http.NewRequest(method, path, strings.NewReader(body))
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+const testWriteToken = "test-token"
+
+// newRouterWithWritableResources serves a copy of the resource fixtures with
+// writes enabled, sending the write token with every request, so tests can
+// modify them.
+func newRouterWithWritableResources(t *testing.T) (http.Handler, func()) {
+	dir, err := ioutil.TempDir("", "resources")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob("../test/fixtures/resources/*.yaml")
+	for _, file := range files {
+		content, _ := ioutil.ReadFile(file)
+		ioutil.WriteFile(filepath.Join(dir, filepath.Base(file)), content, 0644)
+	}
+
+	previous := os.Getenv("RESOURCES_PATH")
+	os.Setenv("RESOURCES_PATH", dir)
+	os.Setenv("ENABLE_WRITES", "true")
+	os.Setenv("WRITE_TOKEN", testWriteToken)
+	router := NewRouter()
+	os.Setenv("RESOURCES_PATH", previous)
+	os.Unsetenv("ENABLE_WRITES")
+	os.Unsetenv("WRITE_TOKEN")
+
+	authenticated := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		request.Header.Set("Authorization", "Bearer "+testWriteToken)
+		router.ServeHTTP(writer, request)
+	})
+	return authenticated, func() { os.RemoveAll(dir) }
+}
+
+func TestWriteEndpointsAreDisabledByDefault(t *testing.T) {
+	testReturnsError(t, "POST", "/resources", http.StatusMethodNotAllowed, "method_not_allowed")
+	testReturnsError(t, "DELETE", "/resources/apache", http.StatusMethodNotAllowed, "method_not_allowed")
+}
+
+func TestWriteEndpointsRequireTheWriteToken(t *testing.T) {
+	os.Setenv("ENABLE_WRITES", "true")
+	os.Setenv("WRITE_TOKEN", testWriteToken)
+	router := NewRouter()
+	os.Unsetenv("ENABLE_WRITES")
+	os.Unsetenv("WRITE_TOKEN")
+
+	for _, authorization := range []string{"", testWriteToken, "Bearer wrong"} {
+		request, _ := http.NewRequest("DELETE", "/resources/apache", nil)
+		request.Header.Set("Authorization", authorization)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code, "Authorization: %s", authorization)
+		assert.NotEmpty(t, recorder.Header().Get("WWW-Authenticate"))
+	}
+	assert.Equal(t, http.StatusOK, serve(router, "GET", "/resources/apache", "").Code)
+}
+
+func TestCORSDoesNotAllowWritesFromOtherOrigins(t *testing.T) {
+	request, _ := http.NewRequest("OPTIONS", "/resources/apache", nil)
+	request.Header.Set("Origin", "https://example.com")
+	request.Header.Set("Access-Control-Request-Method", "DELETE")
+	recorder := httptest.NewRecorder()
+
+	NewRouter().ServeHTTP(recorder, request)
+
+	assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestRetrieveOneResourceHandlerReturnsNotFound(t *testing.T) {
+	testReturnsError(t, "GET", "/resources/nginx", http.StatusNotFound, "not_found")
+}
+
+func TestRetrieveOneVendorsHandlerReturnsNotFound(t *testing.T) {
+	testReturnsError(t, "GET", "/vendors/nginx", http.StatusNotFound, "not_found")
+}
+
+func TestRetrieveAllResourcesFromVendorHandlerReturnsNotFound(t *testing.T) {
+	testReturnsError(t, "GET", "/vendors/nginx/resources", http.StatusNotFound, "not_found")
+}
+
+func TestRetrieveFalcoRulesForHelmChartHandlerReturnsNotFound(t *testing.T) {
+	testReturnsError(t, "GET", "/resources/nginx/custom-rules.yaml", http.StatusNotFound, "not_found")
+}
+
+func TestSearchResourcesHandlerReturnsBadRequestWithoutQuery(t *testing.T) {
+	testReturnsError(t, "GET", "/resources/search", http.StatusBadRequest, "invalid_request")
+}
+
+func TestUnknownRouteReturnsNotFound(t *testing.T) {
+	testReturnsError(t, "GET", "/unknown", http.StatusNotFound, "not_found")
+}
+
+func TestMethodNotAllowedReturnsJSON(t *testing.T) {
+	testReturnsError(t, "DELETE", "/vendors/apache", http.StatusMethodNotAllowed, "method_not_allowed")
+}
+
+func TestWritesToReadOnlyRepositoriesAreForbidden(t *testing.T) {
+	status, response := newErrorResponse(fmt.Errorf("%w: resources are read from git", resource.ErrReadOnly), "request")
+
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Equal(t, "read_only", response.Code)
+}
+
+func TestCreateResourceHandlerReturnsConflictIfResourceExists(t *testing.T) {
+	router, cleanup := newRouterWithWritableResources(t)
+	defer cleanup()
+	serve(router, "POST", "/resources", nginxResource)
+
+	recorder := serve(router, "POST", "/resources", nginxResource)
+
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+}
+
+func TestErrorResponsesIncludeTheRequestID(t *testing.T) {
+	request, _ := http.NewRequest("GET", "/resources/nginx", nil)
+	request.Header.Set("X-Request-Id", "abc123")
+	recorder := httptest.NewRecorder()
+
+	NewRouter().ServeHTTP(recorder, request)
+
+	var result errorResponse
+	json.Unmarshal(recorder.Body.Bytes(), &result)
+	assert.Equal(t, "abc123", result.RequestID)
+	assert.Equal(t, "abc123", recorder.Header().Get("X-Request-Id"))
+}
+
+func testReturnsError(t *testing.T, method, path string, statusCode int, code string) {
+	recorder := serve(NewRouter(), method, path, "")
+
+	var result errorResponse
+	json.Unmarshal(recorder.Body.Bytes(), &result)
+	assert.Equal(t, statusCode, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, code, result.Code)
+	assert.NotEmpty(t, result.Message)
+	assert.NotEmpty(t, result.RequestID)
+}
+
+func TestRetrieveOneResourceHandlerIncludesParsedRules(t *testing.T) {
+	recorder := serve(NewRouter(), "GET", "/resources/apache", "")
+
+	var result struct {
+		Rules []struct {
+			Parsed []struct {
+				Type string
+				Name string
+			}
+		}
+	}
+	json.Unmarshal(recorder.Body.Bytes(), &result)
+	assert.Equal(t, "macro", result.Rules[0].Parsed[0].Type)
+	assert.Equal(t, "apache_consider_syscalls", result.Rules[0].Parsed[0].Name)
+}
+
+func TestRetrieveResourceDependenciesHandlerReturnsDependencies(t *testing.T) {
+	router, cleanup := newRouterWithWritableResources(t)
+	defer cleanup()
+	withDependencies := strings.Replace(nginxResource, `(evt.num < 0)`, `spawned_process and apache_consider_syscalls`, 1)
+	serve(router, "POST", "/resources", withDependencies)
+
+	recorder := serve(router, "GET", "/resources/nginx/dependencies", "")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{
+		"resource": "nginx",
+		"dependencies": [
+			{"type": "macro", "name": "apache_consider_syscalls", "source": "hub", "resources": ["apache"], "usedBy": ["nginx_consider_syscalls"]},
+			{"type": "macro", "name": "spawned_process", "source": "falco", "usedBy": ["nginx_consider_syscalls"]}
+		]
+	}`, recorder.Body.String())
+}
+
+func TestCreateResourceHandlerRejectsUnresolvedMacros(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "falco")
+	defer os.RemoveAll(dir)
+	falcoRules := filepath.Join(dir, "falco_rules.yaml")
+	ioutil.WriteFile(falcoRules, []byte("- macro: spawned_process\n  condition: evt.type = execve\n"), 0644)
+	os.Setenv("FALCO_RULES_PATH", falcoRules)
+	router, cleanup := newRouterWithWritableResources(t)
+	os.Unsetenv("FALCO_RULES_PATH")
+	defer cleanup()
+	withUnknownMacro := strings.Replace(nginxResource, `(evt.num < 0)`, `nginx_shell`, 1)
+
+	recorder := serve(router, "POST", "/resources", withUnknownMacro)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `the macro \"nginx_shell\" used by \"nginx_consider_syscalls\" is not defined`)
+}
+
+func TestCreateResourceHandlerAcceptsUnknownMacrosWithoutFalcoRules(t *testing.T) {
+	router, cleanup := newRouterWithWritableResources(t)
+	defer cleanup()
+	withFalcoMacro := strings.Replace(nginxResource, `(evt.num < 0)`, `container_entrypoint`, 1)
+
+	recorder := serve(router, "POST", "/resources", withFalcoMacro)
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+}
+
+func TestRetrieveResourceDependenciesHandlerReturnsNotFound(t *testing.T) {
+	testReturnsError(t, "GET", "/resources/notFound/dependencies", http.StatusNotFound, "not_found")
+}
+
+const apacheAndMongoDBBundle = `customRules:
+  rules-apache.yaml: |-
+    - macro: apache_consider_syscalls
+      condition: (evt.num < 0)
+  rules-mongodb.yaml: |-
+    - macro: mongo_consider_syscalls
+      condition: (evt.num < 0)
+`
+
+func TestRetrieveFalcoRulesBundleForHelmChartReturnsContent(t *testing.T) {
+	recorder := serve(NewRouter(), "GET", "/bundles/custom-rules.yaml?resources=apache,mongodb", "")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/x-yaml", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, apacheAndMongoDBBundle, recorder.Body.String())
+}
+
+func TestCreateFalcoRulesBundleForHelmChartReturnsContent(t *testing.T) {
+	recorder := serve(NewRouter(), "POST", "/bundles/custom-rules.yaml", `{"resources": ["apache", "mongodb"]}`)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, apacheAndMongoDBBundle, recorder.Body.String())
+}
+
+func TestFalcoRulesBundleForHelmChartReturnsConflicts(t *testing.T) {
+	router, cleanup := newRouterWithWritableResources(t)
+	defer cleanup()
+	serve(router, "POST", "/resources", strings.Replace(nginxResource, "nginx_consider_syscalls", "apache_consider_syscalls", 1))
+
+	recorder := serve(router, "GET", "/bundles/custom-rules.yaml?resources=apache,nginx", "")
+
+	var result errorResponse
+	json.Unmarshal(recorder.Body.Bytes(), &result)
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+	assert.Equal(t, "conflict", result.Code)
+	assert.Equal(t, []string{`the macro "apache_consider_syscalls" is defined by both "apache" and "nginx"`}, result.Details)
+}
+
+func TestFalcoRulesBundleForHelmChartReturnsBadRequestWithoutResources(t *testing.T) {
+	testReturnsError(t, "GET", "/bundles/custom-rules.yaml", http.StatusBadRequest, "invalid_request")
+}
+
+func TestFalcoRulesBundleForHelmChartReturnsNotFound(t *testing.T) {
+	testReturnsError(t, "GET", "/bundles/custom-rules.yaml?resources=apache,notFound", http.StatusNotFound, "not_found")
+}
+
+func TestExportResourceAsFalcoRulesFile(t *testing.T) {
+	recorder := serve(NewRouter(), "GET", "/resources/apache/rules.yaml", "")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/x-yaml", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "- macro: apache_consider_syscalls\n  condition: (evt.num < 0)\n", recorder.Body.String())
+}
+
+func TestExportResourceAsConfigMapWithNameAndNamespace(t *testing.T) {
+	recorder := serve(NewRouter(), "GET", "/resources/apache/configmap.yaml?name=apache-rules&namespace=falco", "")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: apache-rules
+  namespace: falco
+data:
+  rules-apache.yaml: |-
+    - macro: apache_consider_syscalls
+      condition: (evt.num < 0)
+`, recorder.Body.String())
+}
+
+func TestExportResourceAsConfigMapReturnsBadRequestWithInvalidName(t *testing.T) {
+	testReturnsError(t, "GET", "/resources/apache/configmap.yaml?name=Apache_Rules", http.StatusBadRequest, "invalid_request")
+}
+
+func TestExportResourceReturnsNotFoundForFormatsOfAnotherKind(t *testing.T) {
+	testReturnsError(t, "GET", "/resources/apache/policies.yaml", http.StatusNotFound, "not_found")
+}
+
+func TestExportBundleAsKustomization(t *testing.T) {
+	recorder := serve(NewRouter(), "GET", "/bundles/kustomization.yaml?resources=apache,mongodb&namespace=falco", "")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "kind: Kustomization\nnamespace: falco\n")
+	assert.Contains(t, recorder.Body.String(), "- name: falco-rules\n")
+}
+
+func TestExportBundleAsFalcoOperatorRulesfile(t *testing.T) {
+	recorder := serve(NewRouter(), "GET", "/bundles/rulesfile.yaml?resources=apache,mongodb&namespace=falco", "")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "kind: Rulesfile\nmetadata:\n  name: falco-rules\n  namespace: falco\n")
+	assert.Contains(t, recorder.Body.String(), "      condition: (evt.num < 0)\n    - macro: mongo_consider_syscalls\n")
+}
+
+func TestResourceVersionsHandlers(t *testing.T) {
+	router, cleanup := newRouterWithWritableResources(t)
+	defer cleanup()
+	for _, version := range []string{"1.0.0", "1.1.0"} {
+		withVersion := strings.Replace(nginxResource, `"name": "Nginx",`, `"name": "Nginx", "version": "`+version+`",`, 1)
+		withVersion = strings.Replace(withVersion, "nginx_consider_syscalls", "nginx_"+strings.Replace(version, ".", "_", -1), 1)
+		assert.Equal(t, http.StatusCreated, serve(router, "POST", "/resources", withVersion).Code)
+	}
+
+	recorder := serve(router, "GET", "/resources/nginx/versions", "")
+	var versions []map[string]interface{}
+	json.Unmarshal(recorder.Body.Bytes(), &versions)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Len(t, versions, 2)
+	assert.Equal(t, "1.1.0", versions[0]["version"])
+
+	recorder = serve(router, "GET", "/resources/nginx/versions/1.0.0", "")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"version":"1.0.0"`)
+
+	recorder = serve(router, "GET", "/resources/nginx", "")
+	assert.Contains(t, recorder.Body.String(), `"version":"1.1.0"`)
+
+	recorder = serve(router, "GET", "/resources/nginx/custom-rules.yaml?version=1.0.0", "")
+	assert.Contains(t, recorder.Body.String(), "nginx_1_0_0")
+}
+
+func TestResourceVersionHandlerReturnsNotFound(t *testing.T) {
+	testReturnsError(t, "GET", "/resources/apache/versions/9.9.9", http.StatusNotFound, "not_found")
+}
+
+func TestResourceVersionHandlerReturnsBadRequestWithInvalidVersion(t *testing.T) {
+	testReturnsError(t, "GET", "/resources/apache/custom-rules.yaml?version=latest", http.StatusBadRequest, "invalid_request")
+}
+
+func TestCompareResourceVersionsHandler(t *testing.T) {
+	router, cleanup := newRouterWithWritableResources(t)
+	defer cleanup()
+	for _, version := range []string{"1.0.0", "1.1.0"} {
+		withVersion := strings.Replace(nginxResource, `"name": "Nginx",`, `"name": "Nginx", "version": "`+version+`",`, 1)
+		withVersion = strings.Replace(withVersion, "nginx_consider_syscalls", "nginx_"+strings.Replace(version, ".", "_", -1), 1)
+		assert.Equal(t, http.StatusCreated, serve(router, "POST", "/resources", withVersion).Code)
+	}
+
+	recorder := serve(router, "GET", "/resources/nginx/diff?from=1.0.0&to=1.1.0", "")
+
+	var result map[string]interface{}
+	json.Unmarshal(recorder.Body.Bytes(), &result)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "nginx_1_1_0", result["added"].([]interface{})[0].(map[string]interface{})["name"])
+	assert.Equal(t, "nginx_1_0_0", result["removed"].([]interface{})[0].(map[string]interface{})["name"])
+	assert.Contains(t, result["unified"], "--- nginx 1.0.0\n+++ nginx 1.1.0\n")
+}
+
+func TestCompareResourceVersionsHandlerReturnsBadRequestWithoutVersions(t *testing.T) {
+	testReturnsError(t, "GET", "/resources/apache/diff?from=1.0.0", http.StatusBadRequest, "invalid_request")
+}
+
+func TestAliasesRedirectToTheCurrentResourceURL(t *testing.T) {
+	router, cleanup := newRouterWithWritableResources(t)
+	defer cleanup()
+	withAlias := strings.Replace(nginxResource, `"name": "Nginx",`, `"name": "Nginx", "aliases": ["nginx-ingress"],`, 1)
+	assert.Equal(t, http.StatusCreated, serve(router, "POST", "/resources", withAlias).Code)
+
+	recorder := serve(router, "GET", "/resources/nginx-ingress", "")
+	assert.Equal(t, http.StatusMovedPermanently, recorder.Code)
+	assert.Equal(t, "/resources/nginx", recorder.Header().Get("Location"))
+
+	recorder = serve(router, "GET", "/resources/nginx-ingress/custom-rules.yaml?name=x", "")
+	assert.Equal(t, http.StatusMovedPermanently, recorder.Code)
+	assert.Equal(t, "/resources/nginx/custom-rules.yaml?name=x", recorder.Header().Get("Location"))
+}
+
+func TestVendorAliasesRedirectToTheCurrentVendorURL(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "vendors")
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "apache.yaml"), []byte("kind: Vendor\nname: Apache\nicon: apache.png\naliases: [apache-foundation]\n"), 0644)
+	os.Setenv("VENDOR_PATH", dir)
+	router := NewRouter()
+	os.Setenv("VENDOR_PATH", "../test/fixtures/vendors")
+
+	recorder := serve(router, "GET", "/vendors/apache-foundation/resources?sort=name", "")
+
+	assert.Equal(t, http.StatusMovedPermanently, recorder.Code)
+	assert.Equal(t, "/vendors/apache/resources?sort=name", recorder.Header().Get("Location"))
+}
+
+func TestCreateResourceHandlerRejectsIDsWhichAreNotURLSafe(t *testing.T) {
+	router, cleanup := newRouterWithWritableResources(t)
+	defer cleanup()
+
+	withInvalidID := strings.Replace(nginxResource, `"name": "Nginx",`, `"name": "Nginx", "id": "nginx/ingress",`, 1)
+	recorder := serve(router, "POST", "/resources", withInvalidID)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "validation_failed")
+}
+
+func TestRetrieveDiagnosticsHandlerReportsUnknownVendors(t *testing.T) {
+	router, cleanup := newRouterWithWritableResources(t)
+	defer cleanup()
+	withUnknownVendor := strings.Replace(nginxResource, `"vendor": "Nginx"`, `"vendor": "F5"`, 1)
+	assert.Equal(t, http.StatusCreated, serve(router, "POST", "/resources", withUnknownVendor).Code)
+
+	recorder := serve(router, "GET", "/admin/diagnostics", "")
+
+	var diagnostics []map[string]interface{}
+	json.Unmarshal(recorder.Body.Bytes(), &diagnostics)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Len(t, diagnostics, 1)
+	assert.Equal(t, "unknown_vendor", diagnostics[0]["code"])
+}
+
+func TestRetrieveDiagnosticsHandlerReturnsAnEmptyList(t *testing.T) {
+	router, cleanup := newRouterWithWritableResources(t)
+	defer cleanup()
+
+	recorder := serve(router, "GET", "/admin/diagnostics", "")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "[]\n", recorder.Body.String())
+}
+
+func TestRetrieveDiagnosticsHandlerRequiresTheWriteToken(t *testing.T) {
+	testReturnsError(t, "GET", "/admin/diagnostics", http.StatusNotFound, "not_found")
+
+	os.Setenv("ENABLE_WRITES", "true")
+	os.Setenv("WRITE_TOKEN", testWriteToken)
+	router := NewRouter()
+	os.Unsetenv("ENABLE_WRITES")
+	os.Unsetenv("WRITE_TOKEN")
+
+	recorder := serve(router, "GET", "/admin/diagnostics", "")
+
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
@@ -0,0 +1,55 @@
+package web
+
+import (
+	"crypto/subtle"
+	"github.com/julienschmidt/httprouter"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// writeToken returns the token the write endpoints require, set in
+// WRITE_TOKEN. It is empty unless ENABLE_WRITES is true, leaving the write
+// endpoints unregistered.
+func writeToken() string {
+	value, ok := os.LookupEnv("ENABLE_WRITES")
+	if !ok {
+		return ""
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Println("The ENABLE_WRITES env var must be true or false")
+		os.Exit(1)
+	}
+	if !enabled {
+		return ""
+	}
+
+	token := os.Getenv("WRITE_TOKEN")
+	if token == "" {
+		log.Println("The WRITE_TOKEN env var must be set when ENABLE_WRITES is true")
+		os.Exit(1)
+	}
+	return token
+}
+
+// requireToken only calls handle for requests sending the token as a bearer
+// token in the Authorization header.
+func (h *handlerRepository) requireToken(token string, handle httprouter.Handle) httprouter.Handle {
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		header := request.Header.Get("Authorization")
+		sent := strings.TrimPrefix(header, "Bearer ")
+		if sent == header || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+			writer.Header().Set("WWW-Authenticate", `Bearer realm="cloud-native-security-hub"`)
+			h.writeErrorResponse(writer, request, http.StatusUnauthorized, &errorResponse{
+				Code:      "unauthorized",
+				Message:   "a valid write token must be sent as a bearer token",
+				RequestID: requestID(request),
+			})
+			return
+		}
+		handle(writer, request, params)
+	}
+}
@@ -0,0 +1,146 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultCacheControl lets caches keep responses, as long as they check with
+// the server before using them again. Unchanged responses are then answered
+// with a 304 and no body.
+const defaultCacheControl = "no-cache"
+
+// cacheControl returns the Cache-Control header of the read endpoints, set
+// in CACHE_CONTROL.
+func cacheControl() string {
+	if value, ok := os.LookupEnv("CACHE_CONTROL"); ok {
+		return value
+	}
+	return defaultCacheControl
+}
+
+// unvalidatedPaths serve responses which don't derive from the resources and
+// vendors, so the time the hub last changed says nothing about them, or which
+// require the write token and must not be kept by shared caches.
+var unvalidatedPaths = map[string]bool{
+	"/health":            true,
+	"/openapi.json":      true,
+	"/metrics":           true,
+	"/admin/diagnostics": true,
+}
+
+// validated tells whether the responses of the route carry validators and
+// answer conditional requests.
+func validated(route route) bool {
+	return route.method == http.MethodGet && !unvalidatedPaths[route.path]
+}
+
+// bufferedResponse holds a response until it is known whether the client
+// already has it.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *bufferedResponse) Header() http.Header {
+	return r.header
+}
+
+func (r *bufferedResponse) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *bufferedResponse) Write(content []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return r.body.Write(content)
+}
+
+type deliveredKey struct{}
+
+// onDelivered calls delivered once the content of the response is sent to the
+// client, which doesn't happen when the client is told it already has it with
+// a 304 Not Modified.
+func onDelivered(request *http.Request, delivered func()) {
+	if hooks, ok := request.Context().Value(deliveredKey{}).(*[]func()); ok {
+		*hooks = append(*hooks, delivered)
+		return
+	}
+	delivered()
+}
+
+// withValidators tags successful responses with a strong ETag hashing their
+// content and a Last-Modified with the time the hub last changed, answering
+// 304 Not Modified when the client sends them back and the response didn't
+// change.
+func withValidators(handle httprouter.Handle, lastModified func() time.Time, cacheControl string) httprouter.Handle {
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		var delivered []func()
+		request = request.WithContext(context.WithValue(request.Context(), deliveredKey{}, &delivered))
+		response := &bufferedResponse{header: writer.Header()}
+		handle(response, request, params)
+		if response.status == 0 {
+			response.status = http.StatusOK
+		}
+		if response.status != http.StatusOK {
+			writer.WriteHeader(response.status)
+			writer.Write(response.body.Bytes())
+			return
+		}
+
+		etag := contentETag(response.body.Bytes())
+		modified := lastModified().UTC().Truncate(time.Second)
+		writer.Header().Set("ETag", etag)
+		if !modified.IsZero() {
+			writer.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+		}
+		if cacheControl != "" {
+			writer.Header().Set("Cache-Control", cacheControl)
+		}
+
+		if notModified(request, etag, modified) {
+			writer.Header().Del("Content-Type")
+			writer.WriteHeader(http.StatusNotModified)
+			return
+		}
+		writer.WriteHeader(http.StatusOK)
+		writer.Write(response.body.Bytes())
+		for _, fn := range delivered {
+			fn()
+		}
+	}
+}
+
+func contentETag(content []byte) string {
+	sum := sha256.Sum256(content)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// notModified evaluates the conditions of the request as RFC 7232 does:
+// If-Modified-Since is ignored when If-None-Match is sent.
+func notModified(request *http.Request, etag string, modified time.Time) bool {
+	if header := request.Header.Get("If-None-Match"); header != "" {
+		for _, candidate := range strings.Split(header, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if header := request.Header.Get("If-Modified-Since"); header != "" && !modified.IsZero() {
+		since, err := http.ParseTime(header)
+		return err == nil && !modified.After(since)
+	}
+	return false
+}
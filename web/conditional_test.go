@@ -0,0 +1,112 @@
+package web
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func serveWithHeaders(router http.Handler, path string, headers map[string]string) *httptest.ResponseRecorder {
+	request, _ := http.NewRequest("GET", path, nil)
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestReadEndpointsSendValidators(t *testing.T) {
+	result := serve(NewRouter(), "GET", "/resources/apache/custom-rules.yaml", "")
+
+	assert.Equal(t, http.StatusOK, result.Code)
+	assert.Equal(t, contentETag(result.Body.Bytes()), result.Header().Get("ETag"))
+	assert.NotEmpty(t, result.Header().Get("Last-Modified"))
+	assert.Equal(t, "no-cache", result.Header().Get("Cache-Control"))
+}
+
+func TestReadEndpointsAnswerNotModifiedForTheSameETag(t *testing.T) {
+	router := NewRouter()
+	etag := serve(router, "GET", "/resources", "").Header().Get("ETag")
+
+	result := serveWithHeaders(router, "/resources", map[string]string{"If-None-Match": `"other", ` + etag})
+
+	assert.Equal(t, http.StatusNotModified, result.Code)
+	assert.Empty(t, result.Body.String())
+	assert.Equal(t, etag, result.Header().Get("ETag"))
+}
+
+func TestReadEndpointsAnswerWithContentForAnotherETag(t *testing.T) {
+	result := serveWithHeaders(NewRouter(), "/resources", map[string]string{"If-None-Match": `"other"`})
+
+	assert.Equal(t, http.StatusOK, result.Code)
+	assert.NotEmpty(t, result.Body.String())
+}
+
+func TestReadEndpointsAnswerNotModifiedSinceLastModified(t *testing.T) {
+	router := NewRouter()
+	lastModified := serve(router, "GET", "/vendors", "").Header().Get("Last-Modified")
+	modified, _ := http.ParseTime(lastModified)
+
+	unchanged := serveWithHeaders(router, "/vendors", map[string]string{"If-Modified-Since": lastModified})
+	changed := serveWithHeaders(router, "/vendors", map[string]string{"If-Modified-Since": modified.Add(-time.Second).Format(http.TimeFormat)})
+
+	assert.Equal(t, http.StatusNotModified, unchanged.Code)
+	assert.Equal(t, http.StatusOK, changed.Code)
+}
+
+func TestIfNoneMatchTakesPrecedenceOverIfModifiedSince(t *testing.T) {
+	router := NewRouter()
+	lastModified := serve(router, "GET", "/vendors", "").Header().Get("Last-Modified")
+
+	result := serveWithHeaders(router, "/vendors", map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": lastModified})
+
+	assert.Equal(t, http.StatusOK, result.Code)
+}
+
+func TestErrorsAreNotTaggedWithValidators(t *testing.T) {
+	result := serve(NewRouter(), "GET", "/resources/nginx", "")
+
+	assert.Equal(t, http.StatusNotFound, result.Code)
+	assert.Empty(t, result.Header().Get("ETag"))
+}
+
+func TestCacheControlIsConfigurable(t *testing.T) {
+	os.Setenv("CACHE_CONTROL", "public, max-age=300")
+	defer os.Unsetenv("CACHE_CONTROL")
+
+	result := serve(NewRouter(), "GET", "/vendors", "")
+
+	assert.Equal(t, "public, max-age=300", result.Header().Get("Cache-Control"))
+}
+
+func TestWithValidatorsLeavesOutLastModifiedWhenUnknown(t *testing.T) {
+	handle := withValidators(func(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+		writer.Write([]byte("OK"))
+	}, func() time.Time { return time.Time{} }, "")
+	recorder := httptest.NewRecorder()
+
+	handle(recorder, httptest.NewRequest("GET", "/health", nil), nil)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, recorder.Header().Get("Last-Modified"))
+	assert.Empty(t, recorder.Header().Get("Cache-Control"))
+	assert.Equal(t, contentETag([]byte("OK")), recorder.Header().Get("ETag"))
+}
+
+func TestServiceEndpointsIgnoreConditionalRequests(t *testing.T) {
+	router := NewRouter()
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+
+	for _, path := range []string{"/metrics", "/health", "/openapi.json"} {
+		result := serveWithHeaders(router, path, map[string]string{"If-Modified-Since": future, "If-None-Match": "*"})
+
+		assert.Equal(t, http.StatusOK, result.Code, path)
+		assert.Empty(t, result.Header().Get("Last-Modified"), path)
+		assert.Empty(t, result.Header().Get("ETag"), path)
+	}
+}
@@ -0,0 +1,106 @@
+package web
+
+import (
+	"errors"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/usecases"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/vendor"
+	"net/http"
+)
+
+// errorResponse is the body of every response with an error status code.
+type errorResponse struct {
+	Code      string   `json:"code"`
+	Message   string   `json:"message"`
+	Details   []string `json:"details,omitempty"`
+	RequestID string   `json:"requestId"`
+}
+
+type errorMapping struct {
+	errors []error
+	status int
+	code   string
+}
+
+var errorMappings = []errorMapping{
+	{
+		errors: []error{resource.ErrNotFound, resource.ErrEmptyRepository, resource.ErrUnknownFormat, vendor.ErrNotFound, vendor.ErrEmptyRepository, usecases.ErrNoResourcesForVendor, usecases.ErrNoResourcesForKeyword, usecases.ErrNoResourcesForMaintainer},
+		status: http.StatusNotFound,
+		code:   "not_found",
+	},
+	{
+		errors: []error{resource.ErrInvalidID, resource.ErrInvalidVersion, vendor.ErrInvalidID, usecases.ErrInvalidQuery, usecases.ErrInvalidListOptions, usecases.ErrNoResourcesSelected, resource.ErrInvalidExportOption},
+		status: http.StatusBadRequest,
+		code:   "invalid_request",
+	},
+	{
+		errors: []error{resource.ErrAlreadyExists},
+		status: http.StatusConflict,
+		code:   "already_exists",
+	},
+	{
+		errors: []error{resource.ErrReadOnly},
+		status: http.StatusForbidden,
+		code:   "read_only",
+	},
+}
+
+// newErrorResponse maps an error to its status code and response body. The
+// message of unexpected errors is not exposed, as it may leak internals.
+func newErrorResponse(err error, requestID string) (int, *errorResponse) {
+	var validationError *resource.ValidationError
+	if errors.As(err, &validationError) {
+		return http.StatusBadRequest, &errorResponse{
+			Code:      "validation_failed",
+			Message:   "the resource is not valid",
+			Details:   validationError.Errors,
+			RequestID: requestID,
+		}
+	}
+
+	var movedError *resource.MovedError
+	if errors.As(err, &movedError) {
+		return http.StatusNotFound, &errorResponse{
+			Code:      "not_found",
+			Message:   movedError.Error(),
+			RequestID: requestID,
+		}
+	}
+
+	var vendorMovedError *vendor.MovedError
+	if errors.As(err, &vendorMovedError) {
+		return http.StatusNotFound, &errorResponse{
+			Code:      "not_found",
+			Message:   vendorMovedError.Error(),
+			RequestID: requestID,
+		}
+	}
+
+	var conflictError *resource.ConflictError
+	if errors.As(err, &conflictError) {
+		return http.StatusConflict, &errorResponse{
+			Code:      "conflict",
+			Message:   "the selected resources define the same rules, macros or lists",
+			Details:   conflictError.Conflicts,
+			RequestID: requestID,
+		}
+	}
+
+	for _, mapping := range errorMappings {
+		for _, known := range mapping.errors {
+			if errors.Is(err, known) {
+				return mapping.status, &errorResponse{
+					Code:      mapping.code,
+					Message:   err.Error(),
+					RequestID: requestID,
+				}
+			}
+		}
+	}
+
+	return http.StatusInternalServerError, &errorResponse{
+		Code:      "internal_error",
+		Message:   "the request could not be completed",
+		RequestID: requestID,
+	}
+}
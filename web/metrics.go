@@ -0,0 +1,145 @@
+package web
+
+import (
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/metrics"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/usecases"
+	"github.com/julienschmidt/httprouter"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// unmatchedRoute labels the requests which match no route, so scanners can't
+// create a series per path they try.
+const unmatchedRoute = "unmatched"
+
+// hubMetrics are the metrics served at /metrics. Requests are labelled by
+// route pattern rather than path, keeping the number of series bounded.
+type hubMetrics struct {
+	registry  *metrics.Registry
+	requests  *metrics.Counter
+	durations *metrics.Histogram
+	// repositoryStats are read once per scrape, for every repository metric.
+	repositoryStats func() *usecases.RepositoryStats
+	scrapeMutex     sync.Mutex
+	scraped         *usecases.RepositoryStats
+}
+
+func newHubMetrics(repositoryStats func() *usecases.RepositoryStats, downloads func() []*usecases.Download) *hubMetrics {
+	registry := metrics.NewRegistry()
+	m := &hubMetrics{
+		registry:        registry,
+		repositoryStats: repositoryStats,
+		requests: registry.NewCounter("hub_http_requests_total",
+			"Requests served, by route, method and status code.", "route", "method", "code"),
+		durations: registry.NewHistogram("hub_http_request_duration_seconds",
+			"Time taken to serve requests, by route, method and status code.", metrics.DefaultBuckets, "route", "method", "code"),
+	}
+
+	registry.NewGaugeFunc("hub_resources_loaded", "Resources loaded, by kind.", []string{"kind"}, func(emit metrics.Emit) {
+		for kind, count := range m.scraped.Resources {
+			emit(float64(count), string(kind))
+		}
+	})
+	registry.NewGaugeFunc("hub_vendors_loaded", "Vendors loaded, by kind.", []string{"kind"}, func(emit metrics.Emit) {
+		for kind, count := range m.scraped.Vendors {
+			emit(float64(count), string(kind))
+		}
+	})
+	registry.NewCounterFunc("hub_repository_loads_total",
+		"Times the resources or vendors were read from their source.", []string{"repository"}, func(emit metrics.Emit) {
+			stats := m.scraped
+			if stats.ResourceLoads != nil {
+				emit(float64(stats.ResourceLoads.Loads), "resources")
+			}
+			if stats.VendorLoads != nil {
+				emit(float64(stats.VendorLoads.Loads), "vendors")
+			}
+		})
+	registry.NewCounterFunc("hub_repository_load_errors_total",
+		"Loads of the resources or vendors which failed.", []string{"repository"}, func(emit metrics.Emit) {
+			stats := m.scraped
+			if stats.ResourceLoads != nil {
+				emit(float64(stats.ResourceLoads.Failures), "resources")
+			}
+			if stats.VendorLoads != nil {
+				emit(float64(stats.VendorLoads.Failures), "vendors")
+			}
+		})
+	registry.NewGaugeFunc("hub_repository_load_duration_seconds",
+		"Time the last load of the resources or vendors took.", []string{"repository"}, func(emit metrics.Emit) {
+			stats := m.scraped
+			if stats.ResourceLoads != nil {
+				emit(stats.ResourceLoads.LastDuration.Seconds(), "resources")
+			}
+			if stats.VendorLoads != nil {
+				emit(stats.VendorLoads.LastDuration.Seconds(), "vendors")
+			}
+		})
+	// The downloads are the ones ranking resources by popularity, the exports
+	// delivered to clients. Helm installs download custom-rules.yaml.
+	registry.NewCounterFunc("hub_resource_downloads_total",
+		"Resources downloaded, by resource and export format.", []string{"resource", "format"}, func(emit metrics.Emit) {
+			for _, download := range downloads() {
+				emit(float64(download.Count), download.ResourceID, download.Format)
+			}
+		})
+	return m
+}
+
+// write writes every metric in the Prometheus text format.
+func (m *hubMetrics) write(w io.Writer) error {
+	m.scrapeMutex.Lock()
+	defer m.scrapeMutex.Unlock()
+	m.scraped = m.repositoryStats()
+	return m.registry.Write(w)
+}
+
+// statusRecorder remembers the status code of the response going through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(content []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(content)
+}
+
+// instrument counts and times the requests served by handle under route.
+func (m *hubMetrics) instrument(route string, handle httprouter.Handle) httprouter.Handle {
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		started := time.Now()
+		recorder := &statusRecorder{ResponseWriter: writer}
+		handle(recorder, request, params)
+		if recorder.status == 0 {
+			recorder.status = http.StatusOK
+		}
+
+		code := strconv.Itoa(recorder.status)
+		m.requests.Inc(route, request.Method, code)
+		m.durations.Observe(time.Since(started).Seconds(), route, request.Method, code)
+	}
+}
+
+// instrumentUnmatched instruments the handlers httprouter calls when no route
+// matches.
+func (m *hubMetrics) instrumentUnmatched(handler http.HandlerFunc) http.HandlerFunc {
+	handle := m.instrument(unmatchedRoute, func(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+		handler(writer, request)
+	})
+	return func(writer http.ResponseWriter, request *http.Request) {
+		handle(writer, request, nil)
+	}
+}
@@ -0,0 +1,56 @@
+package web
+
+import (
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestMetricsAreServedInThePrometheusTextFormat(t *testing.T) {
+	result := serve(NewRouter(), "GET", "/metrics", "")
+
+	assert.Equal(t, http.StatusOK, result.Code)
+	assert.Equal(t, metrics.ContentType, result.Header().Get("Content-Type"))
+	assert.Contains(t, result.Body.String(), "# TYPE hub_http_requests_total counter\n")
+}
+
+func TestMetricsCountRequestsByRoutePattern(t *testing.T) {
+	router := NewRouter()
+	serve(router, "GET", "/resources/apache", "")
+	serve(router, "GET", "/resources/mongodb", "")
+	serve(router, "GET", "/resources/unknown", "")
+	serve(router, "GET", "/nowhere", "")
+
+	body := serve(router, "GET", "/metrics", "").Body.String()
+
+	assert.Contains(t, body, `hub_http_requests_total{route="/resources/:resource",method="GET",code="200"} 2`)
+	assert.Contains(t, body, `hub_http_requests_total{route="/resources/:resource",method="GET",code="404"} 1`)
+	assert.Contains(t, body, `hub_http_requests_total{route="unmatched",method="GET",code="404"} 1`)
+	assert.Contains(t, body, `hub_http_request_duration_seconds_count{route="/resources/:resource",method="GET",code="200"} 2`)
+}
+
+func TestMetricsCountDeliveredDownloadsPerResourceAndFormat(t *testing.T) {
+	router := NewRouter()
+	etag := serve(router, "GET", "/resources/apache/custom-rules.yaml", "").Header().Get("ETag")
+	serve(router, "GET", "/resources/Apache/custom-rules.yaml", "")
+	serveWithHeaders(router, "/resources/apache/custom-rules.yaml", map[string]string{"If-None-Match": etag})
+	serve(router, "GET", "/resources/apache/rules.yaml", "")
+
+	body := serve(router, "GET", "/metrics", "").Body.String()
+
+	assert.Contains(t, body, `hub_resource_downloads_total{resource="apache",format="custom-rules.yaml"} 2`)
+	assert.Contains(t, body, `hub_resource_downloads_total{resource="apache",format="rules.yaml"} 1`)
+	assert.NotContains(t, body, `hub_resource_downloads_total{resource="mongodb"`)
+}
+
+func TestMetricsDescribeTheLoadedRepositories(t *testing.T) {
+	body := serve(NewRouter(), "GET", "/metrics", "").Body.String()
+
+	assert.Contains(t, body, `hub_resources_loaded{kind="FalcoRules"} 2`)
+	assert.Contains(t, body, `hub_resources_loaded{kind="OPAPolicies"} 0`)
+	assert.Contains(t, body, `hub_vendors_loaded{kind="Vendor"} 2`)
+	assert.Contains(t, body, `hub_repository_loads_total{repository="resources"} 1`)
+	assert.Contains(t, body, `hub_repository_load_errors_total{repository="vendors"} 0`)
+	assert.Contains(t, body, `hub_repository_load_duration_seconds{repository="resources"}`)
+}
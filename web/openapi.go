@@ -0,0 +1,433 @@
+package web
+
+import (
+	"fmt"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/diagnostic"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/metrics"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/usecases"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/vendor"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The OpenAPI document is built from the routes and the operations
+// documenting them, so clients can be generated from the running server.
+// Schemas are derived from the types the handlers encode.
+
+type openAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       openAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components openAPIComponents                       `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIComponents struct {
+	Schemas         map[string]*openAPISchema         `json:"schemas"`
+	Parameters      map[string]*openAPIParameter      `json:"parameters"`
+	Responses       map[string]*openAPIResponse       `json:"responses"`
+	SecuritySchemes map[string]*openAPISecurityScheme `json:"securitySchemes"`
+}
+
+type openAPISecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme"`
+	Description string `json:"description"`
+}
+
+type openAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Summary     string                      `json:"summary"`
+	Tags        []string                    `json:"tags"`
+	Parameters  []*openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*openAPIResponse `json:"responses"`
+	Security    []map[string][]string       `json:"security,omitempty"`
+}
+
+type openAPIParameter struct {
+	Ref         string         `json:"$ref,omitempty"`
+	Name        string         `json:"name,omitempty"`
+	In          string         `json:"in,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *openAPISchema `json:"schema,omitempty"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                         `json:"required"`
+	Content  map[string]*openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Ref         string                       `json:"$ref,omitempty"`
+	Description string                       `json:"description,omitempty"`
+	Headers     map[string]*openAPIHeader    `json:"headers,omitempty"`
+	Content     map[string]*openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIHeader struct {
+	Description string         `json:"description"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Ref        string                    `json:"$ref,omitempty"`
+	Type       string                    `json:"type,omitempty"`
+	Format     string                    `json:"format,omitempty"`
+	Enum       []string                  `json:"enum,omitempty"`
+	Items      *openAPISchema            `json:"items,omitempty"`
+	Properties map[string]*openAPISchema `json:"properties,omitempty"`
+	Required   []string                  `json:"required,omitempty"`
+}
+
+// apiOperation documents a route. The request and response are values of
+// the types encoded in their JSON bodies, or nil when the body is not JSON.
+type apiOperation struct {
+	id          string
+	summary     string
+	tag         string
+	query       []*openAPIParameter
+	request     interface{}
+	status      int
+	response    interface{}
+	contentType string
+	paginated   bool
+	errors      []int
+	// authenticated operations require the write token.
+	authenticated bool
+}
+
+var (
+	listQuery = []*openAPIParameter{
+		{Ref: "#/components/parameters/kind"},
+		{Ref: "#/components/parameters/vendor"},
+		{Ref: "#/components/parameters/keyword"},
+		{Ref: "#/components/parameters/maintainer"},
+		{Ref: "#/components/parameters/sort"},
+		{Ref: "#/components/parameters/offset"},
+		{Ref: "#/components/parameters/limit"},
+	}
+	vendorListQuery = []*openAPIParameter{
+		{Ref: "#/components/parameters/sort"},
+		{Ref: "#/components/parameters/offset"},
+		{Ref: "#/components/parameters/limit"},
+	}
+	versionQuery = &openAPIParameter{Name: "version", In: "query", Description: "Version of the resource, the latest one by default", Schema: &openAPISchema{Type: "string"}}
+)
+
+// apiOperations documents every route, keyed by method and path as they are
+// registered. Search is dispatched through /resources/:resource, and is
+// documented under its own path.
+func apiOperations() map[string]*apiOperation {
+	operations := map[string]*apiOperation{
+		"GET /resources": {id: "listResources", summary: "List the latest version of every resource", tag: "resources",
+			query: listQuery, response: []*resource.Resource{}, paginated: true, errors: []int{http.StatusBadRequest}},
+		"GET /resources/search": {id: "searchResources", summary: "Search resources by name, description and keywords", tag: "resources",
+			query:    []*openAPIParameter{{Name: "q", In: "query", Description: "Text to search for", Required: true, Schema: &openAPISchema{Type: "string"}}},
+			response: []*resource.Resource{}, errors: []int{http.StatusBadRequest}},
+		"GET /resources/:resource": {id: "getResource", summary: "Get the latest version of a resource", tag: "resources",
+			response: &resource.Resource{}, errors: []int{http.StatusNotFound}},
+		"GET /resources/:resource/dependencies": {id: "getResourceDependencies", summary: "List the macros and lists a resource uses, and where they are defined", tag: "resources",
+			response: &resource.Dependencies{}, errors: []int{http.StatusNotFound}},
+		"GET /resources/:resource/versions": {id: "listResourceVersions", summary: "List every version of a resource", tag: "resources",
+			response: []*resource.Resource{}, errors: []int{http.StatusNotFound}},
+		"GET /resources/:resource/versions/:version": {id: "getResourceVersion", summary: "Get a version of a resource", tag: "resources",
+			response: &resource.Resource{}, errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+		"GET /resources/:resource/diff": {id: "compareResourceVersions", summary: "Compare two versions of a resource", tag: "resources",
+			query: []*openAPIParameter{
+				{Name: "from", In: "query", Description: "Version to compare from", Required: true, Schema: &openAPISchema{Type: "string"}},
+				{Name: "to", In: "query", Description: "Version to compare to", Required: true, Schema: &openAPISchema{Type: "string"}},
+			},
+			response: &resource.Diff{}, errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+		"POST /resources": {id: "createResource", summary: "Create a resource, when writes are enabled", tag: "resources",
+			request: &resource.Resource{}, status: http.StatusCreated, response: &resource.Resource{}, authenticated: true,
+			errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusConflict}},
+		"PUT /resources/:resource": {id: "updateResource", summary: "Replace a version of a resource, when writes are enabled", tag: "resources",
+			request: &resource.Resource{}, response: &resource.Resource{}, authenticated: true,
+			errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict}},
+		"DELETE /resources/:resource": {id: "deleteResource", summary: "Delete a resource, when writes are enabled", tag: "resources",
+			status: http.StatusNoContent, authenticated: true,
+			errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound}},
+		"GET /keywords": {id: "listKeywords", summary: "List the keywords of the resources, most used first", tag: "keywords",
+			response: []*resource.Keyword{}},
+		"GET /keywords/:keyword/resources": {id: "listResourcesWithKeyword", summary: "List the resources tagged with a keyword or its synonyms", tag: "keywords",
+			query: listQuery, response: []*resource.Resource{}, paginated: true, errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+		"GET /maintainers": {id: "listMaintainers", summary: "List the maintainers of the resources", tag: "maintainers",
+			response: []*resource.MaintainerSummary{}},
+		"GET /maintainers/:maintainer/resources": {id: "listResourcesFromMaintainer", summary: "List the resources of a maintainer", tag: "maintainers",
+			query: listQuery, response: []*resource.Resource{}, paginated: true, errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+		"GET /vendors": {id: "listVendors", summary: "List the vendors", tag: "vendors",
+			query: vendorListQuery, response: []*vendor.Vendor{}, paginated: true, errors: []int{http.StatusBadRequest}},
+		"GET /vendors/:vendor": {id: "getVendor", summary: "Get a vendor", tag: "vendors",
+			response: &vendor.Vendor{}, errors: []int{http.StatusNotFound}},
+		"GET /vendors/:vendor/resources": {id: "listResourcesFromVendor", summary: "List the resources of a vendor", tag: "vendors",
+			query: listQuery, response: []*resource.Resource{}, paginated: true, errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+		"GET /admin/diagnostics": {id: "listDiagnostics", summary: "List the problems found in the resources and vendors served, when writes are enabled", tag: "admin",
+			response: []*diagnostic.Diagnostic{}, authenticated: true, errors: []int{http.StatusUnauthorized}},
+		"GET /health": {id: "checkHealth", summary: "Check the server is up", tag: "admin",
+			contentType: "text/plain"},
+		"GET /openapi.json": {id: "getOpenAPIDocument", summary: "Get this document", tag: "admin",
+			response: map[string]interface{}{}},
+		"GET /metrics": {id: "getMetrics", summary: "Get the metrics of the server in the Prometheus text format", tag: "admin",
+			contentType: metrics.ContentType},
+	}
+
+	for _, format := range resource.ExportFormats() {
+		name := exportOperationName(format)
+		contentType := resource.ExportContentType(format)
+		exportErrors := []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict}
+		resourcesQuery := &openAPIParameter{Name: "resources", In: "query", Description: "Comma separated IDs of the resources to bundle", Required: true, Schema: &openAPISchema{Type: "string"}}
+
+		operations["GET /resources/:resource/"+format] = &apiOperation{id: "exportResource" + name, summary: "Export a resource to " + format, tag: "exports",
+			query: []*openAPIParameter{versionQuery}, contentType: contentType, errors: exportErrors}
+		operations["GET /bundles/"+format] = &apiOperation{id: "exportBundle" + name, summary: "Export several resources to a single " + format, tag: "exports",
+			query: []*openAPIParameter{resourcesQuery}, contentType: contentType, errors: exportErrors}
+		operations["POST /bundles/"+format] = &apiOperation{id: "createBundle" + name, summary: "Export the resources listed in the body to a single " + format, tag: "exports",
+			request: &bundleRequest{}, contentType: contentType, errors: exportErrors}
+	}
+	return operations
+}
+
+// exportOperationName turns a format like custom-rules.yaml into
+// CustomRulesYaml, to name the operations exporting to it.
+func exportOperationName(format string) string {
+	var name strings.Builder
+	for _, word := range strings.FieldsFunc(format, func(r rune) bool { return r == '-' || r == '.' }) {
+		name.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return name.String()
+}
+
+var pathParameter = regexp.MustCompile(`:([a-z]+)`)
+
+var pathParameterDescriptions = map[string]string{
+	"resource":   "ID of the resource, or one of its former IDs",
+	"version":    "Semantic version of the resource",
+	"keyword":    "Keyword, or one of its synonyms",
+	"maintainer": "Email of the maintainer, or the slug of their name when they have no email",
+	"vendor":     "ID of the vendor, or one of its former IDs",
+}
+
+var errorResponses = map[int]string{
+	http.StatusBadRequest:   "BadRequest",
+	http.StatusUnauthorized: "Unauthorized",
+	http.StatusNotFound:     "NotFound",
+	http.StatusForbidden:    "ReadOnly",
+	http.StatusConflict:     "Conflict",
+}
+
+func newOpenAPIDocument(routes []route) *openAPIDocument {
+	document := &openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info:    openAPIInfo{Title: "Cloud Native Security Hub", Version: "1.0.0"},
+		Paths:   map[string]map[string]*openAPIOperation{},
+		Components: openAPIComponents{
+			Schemas:    map[string]*openAPISchema{},
+			Parameters: sharedParameters(),
+			Responses:  map[string]*openAPIResponse{},
+			SecuritySchemes: map[string]*openAPISecurityScheme{
+				"writeToken": {Type: "http", Scheme: "bearer", Description: "The WRITE_TOKEN of the server, required when writes are enabled"},
+			},
+		},
+	}
+	generator := &schemaGenerator{schemas: document.Components.Schemas}
+	errorSchema := generator.schemaOf(reflect.TypeOf(errorResponse{}))
+	for status, name := range errorResponses {
+		document.Components.Responses[name] = jsonResponse(http.StatusText(status), errorSchema)
+	}
+	document.Components.Responses["NotModified"] = &openAPIResponse{Description: "The response didn't change since the client got it"}
+	document.Components.Responses["InternalError"] = jsonResponse(http.StatusText(http.StatusInternalServerError), errorSchema)
+
+	operations := apiOperations()
+	documented := append(routes, route{method: http.MethodGet, path: "/resources/search"})
+	for _, route := range documented {
+		operation, ok := operations[route.method+" "+route.path]
+		if !ok {
+			continue
+		}
+		path := pathParameter.ReplaceAllString(route.path, "{$1}")
+		if document.Paths[path] == nil {
+			document.Paths[path] = map[string]*openAPIOperation{}
+		}
+		document.Paths[path][strings.ToLower(route.method)] = operation.document(route, generator)
+	}
+	return document
+}
+
+func (o *apiOperation) document(route route, generator *schemaGenerator) *openAPIOperation {
+	operation := &openAPIOperation{
+		OperationID: o.id,
+		Summary:     o.summary,
+		Tags:        []string{o.tag},
+		Responses:   map[string]*openAPIResponse{},
+	}
+	for _, match := range pathParameter.FindAllStringSubmatch(route.path, -1) {
+		operation.Parameters = append(operation.Parameters, &openAPIParameter{
+			Name: match[1], In: "path", Description: pathParameterDescriptions[match[1]], Required: true, Schema: &openAPISchema{Type: "string"},
+		})
+	}
+	operation.Parameters = append(operation.Parameters, o.query...)
+	if validated(route) {
+		operation.Parameters = append(operation.Parameters,
+			&openAPIParameter{Ref: "#/components/parameters/ifNoneMatch"},
+			&openAPIParameter{Ref: "#/components/parameters/ifModifiedSince"})
+	}
+	if o.request != nil {
+		operation.RequestBody = &openAPIRequestBody{Required: true, Content: map[string]*openAPIMediaType{
+			"application/json": {Schema: generator.schemaOf(reflect.TypeOf(o.request))},
+		}}
+	}
+
+	status := o.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	response := &openAPIResponse{Description: http.StatusText(status)}
+	switch {
+	case o.response != nil:
+		response.Content = map[string]*openAPIMediaType{"application/json": {Schema: generator.schemaOf(reflect.TypeOf(o.response))}}
+	case o.contentType != "":
+		response.Content = map[string]*openAPIMediaType{o.contentType: {Schema: &openAPISchema{Type: "string"}}}
+	}
+	response.Headers = map[string]*openAPIHeader{}
+	if o.paginated {
+		response.Headers[totalCountHeader] = &openAPIHeader{Description: "Number of items matching the filters, in every page", Schema: &openAPISchema{Type: "integer"}}
+		response.Headers["Link"] = &openAPIHeader{Description: "Links to the first, previous, next and last pages, when a limit is set", Schema: &openAPISchema{Type: "string"}}
+	}
+	if validated(route) {
+		response.Headers["ETag"] = &openAPIHeader{Description: "Hash of the content, to send back in If-None-Match", Schema: &openAPISchema{Type: "string"}}
+		response.Headers["Last-Modified"] = &openAPIHeader{Description: "When the hub last changed, to send back in If-Modified-Since", Schema: &openAPISchema{Type: "string"}}
+		response.Headers["Cache-Control"] = &openAPIHeader{Description: "How long caches may reuse the response", Schema: &openAPISchema{Type: "string"}}
+		operation.Responses["304"] = &openAPIResponse{Ref: "#/components/responses/NotModified"}
+	}
+	operation.Responses[strconv.Itoa(status)] = response
+
+	for _, status := range o.errors {
+		operation.Responses[strconv.Itoa(status)] = &openAPIResponse{Ref: "#/components/responses/" + errorResponses[status]}
+	}
+	operation.Responses["500"] = &openAPIResponse{Ref: "#/components/responses/InternalError"}
+	if o.authenticated {
+		operation.Security = []map[string][]string{{"writeToken": {}}}
+	}
+	return operation
+}
+
+func jsonResponse(description string, schema *openAPISchema) *openAPIResponse {
+	return &openAPIResponse{Description: description, Content: map[string]*openAPIMediaType{"application/json": {Schema: schema}}}
+}
+
+func kindNames() []string {
+	var kinds []string
+	for _, kind := range resource.Kinds() {
+		kinds = append(kinds, string(kind))
+	}
+	return kinds
+}
+
+func sharedParameters() map[string]*openAPIParameter {
+	query := func(name, description string, schema *openAPISchema) *openAPIParameter {
+		return &openAPIParameter{Name: name, In: "query", Description: description, Schema: schema}
+	}
+	return map[string]*openAPIParameter{
+		"kind":       query("kind", "Only list the resources of this kind", &openAPISchema{Type: "string", Enum: kindNames()}),
+		"vendor":     query("vendor", "Only list the resources of this vendor", &openAPISchema{Type: "string"}),
+		"keyword":    query("keyword", "Only list the resources tagged with this keyword", &openAPISchema{Type: "string"}),
+		"maintainer": query("maintainer", "Only list the resources maintained by this name or email", &openAPISchema{Type: "string"}),
+		"sort":       query("sort", "Field to sort by, prefixed with - to sort in descending order. Only resources read from git can be sorted by updated. Popularity counts the downloads served by the replica answering since it started", &openAPISchema{Type: "string", Enum: []string{"name", "-name", "updated", "-updated", "popularity", "-popularity"}}),
+		"offset":     query("offset", "Number of items to skip", &openAPISchema{Type: "integer"}),
+		"limit":      query("limit", fmt.Sprintf("Maximum number of items to list, up to %d. Every item is listed when it is omitted or 0", usecases.MaxPageSize), &openAPISchema{Type: "integer"}),
+		"ifNoneMatch": {Name: "If-None-Match", In: "header", Description: "ETag of the response the client has, answered with 304 when it didn't change",
+			Schema: &openAPISchema{Type: "string"}},
+		"ifModifiedSince": {Name: "If-Modified-Since", In: "header", Description: "Last-Modified of the response the client has, answered with 304 when the hub didn't change since",
+			Schema: &openAPISchema{Type: "string"}},
+	}
+}
+
+// marshalledAs maps the types encoded with a custom marshaller to a type with
+// the fields they are encoded with.
+var marshalledAs = map[reflect.Type]reflect.Type{
+	reflect.TypeOf(resource.FalcoRuleData{}): reflect.TypeOf(struct {
+		Raw    string                `json:"raw"`
+		Parsed []*resource.FalcoItem `json:"parsed"`
+	}{}),
+}
+
+// schemaGenerator derives schemas from Go types, adding a component for each
+// struct.
+type schemaGenerator struct {
+	schemas map[string]*openAPISchema
+}
+
+func (g *schemaGenerator) schemaOf(t reflect.Type) *openAPISchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return &openAPISchema{Type: "string", Format: "date-time"}
+	}
+	if t == reflect.TypeOf(resource.Kind("")) {
+		return &openAPISchema{Type: "string", Enum: kindNames()}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &openAPISchema{Type: "string"}
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		return &openAPISchema{Type: "integer"}
+	case reflect.Slice, reflect.Array:
+		return &openAPISchema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map, reflect.Interface:
+		return &openAPISchema{Type: "object"}
+	case reflect.Struct:
+		return g.componentOf(t)
+	}
+	panic(fmt.Sprintf("no schema for %s", t))
+}
+
+func (g *schemaGenerator) componentOf(t reflect.Type) *openAPISchema {
+	name := strings.ToUpper(t.Name()[:1]) + t.Name()[1:]
+	ref := &openAPISchema{Ref: "#/components/schemas/" + name}
+	if _, ok := g.schemas[name]; ok {
+		return ref
+	}
+
+	schema := &openAPISchema{Type: "object", Properties: map[string]*openAPISchema{}}
+	g.schemas[name] = schema
+	if encoded, ok := marshalledAs[t]; ok {
+		t = encoded
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")
+		if tag[0] == "-" || field.PkgPath != "" {
+			continue
+		}
+		property := tag[0]
+		if property == "" {
+			property = field.Name
+		}
+		schema.Properties[property] = g.schemaOf(field.Type)
+		if len(tag) == 1 || tag[1] != "omitempty" {
+			schema.Required = append(schema.Required, property)
+		}
+	}
+	sort.Strings(schema.Required)
+	return ref
+}
@@ -0,0 +1,79 @@
+package web
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestEveryRouteIsDocumented(t *testing.T) {
+	operations := apiOperations()
+
+	for _, route := range routes(NewHandlerRepository(nil)) {
+		_, ok := operations[route.method+" "+route.path]
+		assert.True(t, ok, "%s %s is not documented in apiOperations", route.method, route.path)
+	}
+}
+
+func TestOpenAPIDocumentIsServed(t *testing.T) {
+	result := serve(NewRouter(), "GET", "/openapi.json", "")
+	var document openAPIDocument
+
+	assert.Equal(t, http.StatusOK, result.Code)
+	assert.Equal(t, "application/json", result.Header().Get("Content-Type"))
+	assert.NoError(t, json.NewDecoder(result.Body).Decode(&document))
+	assert.Equal(t, "3.0.3", document.OpenAPI)
+	assert.Equal(t, "getResource", document.Paths["/resources/{resource}"]["get"].OperationID)
+	assert.Equal(t, "searchResources", document.Paths["/resources/search"]["get"].OperationID)
+	assert.Equal(t, "#/components/responses/NotFound", document.Paths["/vendors/{vendor}"]["get"].Responses["404"].Ref)
+	assert.Equal(t, "#/components/schemas/Vendor", document.Paths["/vendors/{vendor}"]["get"].Responses["200"].Content["application/json"].Schema.Ref)
+	assert.Contains(t, document.Paths["/vendors/{vendor}"]["get"].Responses, "304")
+	assert.NotContains(t, document.Paths["/metrics"]["get"].Responses, "304")
+}
+
+func TestOpenAPIDocumentOperationsHaveUniqueIDs(t *testing.T) {
+	document := newOpenAPIDocument(routes(NewHandlerRepository(nil)))
+	seen := map[string]bool{}
+
+	for path, operations := range document.Paths {
+		for method, operation := range operations {
+			assert.False(t, seen[operation.OperationID], "%s %s reuses the operation ID %s", method, path, operation.OperationID)
+			seen[operation.OperationID] = true
+		}
+	}
+}
+
+func TestOpenAPISchemasDescribeEveryEncodedField(t *testing.T) {
+	document := newOpenAPIDocument(routes(NewHandlerRepository(nil)))
+
+	for path, schema := range map[string]string{"/resources/apache": "Resource", "/vendors/apache": "Vendor"} {
+		var encoded map[string]interface{}
+		assert.NoError(t, json.NewDecoder(serve(NewRouter(), "GET", path, "").Body).Decode(&encoded))
+		for field := range encoded {
+			assert.Contains(t, document.Components.Schemas[schema].Properties, field, "%s.%s is not in the schema", schema, field)
+		}
+	}
+	assert.Contains(t, document.Components.Schemas["FalcoRuleData"].Properties, "parsed")
+}
+
+func TestOpenAPIDocumentDescribesExports(t *testing.T) {
+	document := newOpenAPIDocument(routes(NewHandlerRepository(nil)))
+
+	operation := document.Paths["/bundles/custom-rules.yaml"]["post"]
+
+	assert.Contains(t, operation.Responses["200"].Content, "application/x-yaml")
+	assert.Equal(t, "#/components/schemas/BundleRequest", operation.RequestBody.Content["application/json"].Schema.Ref)
+}
+
+func TestOpenAPIDocumentRequiresBothVersionsOfADiff(t *testing.T) {
+	document := newOpenAPIDocument(routes(NewHandlerRepository(nil)))
+
+	required := []string{}
+	for _, parameter := range document.Paths["/resources/{resource}/diff"]["get"].Parameters {
+		if parameter.In == "query" && parameter.Required {
+			required = append(required, parameter.Name)
+		}
+	}
+	assert.Equal(t, []string{"from", "to"}, required)
+}
@@ -0,0 +1,85 @@
+package web
+
+import (
+	"fmt"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/usecases"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const totalCountHeader = "X-Total-Count"
+
+func resourceListOptions(request *http.Request) (usecases.ResourceListOptions, error) {
+	query := request.URL.Query()
+	pagination, err := paginationFrom(query)
+	return usecases.ResourceListOptions{
+		Filter: usecases.ResourceFilter{
+			Kind:       query.Get("kind"),
+			Vendor:     query.Get("vendor"),
+			Keyword:    query.Get("keyword"),
+			Maintainer: query.Get("maintainer"),
+		},
+		Sort:       query.Get("sort"),
+		Pagination: pagination,
+	}, err
+}
+
+func vendorListOptions(request *http.Request) (usecases.VendorListOptions, error) {
+	query := request.URL.Query()
+	pagination, err := paginationFrom(query)
+	return usecases.VendorListOptions{
+		Sort:       query.Get("sort"),
+		Pagination: pagination,
+	}, err
+}
+
+func paginationFrom(query url.Values) (pagination usecases.Pagination, err error) {
+	for name, value := range map[string]*int{"offset": &pagination.Offset, "limit": &pagination.Limit} {
+		raw := query.Get(name)
+		if raw == "" {
+			continue
+		}
+		if *value, err = strconv.Atoi(raw); err != nil {
+			return pagination, fmt.Errorf("%w: the %s must be a number", usecases.ErrInvalidListOptions, name)
+		}
+	}
+	return pagination, nil
+}
+
+// writePageHeaders sets the total number of items in X-Total-Count, and links
+// to the first, previous, next and last pages when the list is paginated.
+func writePageHeaders(writer http.ResponseWriter, request *http.Request, total int, pagination usecases.Pagination) {
+	writer.Header().Set(totalCountHeader, strconv.Itoa(total))
+	limit := pagination.Limit
+	if limit == 0 {
+		return
+	}
+
+	last := 0
+	if total > 0 {
+		last = (total - 1) / limit * limit
+	}
+	links := []string{pageLink(request, 0, limit, "first")}
+	if pagination.Offset > 0 {
+		previous := pagination.Offset - limit
+		if previous < 0 {
+			previous = 0
+		}
+		links = append(links, pageLink(request, previous, limit, "prev"))
+	}
+	if pagination.Offset+limit < total {
+		links = append(links, pageLink(request, pagination.Offset+limit, limit, "next"))
+	}
+	links = append(links, pageLink(request, last, limit, "last"))
+	writer.Header().Set("Link", strings.Join(links, ", "))
+}
+
+func pageLink(request *http.Request, offset, limit int, rel string) string {
+	query := request.URL.Query()
+	query.Set("offset", strconv.Itoa(offset))
+	query.Set("limit", strconv.Itoa(limit))
+	target := url.URL{Path: request.URL.Path, RawQuery: query.Encode()}
+	return fmt.Sprintf("<%s>; rel=%q", target.String(), rel)
+}
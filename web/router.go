@@ -1,6 +1,7 @@
 package web
 
 import (
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
 	"github.com/julienschmidt/httprouter"
 	"github.com/rs/cors"
 	"log"
@@ -11,24 +12,113 @@ func NewRouter() http.Handler {
 	router := httprouter.New()
 	registerOn(router, nil)
 
-	return cors.Default().Handler(router)
+	return newCORS().Handler(withRequestID(router))
 }
 
 func NewRouterWithLogger(logger *log.Logger) http.Handler {
 	router := httprouter.New()
 	registerOn(router, logger)
 
-	return cors.Default().Handler(router)
+	return newCORS().Handler(withRequestID(router))
+}
+
+// newCORS lets any origin read the hub. POST is allowed for the bundles, the
+// write endpoints need an Authorization header which no origin may send.
+func newCORS() *cors.Cors {
+	return cors.New(cors.Options{
+		AllowedMethods: []string{http.MethodGet, http.MethodHead, http.MethodPost},
+		ExposedHeaders: []string{requestIDHeader, totalCountHeader, "Link", "ETag", "Last-Modified"},
+	})
+}
+
+// route is an endpoint of the API, documented in the OpenAPI document.
+type route struct {
+	method string
+	path   string
+	handle httprouter.Handle
+}
+
+func routes(h HandlerRepository) []route {
+	return append(append(readRoutes(h), writeRoutes(h)...), adminRoutes(h)...)
+}
+
+func readRoutes(h HandlerRepository) []route {
+	routes := []route{
+		{http.MethodGet, "/resources", h.retrieveAllResourcesHandler},
+		{http.MethodGet, "/resources/:resource", withStaticSegment("resource", "search", h.searchResourcesHandler, h.retrieveOneResourcesHandler)},
+		{http.MethodGet, "/resources/:resource/dependencies", h.retrieveResourceDependenciesHandler},
+		{http.MethodGet, "/resources/:resource/versions", h.retrieveResourceVersionsHandler},
+		{http.MethodGet, "/resources/:resource/versions/:version", h.retrieveResourceVersionHandler},
+		{http.MethodGet, "/resources/:resource/diff", h.compareResourceVersionsHandler},
+	}
+	for _, format := range resource.ExportFormats() {
+		routes = append(routes,
+			route{http.MethodGet, "/resources/:resource/" + format, h.exportResourceHandler(format)},
+			route{http.MethodGet, "/bundles/" + format, h.exportBundleHandler(format)},
+			route{http.MethodPost, "/bundles/" + format, h.createBundleHandler(format)},
+		)
+	}
+	return append(routes, []route{
+		{http.MethodGet, "/keywords", h.retrieveKeywordsHandler},
+		{http.MethodGet, "/keywords/:keyword/resources", h.retrieveResourcesWithKeywordHandler},
+		{http.MethodGet, "/maintainers", h.retrieveAllMaintainersHandler},
+		{http.MethodGet, "/maintainers/:maintainer/resources", h.retrieveAllResourcesFromMaintainerHandler},
+		{http.MethodGet, "/vendors", h.retrieveAllVendorsHandler},
+		{http.MethodGet, "/vendors/:vendor", h.retrieveOneVendorsHandler},
+		{http.MethodGet, "/vendors/:vendor/resources", h.retrieveAllResourcesFromVendorHandler},
+		{http.MethodGet, "/health", h.healthCheckHandler},
+		{http.MethodGet, "/openapi.json", h.openAPIHandler},
+		{http.MethodGet, "/metrics", h.metricsHandler},
+	}...)
+}
+
+// writeRoutes modify the resources served. They are only registered when
+// writes are enabled, and require the write token.
+func writeRoutes(h HandlerRepository) []route {
+	return []route{
+		{http.MethodPost, "/resources", h.createResourceHandler},
+		{http.MethodPut, "/resources/:resource", h.updateResourceHandler},
+		{http.MethodDelete, "/resources/:resource", h.deleteResourceHandler},
+	}
+}
+
+// adminRoutes expose the internals of the server, like the paths of the files
+// served. They are registered along with the write routes, and require the
+// write token too.
+func adminRoutes(h HandlerRepository) []route {
+	return []route{
+		{http.MethodGet, "/admin/diagnostics", h.retrieveDiagnosticsHandler},
+	}
 }
 
 func registerOn(router *httprouter.Router, logger *log.Logger) {
 	h := NewHandlerRepository(logger)
-	router.GET("/resources", h.retrieveAllResourcesHandler)
-	router.GET("/resources/:resource", h.retrieveOneResourcesHandler)
-	router.GET("/resources/:resource/custom-rules.yaml", h.retrieveFalcoRulesForHelmChartHandler)
-	router.GET("/vendors", h.retrieveAllVendorsHandler)
-	router.GET("/vendors/:vendor", h.retrieveOneVendorsHandler)
-	router.GET("/vendors/:vendor/resources", h.retrieveAllResourcesFromVendorHandler)
-	router.GET("/health", h.healthCheckHandler)
-	router.NotFound = h.notFound()
+	cacheControl := cacheControl()
+	for _, route := range readRoutes(h) {
+		handle := route.handle
+		if validated(route) {
+			handle = withValidators(handle, h.lastModified, cacheControl)
+		}
+		router.Handle(route.method, route.path, h.instrument(route.path, handle))
+	}
+	if token := writeToken(); token != "" {
+		for _, route := range append(writeRoutes(h), adminRoutes(h)...) {
+			router.Handle(route.method, route.path, h.instrument(route.path, h.requireToken(token, route.handle)))
+		}
+	}
+	router.NotFound = h.instrumentUnmatched(h.notFound())
+	router.MethodNotAllowed = h.instrumentUnmatched(h.methodNotAllowed())
+}
+
+// httprouter does not allow a static segment to share its position with a
+// named parameter, so routes like /resources/search are registered through
+// the parameter route and dispatched here.
+func withStaticSegment(param, segment string, static, handler httprouter.Handle) httprouter.Handle {
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		if params.ByName(param) == segment {
+			static(writer, request, params)
+			return
+		}
+		handler(writer, request, params)
+	}
 }
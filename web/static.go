@@ -0,0 +1,150 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/falcosecurity/cloud-native-security-hub/pkg/resource"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// StaticIndexFile is the file ExportStatic lists the exported endpoints in.
+const StaticIndexFile = "index.json"
+
+// StaticFile is an endpoint rendered to a file, relative to the directory of
+// the export.
+type StaticFile struct {
+	Path        string `json:"path"`
+	File        string `json:"file"`
+	ContentType string `json:"contentType"`
+}
+
+// StaticIndex lists the endpoints of a static export, so file servers can be
+// configured to serve each file under the path of its endpoint.
+type StaticIndex struct {
+	Files []*StaticFile `json:"files"`
+}
+
+// ExportStatic renders the read-only endpoints of the hub into dir, so it can
+// be hosted without running the server. Endpoints are requested from handler,
+// so the files hold exactly what the server would answer: JSON endpoints are
+// written to an index.json in the directory of their path, and exports to
+// their path. Exports the kind of a resource doesn't support are left out.
+func ExportStatic(handler http.Handler, dir string) (*StaticIndex, error) {
+	exporter := &staticExporter{handler: handler, dir: dir, index: &StaticIndex{Files: []*StaticFile{}}}
+
+	resources, err := exporter.exportList("resources")
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range resources {
+		if _, err := exporter.export([]string{"resources", id}, true); err != nil {
+			return nil, err
+		}
+		if _, err := exporter.export([]string{"resources", id, "custom-rules.yaml"}, false); err != nil {
+			return nil, err
+		}
+	}
+
+	vendors, err := exporter.exportList("vendors")
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range vendors {
+		if _, err := exporter.export([]string{"vendors", id}, true); err != nil {
+			return nil, err
+		}
+		if _, err := exporter.export([]string{"vendors", id, "resources"}, false); err != nil {
+			return nil, err
+		}
+	}
+
+	content, err := json.MarshalIndent(exporter.index, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, StaticIndexFile), append(content, '\n'), 0644); err != nil {
+		return nil, err
+	}
+	return exporter.index, nil
+}
+
+type staticExporter struct {
+	handler http.Handler
+	dir     string
+	index   *StaticIndex
+}
+
+// exportList renders a list of resources or vendors, returning their IDs.
+func (e *staticExporter) exportList(segment string) ([]string, error) {
+	content, err := e.export([]string{segment}, true)
+	if err != nil {
+		return nil, err
+	}
+	var items []struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(content, &items); err != nil {
+		return nil, fmt.Errorf("/%s: %s", segment, err)
+	}
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	return ids, nil
+}
+
+// export renders the endpoint at the path made of segments into its file and
+// returns its content. Exports are written to their path and the other
+// endpoints, which answer JSON, to an index.json in the directory of their
+// path. Missing endpoints fail the export when they are required, and are
+// skipped otherwise.
+func (e *staticExporter) export(segments []string, required bool) ([]byte, error) {
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		escaped[i] = url.PathEscape(segment)
+	}
+	endpoint := "/" + strings.Join(escaped, "/")
+
+	request := httptest.NewRequest(http.MethodGet, endpoint, nil)
+	recorder := httptest.NewRecorder()
+	e.handler.ServeHTTP(recorder, request)
+
+	if recorder.Code == http.StatusNotFound && !required {
+		return nil, nil
+	}
+	if recorder.Code != http.StatusOK {
+		return nil, fmt.Errorf("%s answered %d: %s", endpoint, recorder.Code, strings.TrimSpace(recorder.Body.String()))
+	}
+
+	file := path.Join(segments...)
+	if !isExport(segments[len(segments)-1]) {
+		file = path.Join(file, "index.json")
+	}
+
+	target := filepath.Join(e.dir, filepath.FromSlash(file))
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(target, recorder.Body.Bytes(), 0644); err != nil {
+		return nil, err
+	}
+
+	e.index.Files = append(e.index.Files, &StaticFile{Path: endpoint, File: file, ContentType: recorder.Header().Get("Content-Type")})
+	return recorder.Body.Bytes(), nil
+}
+
+func isExport(segment string) bool {
+	for _, format := range resource.ExportFormats() {
+		if segment == format {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package web
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExportStaticRendersEndpointsIntoFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "static")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	router := NewRouter()
+
+	index, err := ExportStatic(router, dir)
+
+	assert.NoError(t, err)
+	var files []string
+	for _, file := range index.Files {
+		files = append(files, file.File)
+		content, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(file.File)))
+		assert.NoError(t, err)
+		assert.Equal(t, serve(router, "GET", file.Path, "").Body.String(), string(content), file.Path)
+	}
+	assert.Equal(t, []string{
+		"resources/index.json",
+		"resources/apache/index.json",
+		"resources/apache/custom-rules.yaml",
+		"resources/mongodb/index.json",
+		"resources/mongodb/custom-rules.yaml",
+		"vendors/index.json",
+		"vendors/apache/index.json",
+		"vendors/apache/resources/index.json",
+		"vendors/mongo/index.json",
+		"vendors/mongo/resources/index.json",
+	}, files)
+}
+
+func TestExportStaticWritesTheIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "static")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	index, _ := ExportStatic(NewRouter(), dir)
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, StaticIndexFile))
+	assert.NoError(t, err)
+	var written StaticIndex
+	assert.NoError(t, json.Unmarshal(content, &written))
+	assert.Equal(t, index, &written)
+	assert.Equal(t, &StaticFile{Path: "/resources/apache/custom-rules.yaml", File: "resources/apache/custom-rules.yaml", ContentType: "application/x-yaml"}, written.Files[2])
+}